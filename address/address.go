@@ -0,0 +1,259 @@
+// Package address parses, validates and constructs Cardano addresses as
+// specified by CIP-19.
+package address
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/minswap/pab-go/bech32"
+)
+
+type Network byte
+
+const (
+	Testnet Network = 0
+	Mainnet Network = 1
+)
+
+func (n Network) String() string {
+	if n == Mainnet {
+		return "mainnet"
+	}
+	return "testnet"
+}
+
+type Kind byte
+
+const (
+	KindBase Kind = iota
+	KindPointer
+	KindEnterprise
+	KindReward
+	KindByron
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindBase:
+		return "base"
+	case KindPointer:
+		return "pointer"
+	case KindEnterprise:
+		return "enterprise"
+	case KindReward:
+		return "reward"
+	case KindByron:
+		return "byron"
+	default:
+		return fmt.Sprintf("Kind(%d)", byte(k))
+	}
+}
+
+const HashLength = 28
+
+var ErrWrongNetwork = errors.New("address: wrong network")
+
+// Address is a decoded Cardano address. Payment is nil for reward addresses,
+// Stake is only set for base and reward addresses and Pointer only for pointer
+// addresses.
+type Address struct {
+	Kind    Kind
+	Network Network
+	Payment *Credential
+	Stake   *Credential
+	Pointer *Pointer
+	// ProtocolMagic is the network magic embedded in a Byron testnet address.
+	ProtocolMagic *uint32
+
+	byron []byte
+}
+
+func NewBaseAddress(network Network, payment, stake Credential) (Address, error) {
+	if err := payment.Validate(); err != nil {
+		return Address{}, fmt.Errorf("invalid payment credential: %w", err)
+	}
+	if err := stake.Validate(); err != nil {
+		return Address{}, fmt.Errorf("invalid stake credential: %w", err)
+	}
+	return Address{Kind: KindBase, Network: network, Payment: &payment, Stake: &stake}, nil
+}
+
+func NewPointerAddress(network Network, payment Credential, pointer Pointer) (Address, error) {
+	if err := payment.Validate(); err != nil {
+		return Address{}, fmt.Errorf("invalid payment credential: %w", err)
+	}
+	return Address{Kind: KindPointer, Network: network, Payment: &payment, Pointer: &pointer}, nil
+}
+
+func NewEnterpriseAddress(network Network, payment Credential) (Address, error) {
+	if err := payment.Validate(); err != nil {
+		return Address{}, fmt.Errorf("invalid payment credential: %w", err)
+	}
+	return Address{Kind: KindEnterprise, Network: network, Payment: &payment}, nil
+}
+
+func NewRewardAddress(network Network, stake Credential) (Address, error) {
+	if err := stake.Validate(); err != nil {
+		return Address{}, fmt.Errorf("invalid stake credential: %w", err)
+	}
+	return Address{Kind: KindReward, Network: network, Stake: &stake}, nil
+}
+
+// Parse decodes a bech32 Shelley address or a base58 Byron address.
+func Parse(s string) (Address, error) {
+	hrp, data, err := bech32.Decode(s)
+	if err != nil {
+		raw, b58Err := base58Decode(s)
+		if b58Err != nil {
+			return Address{}, fmt.Errorf("address: %s is neither bech32 nor base58: %w", s, err)
+		}
+		return parseByron(raw)
+	}
+	addr, err := FromBytes(data)
+	if err != nil {
+		return Address{}, err
+	}
+	if addr.Kind == KindByron {
+		return Address{}, errors.New("address: byron address must be base58-encoded")
+	}
+	if expected := addr.HRP(); hrp != expected {
+		return Address{}, fmt.Errorf("address: prefix %s does not match header, expect %s", hrp, expected)
+	}
+	return addr, nil
+}
+
+// FromBytes decodes the raw bytes of an address.
+func FromBytes(b []byte) (Address, error) {
+	if len(b) == 0 {
+		return Address{}, errors.New("address: empty address")
+	}
+	header := b[0]
+	typ, network := header>>4, Network(header&0x0f)
+	if typ != 8 && network != Testnet && network != Mainnet {
+		return Address{}, fmt.Errorf("address: unknown network id %d", network)
+	}
+	payload := b[1:]
+	switch {
+	case typ <= 3:
+		if len(payload) != 2*HashLength {
+			return Address{}, fmt.Errorf("address: base address must have %d bytes, got %d", 1+2*HashLength, len(b))
+		}
+		payment := newCredential(typ&1 == 1, payload[:HashLength])
+		stake := newCredential(typ&2 == 2, payload[HashLength:])
+		return Address{Kind: KindBase, Network: network, Payment: &payment, Stake: &stake}, nil
+	case typ == 4 || typ == 5:
+		if len(payload) < HashLength+3 {
+			return Address{}, fmt.Errorf("address: pointer address too short")
+		}
+		payment := newCredential(typ&1 == 1, payload[:HashLength])
+		pointer, err := decodePointer(payload[HashLength:])
+		if err != nil {
+			return Address{}, err
+		}
+		return Address{Kind: KindPointer, Network: network, Payment: &payment, Pointer: &pointer}, nil
+	case typ == 6 || typ == 7:
+		if len(payload) != HashLength {
+			return Address{}, fmt.Errorf("address: enterprise address must have %d bytes, got %d", 1+HashLength, len(b))
+		}
+		payment := newCredential(typ&1 == 1, payload)
+		return Address{Kind: KindEnterprise, Network: network, Payment: &payment}, nil
+	case typ == 8:
+		return parseByron(b)
+	case typ == 14 || typ == 15:
+		if len(payload) != HashLength {
+			return Address{}, fmt.Errorf("address: reward address must have %d bytes, got %d", 1+HashLength, len(b))
+		}
+		stake := newCredential(typ&1 == 1, payload)
+		return Address{Kind: KindReward, Network: network, Stake: &stake}, nil
+	default:
+		return Address{}, fmt.Errorf("address: unknown address type %d", typ)
+	}
+}
+
+func (a Address) header() byte {
+	var typ byte
+	switch a.Kind {
+	case KindBase:
+		typ = byte(a.Payment.Type) | byte(a.Stake.Type)<<1
+	case KindPointer:
+		typ = 4 | byte(a.Payment.Type)
+	case KindEnterprise:
+		typ = 6 | byte(a.Payment.Type)
+	case KindReward:
+		typ = 14 | byte(a.Stake.Type)
+	}
+	return typ<<4 | byte(a.Network)
+}
+
+// Bytes returns the raw bytes of the address.
+func (a Address) Bytes() []byte {
+	if a.Kind == KindByron {
+		return append([]byte{}, a.byron...)
+	}
+	b := []byte{a.header()}
+	switch a.Kind {
+	case KindBase:
+		b = append(b, a.Payment.hashBytes()...)
+		b = append(b, a.Stake.hashBytes()...)
+	case KindPointer:
+		b = append(b, a.Payment.hashBytes()...)
+		b = append(b, a.Pointer.bytes()...)
+	case KindEnterprise:
+		b = append(b, a.Payment.hashBytes()...)
+	case KindReward:
+		b = append(b, a.Stake.hashBytes()...)
+	}
+	return b
+}
+
+// HRP returns the bech32 prefix of the address, empty for Byron addresses.
+func (a Address) HRP() string {
+	switch {
+	case a.Kind == KindByron:
+		return ""
+	case a.Kind == KindReward && a.Network == Mainnet:
+		return "stake"
+	case a.Kind == KindReward:
+		return "stake_test"
+	case a.Network == Mainnet:
+		return "addr"
+	default:
+		return "addr_test"
+	}
+}
+
+func (a Address) String() string {
+	if a.Kind == KindByron {
+		return base58Encode(a.byron)
+	}
+	s, err := bech32.Encode(a.HRP(), a.Bytes())
+	if err != nil {
+		// only fails on an empty prefix, which HRP never returns for Shelley addresses
+		panic(err)
+	}
+	return s
+}
+
+// Hex returns the raw bytes of the address hex-encoded.
+func (a Address) Hex() string {
+	return hex.EncodeToString(a.Bytes())
+}
+
+// RewardAddress returns the reward address sharing the stake credential of a
+// base address.
+func (a Address) RewardAddress() (Address, error) {
+	if a.Stake == nil {
+		return Address{}, fmt.Errorf("address: %s address has no stake credential", a.Kind)
+	}
+	return NewRewardAddress(a.Network, *a.Stake)
+}
+
+// ValidateNetwork returns ErrWrongNetwork if the address does not belong to network.
+func (a Address) ValidateNetwork(network Network) error {
+	if a.Network != network {
+		return fmt.Errorf("%w: %s is a %s address, expect %s", ErrWrongNetwork, a, a.Network, network)
+	}
+	return nil
+}
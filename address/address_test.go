@@ -0,0 +1,85 @@
+package address
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test vectors from CIP-19
+const (
+	testPaymentKeyHash = "9493315cd92eb5d8c4304e67b7e16ae36d61d34502694657811a2c8e"
+	testStakeKeyHash   = "337b62cfff6403a06a3acbc34f8c46003c69fe79a3628cefa9c47251"
+	testScriptHash     = "c37b1b5dc0669f1d3c61a6fddb2e8fde96be87b881c60bce8e8d542f"
+)
+
+func TestParseAndBuild(t *testing.T) {
+	assert := assert.New(t)
+	paymentKey := NewKeyCredential(testPaymentKeyHash)
+	stakeKey := NewKeyCredential(testStakeKeyHash)
+	script := NewScriptCredential(testScriptHash)
+	pointer := Pointer{Slot: 2498243, TxIndex: 27, CertIndex: 3}
+
+	mustBuild := func(addr Address, err error) Address {
+		assert.NoError(err)
+		return addr
+	}
+	testcases := []struct {
+		addr     Address
+		expected string
+	}{
+		{mustBuild(NewBaseAddress(Mainnet, paymentKey, stakeKey)), "addr1qx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzer3n0d3vllmyqwsx5wktcd8cc3sq835lu7drv2xwl2wywfgse35a3x"},
+		{mustBuild(NewBaseAddress(Mainnet, script, stakeKey)), "addr1z8phkx6acpnf78fuvxn0mkew3l0fd058hzquvz7w36x4gten0d3vllmyqwsx5wktcd8cc3sq835lu7drv2xwl2wywfgs9yc0hh"},
+		{mustBuild(NewBaseAddress(Mainnet, paymentKey, script)), "addr1yx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzerkr0vd4msrxnuwnccdxlhdjar77j6lg0wypcc9uar5d2shs2z78ve"},
+		{mustBuild(NewBaseAddress(Mainnet, script, script)), "addr1x8phkx6acpnf78fuvxn0mkew3l0fd058hzquvz7w36x4gt7r0vd4msrxnuwnccdxlhdjar77j6lg0wypcc9uar5d2shskhj42g"},
+		{mustBuild(NewPointerAddress(Mainnet, paymentKey, pointer)), "addr1gx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzer5pnz75xxcrzqf96k"},
+		{mustBuild(NewPointerAddress(Mainnet, script, pointer)), "addr128phkx6acpnf78fuvxn0mkew3l0fd058hzquvz7w36x4gtupnz75xxcrtw79hu"},
+		{mustBuild(NewEnterpriseAddress(Mainnet, paymentKey)), "addr1vx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzers66hrl8"},
+		{mustBuild(NewEnterpriseAddress(Mainnet, script)), "addr1w8phkx6acpnf78fuvxn0mkew3l0fd058hzquvz7w36x4gtcyjy7wx"},
+		{mustBuild(NewRewardAddress(Mainnet, stakeKey)), "stake1uyehkck0lajq8gr28t9uxnuvgcqrc6070x3k9r8048z8y5gh6ffgw"},
+		{mustBuild(NewRewardAddress(Mainnet, script)), "stake178phkx6acpnf78fuvxn0mkew3l0fd058hzquvz7w36x4gtcccycj5"},
+		{mustBuild(NewBaseAddress(Testnet, paymentKey, stakeKey)), "addr_test1qz2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzer3n0d3vllmyqwsx5wktcd8cc3sq835lu7drv2xwl2wywfgs68faae"},
+	}
+	for _, tc := range testcases {
+		assert.Equal(tc.expected, tc.addr.String())
+		parsed, err := Parse(tc.expected)
+		if assert.NoError(err) {
+			assert.Equal(tc.addr, parsed)
+		}
+	}
+}
+
+func TestParseByron(t *testing.T) {
+	assert := assert.New(t)
+	s := "37btjrVyb4KDXBNC4haBVPCrro8AQPHwvCMp3RFhhSVWwfFmZ6wwzSK6JK1hY6wHNmtrpTf1kdbva8TCneM2YsiXT7mrzT21EacHnPpz5YyUdj64na"
+	addr, err := Parse(s)
+	if assert.NoError(err) {
+		assert.Equal(KindByron, addr.Kind)
+		assert.Equal(Testnet, addr.Network)
+		if assert.NotNil(addr.ProtocolMagic) {
+			assert.Equal(uint32(1097911063), *addr.ProtocolMagic)
+		}
+		assert.Equal(s, addr.String())
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	assert := assert.New(t)
+	_, err := Parse("addr1vx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzers66hrl9")
+	assert.Error(err)
+	// mainnet header with testnet prefix
+	_, err = Parse("addr_test1vx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzers66hrl8")
+	assert.Error(err)
+	_, err = NewEnterpriseAddress(Mainnet, NewKeyCredential("abcd"))
+	assert.Error(err)
+}
+
+func TestValidateNetwork(t *testing.T) {
+	addr, err := Parse("addr_test1wr37myp6qxqjd5g2de002z27zecggjfwqgdwn0wav8m4y3ggavlh3")
+	if assert.NoError(t, err) {
+		assert.Equal(t, KindEnterprise, addr.Kind)
+		assert.True(t, addr.Payment.IsScript())
+		assert.NoError(t, addr.ValidateNetwork(Testnet))
+		assert.ErrorIs(t, addr.ValidateNetwork(Mainnet), ErrWrongNetwork)
+	}
+}
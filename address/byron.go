@@ -0,0 +1,69 @@
+package address
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+
+	"github.com/minswap/pab-go/cbor"
+)
+
+// byronAttrNetworkMagic is the attribute key holding the protocol magic of
+// Byron addresses on networks other than mainnet.
+const byronAttrNetworkMagic uint64 = 2
+
+// parseByron decodes [#6.24(bytes .cbor [root, attributes, type]), crc32].
+func parseByron(raw []byte) (Address, error) {
+	v, err := cbor.Unmarshal(raw)
+	if err != nil {
+		return Address{}, fmt.Errorf("address: invalid byron address: %w", err)
+	}
+	outer, ok := v.([]interface{})
+	if !ok || len(outer) != 2 {
+		return Address{}, errors.New("address: byron address must be a 2-element array")
+	}
+	tag, ok := outer[0].(cbor.Tag)
+	if !ok || tag.Number != cbor.TagEncodedCBOR {
+		return Address{}, errors.New("address: byron address payload must be tagged CBOR")
+	}
+	payload, ok := tag.Content.([]byte)
+	if !ok {
+		return Address{}, errors.New("address: byron address payload must be bytes")
+	}
+	checksum, ok := outer[1].(uint64)
+	if !ok || uint64(crc32.ChecksumIEEE(payload)) != checksum {
+		return Address{}, errors.New("address: byron address checksum mismatch")
+	}
+
+	v, err = cbor.Unmarshal(payload)
+	if err != nil {
+		return Address{}, fmt.Errorf("address: invalid byron address payload: %w", err)
+	}
+	fields, ok := v.([]interface{})
+	if !ok || len(fields) != 3 {
+		return Address{}, errors.New("address: byron address payload must be a 3-element array")
+	}
+	attrs, ok := fields[1].(cbor.Map)
+	if !ok {
+		return Address{}, errors.New("address: byron address attributes must be a map")
+	}
+	addr := Address{Kind: KindByron, Network: Mainnet, byron: append([]byte{}, raw...)}
+	if rawMagic, ok := attrs.Get(byronAttrNetworkMagic); ok {
+		b, ok := rawMagic.([]byte)
+		if !ok {
+			return Address{}, errors.New("address: byron network magic must be bytes")
+		}
+		v, err := cbor.Unmarshal(b)
+		if err != nil {
+			return Address{}, fmt.Errorf("address: invalid byron network magic: %w", err)
+		}
+		magic, ok := v.(uint64)
+		if !ok || magic > 0xffffffff {
+			return Address{}, errors.New("address: byron network magic must be a uint32")
+		}
+		m := uint32(magic)
+		addr.ProtocolMagic = &m
+		addr.Network = Testnet
+	}
+	return addr, nil
+}
@@ -0,0 +1,118 @@
+package address
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+type CredentialType byte
+
+const (
+	KeyHashCredential    CredentialType = 0
+	ScriptHashCredential CredentialType = 1
+)
+
+func (t CredentialType) String() string {
+	if t == ScriptHashCredential {
+		return "script"
+	}
+	return "key"
+}
+
+// Credential is a payment or stake credential: a hex-encoded key hash or
+// script hash.
+type Credential struct {
+	Type CredentialType
+	Hash string
+}
+
+func NewKeyCredential(keyHash string) Credential {
+	return Credential{KeyHashCredential, keyHash}
+}
+
+func NewScriptCredential(scriptHash string) Credential {
+	return Credential{ScriptHashCredential, scriptHash}
+}
+
+func newCredential(isScript bool, hash []byte) Credential {
+	if isScript {
+		return NewScriptCredential(hex.EncodeToString(hash))
+	}
+	return NewKeyCredential(hex.EncodeToString(hash))
+}
+
+func (c Credential) IsScript() bool {
+	return c.Type == ScriptHashCredential
+}
+
+// Validate checks that the hash is a hex-encoded 28-byte hash.
+func (c Credential) Validate() error {
+	if c.Type != KeyHashCredential && c.Type != ScriptHashCredential {
+		return fmt.Errorf("unknown credential type %d", c.Type)
+	}
+	b, err := hex.DecodeString(c.Hash)
+	if err != nil {
+		return fmt.Errorf("credential hash is not hex: %w", err)
+	}
+	if len(b) != HashLength {
+		return fmt.Errorf("credential hash must have %d bytes, got %d", HashLength, len(b))
+	}
+	return nil
+}
+
+func (c Credential) hashBytes() []byte {
+	b, _ := hex.DecodeString(c.Hash)
+	return b
+}
+
+// Pointer locates the stake registration certificate of a pointer address.
+type Pointer struct {
+	Slot      uint64
+	TxIndex   uint64
+	CertIndex uint64
+}
+
+func (p Pointer) bytes() []byte {
+	var b []byte
+	for _, n := range []uint64{p.Slot, p.TxIndex, p.CertIndex} {
+		b = appendVarNat(b, n)
+	}
+	return b
+}
+
+func appendVarNat(b []byte, n uint64) []byte {
+	groups := []byte{byte(n & 0x7f)}
+	for n >>= 7; n > 0; n >>= 7 {
+		groups = append(groups, byte(n&0x7f)|0x80)
+	}
+	for i := len(groups) - 1; i >= 0; i-- {
+		b = append(b, groups[i])
+	}
+	return b
+}
+
+func decodePointer(b []byte) (Pointer, error) {
+	var nats [3]uint64
+	for i := range nats {
+		var n uint64
+		for {
+			if len(b) == 0 {
+				return Pointer{}, fmt.Errorf("address: truncated pointer")
+			}
+			if n > 1<<56 {
+				return Pointer{}, fmt.Errorf("address: pointer value overflows")
+			}
+			c := b[0]
+			b = b[1:]
+			n = n<<7 | uint64(c&0x7f)
+			if c&0x80 == 0 {
+				break
+			}
+		}
+		nats[i] = n
+	}
+	if len(b) > 0 {
+		return Pointer{}, fmt.Errorf("address: trailing bytes after pointer")
+	}
+	return Pointer{Slot: nats[0], TxIndex: nats[1], CertIndex: nats[2]}, nil
+}
@@ -0,0 +1,28 @@
+package bech32
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeEncode(t *testing.T) {
+	assert := assert.New(t)
+	// CIP-19 test vector: type-0 mainnet base address
+	addr := "addr1qx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzer3n0d3vllmyqwsx5wktcd8cc3sq835lu7drv2xwl2wywfgse35a3x"
+	hrp, data, err := Decode(addr)
+	if assert.NoError(err) {
+		assert.Equal("addr", hrp)
+		assert.Equal("019493315cd92eb5d8c4304e67b7e16ae36d61d34502694657811a2c8e337b62cfff6403a06a3acbc34f8c46003c69fe79a3628cefa9c47251", hex.EncodeToString(data))
+	}
+	s, err := Encode(hrp, data)
+	if assert.NoError(err) {
+		assert.Equal(addr, s)
+	}
+
+	_, _, err = Decode(addr[:len(addr)-1] + "q")
+	assert.ErrorIs(err, ErrInvalidChecksum)
+	_, _, err = Decode("addr1qX2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzer3n0d3vllmyqwsx5wktcd8cc3sq835lu7drv2xwl2wywfgse35a3x")
+	assert.ErrorIs(err, ErrMixedCase)
+}
@@ -0,0 +1,91 @@
+// Package cbor implements the subset of RFC 8949 CBOR needed to build and
+// inspect Cardano ledger structures.
+package cbor
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+const (
+	majorUnsigned byte = 0
+	majorNegative byte = 1
+	majorBytes    byte = 2
+	majorText     byte = 3
+	majorArray    byte = 4
+	majorMap      byte = 5
+	majorTag      byte = 6
+	majorSimple   byte = 7
+)
+
+const (
+	TagPositiveBignum uint64 = 2
+	TagNegativeBignum uint64 = 3
+	TagEncodedCBOR    uint64 = 24
+	TagRational       uint64 = 30
+	TagSet            uint64 = 258
+)
+
+var (
+	ErrUnexpectedEOF = errors.New("cbor: unexpected end of input")
+	ErrTrailingData  = errors.New("cbor: trailing data after item")
+)
+
+// Tag is a tagged data item.
+type Tag struct {
+	Number  uint64
+	Content interface{}
+}
+
+// RawMessage is an already encoded data item. It is written as is when
+// encoding and holds the exact bytes of an item when decoding with DecodeRaw.
+type RawMessage []byte
+
+// MapEntry is a key/value pair of a Map.
+type MapEntry struct {
+	Key   interface{}
+	Value interface{}
+}
+
+// Map is a CBOR map. Decoding keeps the wire order, encoding always writes
+// the entries in canonical order (shorter encoded key first, then bytewise).
+type Map []MapEntry
+
+// Get returns the value of the first entry whose key is equal to key.
+func (m Map) Get(key interface{}) (interface{}, bool) {
+	want, err := Marshal(key)
+	if err != nil {
+		return nil, false
+	}
+	for _, e := range m {
+		got, err := Marshal(e.Key)
+		if err == nil && string(got) == string(want) {
+			return e.Value, true
+		}
+	}
+	return nil, false
+}
+
+// IndefiniteArray is an array encoded with indefinite length, as used by
+// Plutus data lists.
+type IndefiniteArray []interface{}
+
+// Marshaler is implemented by types that can encode themselves.
+type Marshaler interface {
+	MarshalCBOR() ([]byte, error)
+}
+
+// SimpleValue is a major type 7 value that is neither a bool, null nor a float.
+type SimpleValue byte
+
+// Undefined is the CBOR undefined simple value.
+const Undefined SimpleValue = 23
+
+func errUnsupported(v interface{}) error {
+	return fmt.Errorf("cbor: unsupported type %T", v)
+}
+
+func bigIsUint64(n *big.Int) bool {
+	return n.Sign() >= 0 && n.IsUint64()
+}
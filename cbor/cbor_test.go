@@ -0,0 +1,77 @@
+package cbor
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshal(t *testing.T) {
+	assert := assert.New(t)
+	bigNum, _ := new(big.Int).SetString("18446744073709551616", 10)
+	testcases := []struct {
+		value interface{}
+		hex   string
+	}{
+		{uint64(0), "00"},
+		{uint64(23), "17"},
+		{uint64(24), "1818"},
+		{uint64(1000000), "1a000f4240"},
+		{int64(-1), "20"},
+		{int64(-1000), "3903e7"},
+		{bigNum, "c249010000000000000000"},
+		{[]byte{1, 2, 3, 4}, "4401020304"},
+		{"IETF", "6449455446"},
+		{[]interface{}{uint64(1), []interface{}{uint64(2), uint64(3)}}, "8201820203"},
+		{IndefiniteArray{uint64(1), uint64(2)}, "9f0102ff"},
+		{Map{{"b", uint64(1)}, {uint64(10), uint64(2)}, {"a", uint64(3)}}, "a30a02616103616201"},
+		{Tag{121, []interface{}{}}, "d87980"},
+		{nil, "f6"},
+		{true, "f5"},
+	}
+	for _, tc := range testcases {
+		b, err := Marshal(tc.value)
+		if assert.NoError(err) {
+			assert.Equal(tc.hex, hex.EncodeToString(b))
+		}
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	assert := assert.New(t)
+	data, _ := hex.DecodeString("a2016162f6d818450102030405")
+	v, err := Unmarshal(data)
+	if !assert.NoError(err) {
+		return
+	}
+	m, ok := v.(Map)
+	assert.True(ok)
+	one, _ := m.Get(uint64(1))
+	assert.Equal("b", one)
+	assert.Equal(MapEntry{nil, Tag{TagEncodedCBOR, []byte{1, 2, 3, 4, 5}}}, m[1])
+
+	data, _ = hex.DecodeString("829f0102ff5f42010243030405ff")
+	v, err = Unmarshal(data)
+	if assert.NoError(err) {
+		assert.Equal([]interface{}{
+			[]interface{}{uint64(1), uint64(2)},
+			[]byte{1, 2, 3, 4, 5},
+		}, v)
+	}
+
+	_, err = Unmarshal([]byte{0x82, 0x01})
+	assert.ErrorIs(err, ErrUnexpectedEOF)
+	_, err = Unmarshal([]byte{0x01, 0x02})
+	assert.ErrorIs(err, ErrTrailingData)
+}
+
+func TestDecodeRaw(t *testing.T) {
+	data, _ := hex.DecodeString("83010203" + "d81843a10102")
+	raw, rest, err := DecodeRaw(data)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "83010203", hex.EncodeToString(raw))
+		assert.Equal(t, "d81843a10102", hex.EncodeToString(rest))
+	}
+}
@@ -0,0 +1,296 @@
+package cbor
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+const maxDepth = 256
+
+// Unmarshal decodes a single data item that must span all of data.
+//
+// Unsigned integers decode to uint64, negative integers to int64 (or
+// *big.Int when they do not fit), bignum tags to *big.Int, byte strings to
+// []byte, text strings to string, arrays to []interface{}, maps to Map, other
+// tags to Tag and simple values to bool, nil, float64 or SimpleValue.
+func Unmarshal(data []byte) (interface{}, error) {
+	v, rest, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, ErrTrailingData
+	}
+	return v, nil
+}
+
+// Decode decodes the first data item of data and returns the remaining bytes.
+func Decode(data []byte) (interface{}, []byte, error) {
+	d := decoder{data: data}
+	v, err := d.value(0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return v, d.data[d.off:], nil
+}
+
+// DecodeRaw returns the encoded bytes of the first data item of data and the
+// remaining bytes.
+func DecodeRaw(data []byte) (RawMessage, []byte, error) {
+	d := decoder{data: data}
+	if err := d.skip(0); err != nil {
+		return nil, nil, err
+	}
+	return RawMessage(data[:d.off]), data[d.off:], nil
+}
+
+type decoder struct {
+	data []byte
+	off  int
+}
+
+func (d *decoder) byte() (byte, error) {
+	if d.off >= len(d.data) {
+		return 0, ErrUnexpectedEOF
+	}
+	b := d.data[d.off]
+	d.off++
+	return b, nil
+}
+
+func (d *decoder) take(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.off) {
+		return nil, ErrUnexpectedEOF
+	}
+	b := d.data[d.off : d.off+int(n)]
+	d.off += int(n)
+	return b, nil
+}
+
+// head reads an initial byte and its argument. indefinite is set when the
+// additional information is 31.
+func (d *decoder) head() (major byte, arg uint64, indefinite bool, err error) {
+	ib, err := d.byte()
+	if err != nil {
+		return 0, 0, false, err
+	}
+	major, info := ib>>5, ib&0x1f
+	switch {
+	case info < 24:
+		return major, uint64(info), false, nil
+	case info <= 27:
+		b, err := d.take(1 << (info - 24))
+		if err != nil {
+			return 0, 0, false, err
+		}
+		switch len(b) {
+		case 1:
+			arg = uint64(b[0])
+		case 2:
+			arg = uint64(binary.BigEndian.Uint16(b))
+		case 4:
+			arg = uint64(binary.BigEndian.Uint32(b))
+		default:
+			arg = binary.BigEndian.Uint64(b)
+		}
+		return major, arg, false, nil
+	case info == 31:
+		if major == majorUnsigned || major == majorNegative || major == majorTag {
+			return 0, 0, false, fmt.Errorf("cbor: indefinite length not allowed for major type %d", major)
+		}
+		return major, 0, true, nil
+	default:
+		return 0, 0, false, fmt.Errorf("cbor: reserved additional information %d", info)
+	}
+}
+
+func (d *decoder) isBreak() bool {
+	if d.off < len(d.data) && d.data[d.off] == 0xff {
+		d.off++
+		return true
+	}
+	return false
+}
+
+func (d *decoder) value(depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("cbor: nesting deeper than %d", maxDepth)
+	}
+	start := d.off
+	major, arg, indefinite, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case majorUnsigned:
+		return arg, nil
+	case majorNegative:
+		if arg <= math.MaxInt64 {
+			return -1 - int64(arg), nil
+		}
+		n := new(big.Int).SetUint64(arg)
+		return n.Neg(n).Sub(n, big.NewInt(1)), nil
+	case majorBytes, majorText:
+		var b []byte
+		if indefinite {
+			for !d.isBreak() {
+				chunkMajor, n, chunkIndefinite, err := d.head()
+				if err != nil {
+					return nil, err
+				}
+				if chunkMajor != major || chunkIndefinite {
+					return nil, fmt.Errorf("cbor: invalid chunk in indefinite string")
+				}
+				chunk, err := d.take(n)
+				if err != nil {
+					return nil, err
+				}
+				b = append(b, chunk...)
+			}
+		} else {
+			chunk, err := d.take(arg)
+			if err != nil {
+				return nil, err
+			}
+			b = append([]byte{}, chunk...)
+		}
+		if major == majorText {
+			return string(b), nil
+		}
+		return b, nil
+	case majorArray:
+		arr := make([]interface{}, 0)
+		for i := uint64(0); indefinite || i < arg; i++ {
+			if indefinite && d.isBreak() {
+				break
+			}
+			item, err := d.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, item)
+		}
+		return arr, nil
+	case majorMap:
+		m := make(Map, 0)
+		for i := uint64(0); indefinite || i < arg; i++ {
+			if indefinite && d.isBreak() {
+				break
+			}
+			k, err := d.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			v, err := d.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			m = append(m, MapEntry{k, v})
+		}
+		return m, nil
+	case majorTag:
+		content, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if arg == TagPositiveBignum || arg == TagNegativeBignum {
+			b, ok := content.([]byte)
+			if !ok {
+				return nil, fmt.Errorf("cbor: bignum content must be a byte string")
+			}
+			n := new(big.Int).SetBytes(b)
+			if arg == TagNegativeBignum {
+				n.Neg(n).Sub(n, big.NewInt(1))
+			}
+			return n, nil
+		}
+		return Tag{arg, content}, nil
+	default:
+		return d.simple(start, arg)
+	}
+}
+
+func (d *decoder) simple(start int, arg uint64) (interface{}, error) {
+	switch info := d.data[start] & 0x1f; info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22:
+		return nil, nil
+	case 25:
+		return float64(halfToFloat(uint16(arg))), nil
+	case 26:
+		return float64(math.Float32frombits(uint32(arg))), nil
+	case 27:
+		return math.Float64frombits(arg), nil
+	case 31:
+		return nil, fmt.Errorf("cbor: unexpected break")
+	default:
+		return SimpleValue(arg), nil
+	}
+}
+
+func (d *decoder) skip(depth int) error {
+	if depth > maxDepth {
+		return fmt.Errorf("cbor: nesting deeper than %d", maxDepth)
+	}
+	major, arg, indefinite, err := d.head()
+	if err != nil {
+		return err
+	}
+	switch major {
+	case majorBytes, majorText:
+		if indefinite {
+			for !d.isBreak() {
+				if err := d.skip(depth + 1); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		_, err := d.take(arg)
+		return err
+	case majorArray, majorMap:
+		items := arg
+		if major == majorMap {
+			items *= 2
+		}
+		for i := uint64(0); indefinite || i < items; i++ {
+			if indefinite && d.isBreak() {
+				return nil
+			}
+			if err := d.skip(depth + 1); err != nil {
+				return err
+			}
+		}
+		return nil
+	case majorTag:
+		return d.skip(depth + 1)
+	default:
+		if indefinite {
+			return fmt.Errorf("cbor: unexpected break")
+		}
+		return nil
+	}
+}
+
+func halfToFloat(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exp := int(h>>10) & 0x1f
+	frac := uint32(h & 0x3ff)
+	switch exp {
+	case 0:
+		f := float32(frac) / (1 << 24)
+		if sign != 0 {
+			return -f
+		}
+		return f
+	case 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | frac<<13)
+	default:
+		return math.Float32frombits(sign | uint32(exp-15+127)<<23 | frac<<13)
+	}
+}
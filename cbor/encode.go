@@ -0,0 +1,233 @@
+package cbor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"math/big"
+	"sort"
+)
+
+// AppendHead appends the initial byte and argument of a data item.
+func AppendHead(dst []byte, major byte, n uint64) []byte {
+	m := major << 5
+	switch {
+	case n < 24:
+		return append(dst, m|byte(n))
+	case n <= math.MaxUint8:
+		return append(dst, m|24, byte(n))
+	case n <= math.MaxUint16:
+		return append(dst, m|25, byte(n>>8), byte(n))
+	case n <= math.MaxUint32:
+		return append(dst, m|26, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	default:
+		return appendUint64(append(dst, m|27), n)
+	}
+}
+
+func appendUint64(dst []byte, n uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], n)
+	return append(dst, b[:]...)
+}
+
+func AppendUint(dst []byte, n uint64) []byte {
+	return AppendHead(dst, majorUnsigned, n)
+}
+
+func AppendInt(dst []byte, n int64) []byte {
+	if n >= 0 {
+		return AppendHead(dst, majorUnsigned, uint64(n))
+	}
+	return AppendHead(dst, majorNegative, uint64(-(n + 1)))
+}
+
+// AppendBigInt appends n as a plain integer when it fits in 64 bits and as a
+// bignum otherwise.
+func AppendBigInt(dst []byte, n *big.Int) []byte {
+	if bigIsUint64(n) {
+		return AppendUint(dst, n.Uint64())
+	}
+	if n.Sign() < 0 {
+		abs := new(big.Int).Neg(n)
+		abs.Sub(abs, big.NewInt(1))
+		if abs.IsUint64() {
+			return AppendHead(dst, majorNegative, abs.Uint64())
+		}
+		dst = AppendHead(dst, majorTag, TagNegativeBignum)
+		return AppendBytes(dst, abs.Bytes())
+	}
+	dst = AppendHead(dst, majorTag, TagPositiveBignum)
+	return AppendBytes(dst, n.Bytes())
+}
+
+func AppendBytes(dst []byte, b []byte) []byte {
+	dst = AppendHead(dst, majorBytes, uint64(len(b)))
+	return append(dst, b...)
+}
+
+// AppendChunkedBytes appends b as an indefinite-length byte string made of
+// chunks of at most size bytes, or as a plain byte string if it is short enough.
+func AppendChunkedBytes(dst []byte, b []byte, size int) []byte {
+	if len(b) <= size {
+		return AppendBytes(dst, b)
+	}
+	dst = append(dst, majorBytes<<5|31)
+	for len(b) > 0 {
+		n := size
+		if len(b) < n {
+			n = len(b)
+		}
+		dst = AppendBytes(dst, b[:n])
+		b = b[n:]
+	}
+	return append(dst, 0xff)
+}
+
+func AppendText(dst []byte, s string) []byte {
+	dst = AppendHead(dst, majorText, uint64(len(s)))
+	return append(dst, s...)
+}
+
+func AppendArrayHeader(dst []byte, n int) []byte {
+	return AppendHead(dst, majorArray, uint64(n))
+}
+
+func AppendMapHeader(dst []byte, n int) []byte {
+	return AppendHead(dst, majorMap, uint64(n))
+}
+
+func AppendTag(dst []byte, number uint64) []byte {
+	return AppendHead(dst, majorTag, number)
+}
+
+func AppendNull(dst []byte) []byte {
+	return append(dst, 0xf6)
+}
+
+func AppendBool(dst []byte, b bool) []byte {
+	if b {
+		return append(dst, 0xf5)
+	}
+	return append(dst, 0xf4)
+}
+
+// Marshal encodes v. Supported types are nil, bool, signed and unsigned
+// integers, *big.Int, []byte, string, []interface{}, IndefiniteArray, Map,
+// Tag, RawMessage, SimpleValue, float64 and Marshaler.
+func Marshal(v interface{}) ([]byte, error) {
+	return appendValue(nil, v)
+}
+
+func appendValue(dst []byte, v interface{}) ([]byte, error) {
+	switch x := v.(type) {
+	case nil:
+		return AppendNull(dst), nil
+	case bool:
+		return AppendBool(dst, x), nil
+	case int:
+		return AppendInt(dst, int64(x)), nil
+	case int32:
+		return AppendInt(dst, int64(x)), nil
+	case int64:
+		return AppendInt(dst, x), nil
+	case uint:
+		return AppendUint(dst, uint64(x)), nil
+	case uint8:
+		return AppendUint(dst, uint64(x)), nil
+	case uint16:
+		return AppendUint(dst, uint64(x)), nil
+	case uint32:
+		return AppendUint(dst, uint64(x)), nil
+	case uint64:
+		return AppendUint(dst, x), nil
+	case *big.Int:
+		return AppendBigInt(dst, x), nil
+	case []byte:
+		return AppendBytes(dst, x), nil
+	case string:
+		return AppendText(dst, x), nil
+	case RawMessage:
+		return append(dst, x...), nil
+	case SimpleValue:
+		if x < 24 {
+			return append(dst, majorSimple<<5|byte(x)), nil
+		}
+		return append(dst, majorSimple<<5|24, byte(x)), nil
+	case float64:
+		return appendUint64(append(dst, majorSimple<<5|27), math.Float64bits(x)), nil
+	case Tag:
+		dst = AppendTag(dst, x.Number)
+		return appendValue(dst, x.Content)
+	case []interface{}:
+		dst = AppendArrayHeader(dst, len(x))
+		return appendItems(dst, x)
+	case IndefiniteArray:
+		dst = append(dst, majorArray<<5|31)
+		dst, err := appendItems(dst, x)
+		if err != nil {
+			return nil, err
+		}
+		return append(dst, 0xff), nil
+	case Map:
+		return appendMap(dst, x)
+	case Marshaler:
+		b, err := x.MarshalCBOR()
+		if err != nil {
+			return nil, err
+		}
+		return append(dst, b...), nil
+	default:
+		return nil, errUnsupported(v)
+	}
+}
+
+func appendItems(dst []byte, items []interface{}) ([]byte, error) {
+	var err error
+	for _, item := range items {
+		if dst, err = appendValue(dst, item); err != nil {
+			return nil, err
+		}
+	}
+	return dst, nil
+}
+
+type encodedEntry struct {
+	key, value []byte
+}
+
+func appendMap(dst []byte, m Map) ([]byte, error) {
+	entries := make([]encodedEntry, len(m))
+	for i, e := range m {
+		k, err := Marshal(e.Key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := Marshal(e.Value)
+		if err != nil {
+			return nil, err
+		}
+		entries[i] = encodedEntry{k, v}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return CompareCanonical(entries[i].key, entries[j].key) < 0
+	})
+	dst = AppendMapHeader(dst, len(entries))
+	for _, e := range entries {
+		dst = append(dst, e.key...)
+		dst = append(dst, e.value...)
+	}
+	return dst, nil
+}
+
+// CompareCanonical orders two encoded keys by the RFC 7049 canonical rule:
+// shorter first, then bytewise.
+func CompareCanonical(a, b []byte) int {
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	return bytes.Compare(a, b)
+}
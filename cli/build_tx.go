@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"log"
 	"math/big"
@@ -8,6 +10,7 @@ import (
 	"strings"
 	"unicode"
 
+	"github.com/minswap/pab-go/cbor"
 	"github.com/minswap/pab-go/ledger"
 	"github.com/minswap/pab-go/txbuilder"
 )
@@ -19,9 +22,19 @@ func BuildInput(u txbuilder.TxInput) string {
 
 // Example: 100 lovelace + 42 5ca53b0eb10f317a5f1bf1bda679a04a8dd01c156643deee1d406ec2cc15e9e3.foo
 func BuildValue(val ledger.Value) string {
-	var parts []string
 	trimmedVal := val.Clone().Trim()
-	for c, amount := range trimmedVal {
+	return buildAmounts(trimmedVal.Assets(), trimmedVal)
+}
+
+// Example: 42 5ca53b0eb10f317a5f1bf1bda679a04a8dd01c156643deee1d406ec2cc15e9e3.foo + -1 5ca53b0eb10f317a5f1bf1bda679a04a8dd01c156643deee1d406ec2cc15e9e3.bar
+func BuildMintValue(val ledger.MintValue) string {
+	return buildAmounts(val.Assets(), val)
+}
+
+func buildAmounts(assets []ledger.Asset, amounts map[ledger.Asset]*big.Int) string {
+	var parts []string
+	for _, c := range assets {
+		amount := amounts[c]
 		if c == ledger.ADA {
 			parts = append(parts, fmt.Sprintf("%s lovelace", amount.String()))
 		} else if c.TokenName == "" {
@@ -57,7 +70,23 @@ func (cli *CardanoCLI) buildTempFile(suffix string, content string, temp *TempMa
 	return file.Name()
 }
 
-func (cli *CardanoCLI) buildTx(b txbuilder.TxBuilder, temp *TempManager) []string {
+func (cli *CardanoCLI) buildTextEnvelopeFile(suffix string, envelopeType string, v cbor.Marshaler, temp *TempManager) (string, error) {
+	b, err := v.MarshalCBOR()
+	if err != nil {
+		return "", err
+	}
+	content, err := json.Marshal(CBORFile{
+		Type:        envelopeType,
+		Description: "",
+		CBORHex:     hex.EncodeToString(b),
+	})
+	if err != nil {
+		return "", err
+	}
+	return cli.buildTempFile(suffix, string(content), temp), nil
+}
+
+func (cli *CardanoCLI) buildTx(b txbuilder.TxBuilder, temp *TempManager) ([]string, error) {
 	var args []string
 	eraFlag := ""
 	switch cli.Era {
@@ -71,6 +100,26 @@ func (cli *CardanoCLI) buildTx(b txbuilder.TxBuilder, temp *TempManager) []strin
 			eraFlag = "--babbage-era"
 			break
 		}
+	case Conway:
+		{
+			eraFlag = "--conway-era"
+			break
+		}
+	}
+	if cli.Era != Conway {
+		if len(b.Votes) > 0 || len(b.Proposals) > 0 {
+			return nil, fmt.Errorf("votes and proposals require Conway era, current era is %s", cli.Era)
+		}
+		for _, cert := range b.Certificates {
+			if cert.IsConway() {
+				return nil, fmt.Errorf("governance certificates require Conway era, current era is %s", cli.Era)
+			}
+		}
+	}
+	if b.MinimumADAParams != nil {
+		if err := b.ApplyMinimumADA(*b.MinimumADAParams); err != nil {
+			return nil, err
+		}
 	}
 	if b.IsRaw() {
 		args = []string{"transaction", "build-raw", eraFlag, "--fee", strconv.FormatInt(b.Fee, 10)}
@@ -97,6 +146,18 @@ func (cli *CardanoCLI) buildTx(b txbuilder.TxBuilder, temp *TempManager) []strin
 	for _, col := range b.Collaterals {
 		args = append(args, "--tx-in-collateral", BuildInput(col))
 	}
+	if err := b.ValidateAmounts(); err != nil {
+		return nil, err
+	}
+	if err := b.ValidateCollateral(); err != nil {
+		return nil, err
+	}
+	if b.CollateralReturn != nil {
+		args = append(args,
+			"--tx-out-return-collateral", BuildOutput(*b.CollateralReturn),
+			"--tx-total-collateral", strconv.FormatInt(b.TotalCollateral, 10),
+		)
+	}
 
 	// build outputs
 	for _, out := range b.PubKeyOutputs {
@@ -115,15 +176,19 @@ func (cli *CardanoCLI) buildTx(b txbuilder.TxBuilder, temp *TempManager) []strin
 			args = append(args,
 				"--tx-out-datum-embed-file", cli.buildTempFile("output-datum-embed", datum.DatumValue, temp),
 			)
+		case txbuilder.ScriptOutputInlineDatum:
+			args = append(args,
+				"--tx-out-inline-datum-file", cli.buildTempFile("output-inline-datum", datum.DatumValue, temp),
+			)
 		default:
 			panic(fmt.Sprintf("Unsupported datum type: %T", datum))
 		}
 	}
 
 	// build minting and burning
-	forgeVal := ledger.NewValue()
+	forgeVal := ledger.NewMintValue()
 	for _, mint := range b.Minting {
-		forgeVal.AddAll(mint.Value)
+		forgeVal.Mint(mint.Value)
 		args = append(args,
 			"--mint-script-file", mint.ScriptFilePath,
 			"--mint-redeemer-file", cli.buildTempFile("mint-redeemer", mint.RedeemerValue, temp),
@@ -133,16 +198,16 @@ func (cli *CardanoCLI) buildTx(b txbuilder.TxBuilder, temp *TempManager) []strin
 		}
 	}
 	mintScriptFilePaths := make(map[string]struct{}, 0)
+	var nativeMintScriptFilePaths []string
 	for _, mintNativeScript := range b.MintingNativeScript {
-		forgeVal.AddAll(mintNativeScript.Value)
+		forgeVal.Mint(mintNativeScript.Value)
 		if _, ok := mintScriptFilePaths[mintNativeScript.ScriptFilePath]; !ok {
 			mintScriptFilePaths[mintNativeScript.ScriptFilePath] = struct{}{}
+			nativeMintScriptFilePaths = append(nativeMintScriptFilePaths, mintNativeScript.ScriptFilePath)
 		}
 	}
 	for _, burn := range b.Burning {
-		for asset, amount := range burn.Value {
-			forgeVal.Add(asset, new(big.Int).Neg(amount))
-		}
+		forgeVal.Burn(burn.Value)
 		args = append(args,
 			"--mint-script-file", burn.ScriptFilePath,
 			"--mint-redeemer-file", cli.buildTempFile("mint-redeemer", burn.RedeemerValue, temp),
@@ -152,22 +217,21 @@ func (cli *CardanoCLI) buildTx(b txbuilder.TxBuilder, temp *TempManager) []strin
 		}
 	}
 	for _, burnNativeScript := range b.BurningNativeScript {
-		for asset, amount := range burnNativeScript.Value {
-			forgeVal.Add(asset, new(big.Int).Neg(amount))
-		}
+		forgeVal.Burn(burnNativeScript.Value)
 		if _, ok := mintScriptFilePaths[burnNativeScript.ScriptFilePath]; !ok {
 			mintScriptFilePaths[burnNativeScript.ScriptFilePath] = struct{}{}
+			nativeMintScriptFilePaths = append(nativeMintScriptFilePaths, burnNativeScript.ScriptFilePath)
 		}
 	}
 
-	for mintScriptFilePath := range mintScriptFilePaths {
+	for _, mintScriptFilePath := range nativeMintScriptFilePaths {
 		args = append(args,
 			"--mint-script-file", mintScriptFilePath,
 		)
 	}
 
 	if len(forgeVal) > 0 {
-		args = append(args, "--mint", BuildValue(forgeVal))
+		args = append(args, "--mint", BuildMintValue(forgeVal))
 	}
 	if b.ValidRangeFrom != nil {
 		args = append(args,
@@ -197,6 +261,74 @@ func (cli *CardanoCLI) buildTx(b txbuilder.TxBuilder, temp *TempManager) []strin
 		)
 	}
 
+	// build certificates and withdrawals
+	certType := "CertificateShelley"
+	if cli.Era == Conway {
+		certType = "CertificateConway"
+	}
+	for i, cert := range b.Certificates {
+		certFile, err := cli.buildTextEnvelopeFile("certificate", certType, cert, temp)
+		if err != nil {
+			return nil, fmt.Errorf("fail to encode certificate %d: %w", i, err)
+		}
+		args = append(args, "--certificate-file", certFile)
+		if cert.ScriptFilePath != "" {
+			args = append(args, "--certificate-script-file", cert.ScriptFilePath)
+			if cert.RedeemerValue != "" {
+				args = append(args, "--certificate-redeemer-file", cli.buildTempFile("certificate-redeemer", cert.RedeemerValue, temp))
+				if b.IsRaw() {
+					args = append(args, "--certificate-execution-units", BuildExUnits(cert.ExCPU, cert.ExMem))
+				}
+			}
+		}
+	}
+	for _, w := range b.Withdrawals {
+		args = append(args, "--withdrawal", fmt.Sprintf("%s+%d", w.RewardAddress, w.Amount))
+		if w.ScriptFilePath != "" {
+			args = append(args, "--withdrawal-script-file", w.ScriptFilePath)
+			if w.RedeemerValue != "" {
+				args = append(args, "--withdrawal-redeemer-file", cli.buildTempFile("withdrawal-redeemer", w.RedeemerValue, temp))
+				if b.IsRaw() {
+					args = append(args, "--withdrawal-execution-units", BuildExUnits(w.ExCPU, w.ExMem))
+				}
+			}
+		}
+	}
+
+	// build governance votes and proposals
+	for i, vote := range b.Votes {
+		voteFile, err := cli.buildTextEnvelopeFile("vote", "Governance voting procedures", vote, temp)
+		if err != nil {
+			return nil, fmt.Errorf("fail to encode vote %d: %w", i, err)
+		}
+		args = append(args, "--vote-file", voteFile)
+		if vote.ScriptFilePath != "" {
+			args = append(args, "--vote-script-file", vote.ScriptFilePath)
+			if vote.RedeemerValue != "" {
+				args = append(args, "--vote-redeemer-file", cli.buildTempFile("vote-redeemer", vote.RedeemerValue, temp))
+				if b.IsRaw() {
+					args = append(args, "--vote-execution-units", BuildExUnits(vote.ExCPU, vote.ExMem))
+				}
+			}
+		}
+	}
+	for i, proposal := range b.Proposals {
+		proposalFile, err := cli.buildTextEnvelopeFile("proposal", "Governance proposal", proposal, temp)
+		if err != nil {
+			return nil, fmt.Errorf("fail to encode proposal %d: %w", i, err)
+		}
+		args = append(args, "--proposal-file", proposalFile)
+		if proposal.ScriptFilePath != "" {
+			args = append(args, "--proposal-script-file", proposal.ScriptFilePath)
+			if proposal.RedeemerValue != "" {
+				args = append(args, "--proposal-redeemer-file", cli.buildTempFile("proposal-redeemer", proposal.RedeemerValue, temp))
+				if b.IsRaw() {
+					args = append(args, "--proposal-execution-units", BuildExUnits(proposal.ExCPU, proposal.ExMem))
+				}
+			}
+		}
+	}
+
 	args = append(args, "--protocol-params-file", cli.ProtocolParamsPath)
-	return args
+	return args, nil
 }
@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/minswap/pab-go/address"
+	"github.com/minswap/pab-go/ledger"
+	"github.com/minswap/pab-go/txbuilder"
+	"github.com/stretchr/testify/assert"
+)
+
+func argValues(args []string, flag string) []string {
+	var values []string
+	for i, arg := range args {
+		if arg == flag && i+1 < len(args) {
+			values = append(values, args[i+1])
+		}
+	}
+	return values
+}
+
+func TestBuildTxCertificatesAndWithdrawals(t *testing.T) {
+	assert := assert.New(t)
+	temp, err := NewTempManager()
+	if !assert.NoError(err) {
+		return
+	}
+	defer temp.Clean()
+
+	const rewardAddr = "stake_test17rphkx6acpnf78fuvxn0mkew3l0fd058hzquvz7w36x4gtcljw6kf"
+	stakeScript := address.NewScriptCredential("c37b1b5dc0669f1d3c61a6fddb2e8fde96be87b881c60bce8e8d542f")
+	c := &CardanoCLI{NetworkID: NetworkTestnetPreprod, Era: Babbage}
+	txb := txbuilder.New(
+		txbuilder.RegisterStake(stakeScript),
+		txbuilder.DelegateStakeScriptRaw(stakeScript, "9493315cd92eb5d8c4304e67b7e16ae36d61d34502694657811a2c8e", "stake.plutus", `{"constructor":0,"fields":[]}`, 100, 200),
+		txbuilder.WithdrawRewardsScriptRaw(rewardAddr, 0, "stake.plutus", `{"constructor":0,"fields":[]}`, 300, 400),
+		txbuilder.PayFee(200_000),
+	)
+	args, err := c.buildTx(txb, temp)
+	if !assert.NoError(err) {
+		return
+	}
+	certFiles := argValues(args, "--certificate-file")
+	if assert.Len(certFiles, 2) {
+		content, err := os.ReadFile(certFiles[1])
+		if assert.NoError(err) {
+			assert.True(strings.Contains(string(content), `"cborHex":"83028201581cc37b1b5dc0669f1d3c61a6fddb2e8fde96be87b881c60bce8e8d542f581c9493315cd92eb5d8c4304e67b7e16ae36d61d34502694657811a2c8e"`))
+			assert.True(strings.Contains(string(content), `"type":"CertificateShelley"`))
+		}
+	}
+	assert.Equal([]string{"stake.plutus"}, argValues(args, "--certificate-script-file"))
+	assert.Equal([]string{"(200,100)"}, argValues(args, "--certificate-execution-units"))
+	assert.Equal([]string{rewardAddr + "+0"}, argValues(args, "--withdrawal"))
+	assert.Equal([]string{"stake.plutus"}, argValues(args, "--withdrawal-script-file"))
+	assert.Len(argValues(args, "--withdrawal-redeemer-file"), 1)
+	assert.Equal([]string{"(400,300)"}, argValues(args, "--withdrawal-execution-units"))
+
+	txb.Add(txbuilder.WithdrawRewards(rewardAddr, -5))
+	_, err = c.buildTx(txb, temp)
+	assert.Error(err)
+}
+
+func TestBuildTxGovernance(t *testing.T) {
+	assert := assert.New(t)
+	temp, err := NewTempManager()
+	if !assert.NoError(err) {
+		return
+	}
+	defer temp.Clean()
+
+	drepScript := address.NewScriptCredential("c37b1b5dc0669f1d3c61a6fddb2e8fde96be87b881c60bce8e8d542f")
+	actionID := txbuilder.GovActionID{TxID: "5ca53b0eb10f317a5f1bf1bda679a04a8dd01c156643deee1d406ec2cc15e9e3", Index: 1}
+	txb := txbuilder.New(
+		txbuilder.RegisterDRepScript(drepScript, 500_000_000, nil, "drep.plutus", `{"constructor":0,"fields":[]}`),
+		txbuilder.CastVoteScript(
+			txbuilder.Voter{Kind: txbuilder.VoterDRepScript, Hash: drepScript.Hash},
+			actionID, txbuilder.VoteYes, nil, "drep.plutus", `{"constructor":1,"fields":[]}`,
+		),
+		txbuilder.ProposeWithGuardrail(
+			100_000_000_000,
+			"stake_test17rphkx6acpnf78fuvxn0mkew3l0fd058hzquvz7w36x4gtcljw6kf",
+			txbuilder.TreasuryWithdrawalsAction{
+				Withdrawals:         map[string]int64{"stake_test17rphkx6acpnf78fuvxn0mkew3l0fd058hzquvz7w36x4gtcljw6kf": 1_000_000},
+				GuardrailScriptHash: "fa24fb305126805cf2164c161d852a0e7330cf988f1fe558cf7d4a64",
+			},
+			txbuilder.Anchor{URL: "https://example.com/proposal.json", DataHash: "0000000000000000000000000000000000000000000000000000000000000000"},
+			"guardrail.plutus", `{"constructor":0,"fields":[]}`,
+		),
+		txbuilder.PayChangeTo("addr_test1qr2tn9mcgzu08lskmnekswwg9ghfrzhtlzx9t2vm4r96skx5hxthss9c70lpdh8ndquus23wjx9wh7yv2k5eh2xt4pvqzs2er6"),
+	)
+
+	babbage := &CardanoCLI{NetworkID: NetworkTestnetPreprod, Era: Babbage}
+	_, err = babbage.buildTx(txb, temp)
+	assert.Error(err)
+
+	conway := &CardanoCLI{NetworkID: NetworkTestnetPreprod, Era: Conway}
+	args, err := conway.buildTx(txb, temp)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Contains(args, "--conway-era")
+	certFiles := argValues(args, "--certificate-file")
+	if assert.Len(certFiles, 1) {
+		content, err := os.ReadFile(certFiles[0])
+		if assert.NoError(err) {
+			assert.Contains(string(content), `"type":"CertificateConway"`)
+			assert.Contains(string(content), `"cborHex":"84108201581cc37b1b5dc0669f1d3c61a6fddb2e8fde96be87b881c60bce8e8d542f1a1dcd6500f6"`)
+		}
+	}
+	voteFiles := argValues(args, "--vote-file")
+	if assert.Len(voteFiles, 1) {
+		content, err := os.ReadFile(voteFiles[0])
+		if assert.NoError(err) {
+			assert.Contains(string(content), `"cborHex":"a18203581cc37b1b5dc0669f1d3c61a6fddb2e8fde96be87b881c60bce8e8d542fa18258205ca53b0eb10f317a5f1bf1bda679a04a8dd01c156643deee1d406ec2cc15e9e3018201f6"`)
+		}
+	}
+	assert.Equal([]string{"drep.plutus"}, argValues(args, "--vote-script-file"))
+	assert.Len(argValues(args, "--proposal-file"), 1)
+	assert.Equal([]string{"guardrail.plutus"}, argValues(args, "--proposal-script-file"))
+	assert.Len(argValues(args, "--proposal-redeemer-file"), 1)
+}
+
+func TestBuildValue(t *testing.T) {
+	val := ledger.NewValue().
+		Add(ledger.NewAsset("3f6092645942a54a75186b25e0975b79e1f50895ad958b42015eb6d2", "4d494e53574150"), big.NewInt(3)).
+		Add(ledger.NewAsset("1d7f33bd23d85e1a25d87d86fac4f199c3197a2f7afeb662a0f34e1e", ""), big.NewInt(2)).
+		Add(ledger.ADA, big.NewInt(5))
+	assert.Equal(t,
+		"5 lovelace + 2 1d7f33bd23d85e1a25d87d86fac4f199c3197a2f7afeb662a0f34e1e + 3 3f6092645942a54a75186b25e0975b79e1f50895ad958b42015eb6d2.4d494e53574150",
+		BuildValue(val),
+	)
+}
+
+func TestBuildTxCollateralReturn(t *testing.T) {
+	temp, err := NewTempManager()
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer temp.Clean()
+
+	const addr = "addr_test1qpmtp5t0t5y6cqkaz7rfsyrx7mld77kpvksgkwm0p7en7qum7a589n30e80tclzrrnj8qr4qvzj6al0vpgtnmrkkksnqd8upj0"
+	c := &CardanoCLI{NetworkID: NetworkTestnetPreprod, Era: Babbage}
+	col := ledger.Utxo{
+		TxID:    "5ca53b0eb10f317a5f1bf1bda679a04a8dd01c156643deee1d406ec2cc15e9e3",
+		TxIndex: 1,
+		Address: addr,
+		Value:   ledger.NewValue().Add(ledger.ADA, big.NewInt(5_000_000)),
+	}
+	args, err := c.buildTx(txbuilder.New(
+		txbuilder.UseCollateralsWithReturn([]ledger.Utxo{col}, addr, 300_000),
+		txbuilder.PayChangeTo(addr),
+	), temp)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{BuildInput(txbuilder.TxInput{TxID: col.TxID, TxIndex: col.TxIndex})}, argValues(args, "--tx-in-collateral"))
+	assert.Equal(t, []string{addr + " + 4700000 lovelace"}, argValues(args, "--tx-out-return-collateral"))
+	assert.Equal(t, []string{"300000"}, argValues(args, "--tx-total-collateral"))
+}
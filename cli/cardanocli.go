@@ -2,6 +2,7 @@ package cli
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -11,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/minswap/pab-go/ledger"
+	"github.com/minswap/pab-go/provider"
 	"github.com/minswap/pab-go/txbuilder"
 )
 
@@ -19,6 +21,7 @@ type Era = string
 const (
 	Alonzo  Era = "Alonzo"
 	Babbage Era = "Babbage"
+	Conway  Era = "Conway"
 )
 
 type Options struct {
@@ -30,6 +33,9 @@ type Options struct {
 	// If you want to log a few commands, set them here. Empty mean log all commands (if LogCommand is true).
 	// Example: []string{"transaction build", "transaction submit"}
 	WhitelistCommandLogs []string
+	// QueryChunk splits UTxO queries of many addresses or tx-ins, which would
+	// otherwise exceed the argument size limit of the OS.
+	QueryChunk provider.ChunkOptions
 }
 
 type CardanoCLI struct {
@@ -40,8 +46,11 @@ type CardanoCLI struct {
 	LogCommand           bool
 	LogTempFile          bool
 	WhitelistCommandLogs []string
+	QueryChunk           provider.ChunkOptions
 }
 
+var _ TxBuildBackend = (*CardanoCLI)(nil)
+
 func New(options Options) (*CardanoCLI, error) {
 	cli := &CardanoCLI{
 		CLIPath:              options.CLIPath,
@@ -50,6 +59,7 @@ func New(options Options) (*CardanoCLI, error) {
 		LogCommand:           options.LogCommand,
 		LogTempFile:          options.LogTempFile,
 		WhitelistCommandLogs: options.WhitelistCommandLogs,
+		QueryChunk:           options.QueryChunk,
 	}
 	if cli.CLIPath == "" {
 		cli.CLIPath = "cardano-cli"
@@ -78,9 +88,14 @@ func New(options Options) (*CardanoCLI, error) {
 				cli.Era = "Babbage"
 				break
 			}
+		case "Conway":
+			{
+				cli.Era = "Conway"
+				break
+			}
 		default:
 			{
-				return nil, fmt.Errorf("fail to parse Era: Era must be Alonzo or Babbage, actual %s", tip.Era)
+				return nil, fmt.Errorf("fail to parse Era: Era must be Alonzo, Babbage or Conway, actual %s", tip.Era)
 			}
 		}
 	}
@@ -149,48 +164,66 @@ func (c *CardanoCLI) GetTip() (*Tip, error) {
 }
 
 func (c *CardanoCLI) GetAllUtxos() ([]ledger.Utxo, error) {
-	tempManager, err := NewTempManager()
-	if err != nil {
-		return nil, fmt.Errorf("fail to create TempManager: %w", err)
-	}
-	defer tempManager.Clean()
+	return c.FilterAllUtxos(nil)
+}
 
-	out := tempManager.NewFile("query-utxo")
-	_, err = c.RunWithNetwork("query", "utxo", "--whole-utxo", "--out-file", out.Name())
-	if err != nil {
-		return nil, fmt.Errorf("fail to query utxo: %w", err)
-	}
-	utxos, err := parseQueryUtxoOutFile(out)
+// FilterAllUtxos returns the UTxOs of the whole UTxO set for which keep
+// returns true, decoding them one at a time.
+func (c *CardanoCLI) FilterAllUtxos(keep func(ledger.Utxo) bool) ([]ledger.Utxo, error) {
+	utxos := make([]ledger.Utxo, 0)
+	err := c.ForEachUtxo(keep, func(u ledger.Utxo) error {
+		utxos = append(utxos, u)
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("fail to parse utxos: %w", err)
+		return nil, err
 	}
 	return utxos, nil
 }
 
-// GetUtxosByAddresses return utxos from Bech32-encoded address(es)
-func (c *CardanoCLI) GetUtxosByAddresses(addresses ...string) ([]ledger.Utxo, error) {
+// ForEachUtxo streams the whole UTxO set to fn, see DecodeQueryUtxo.
+func (c *CardanoCLI) ForEachUtxo(keep func(ledger.Utxo) bool, fn func(ledger.Utxo) error) error {
 	tempManager, err := NewTempManager()
 	if err != nil {
-		return nil, fmt.Errorf("fail to create TempManager: %w", err)
+		return fmt.Errorf("fail to create TempManager: %w", err)
 	}
 	defer tempManager.Clean()
 
 	out := tempManager.NewFile("query-utxo")
-	args := []string{"query", "utxo", "--out-file", out.Name()}
-	for _, addr := range addresses {
-		args = append(args, "--address", addr)
-	}
-	if _, err := c.RunWithNetwork(args...); err != nil {
-		return nil, fmt.Errorf("fail to query utxo: %w", err)
-	}
-	utxos, err := parseQueryUtxoOutFile(out)
+	_, err = c.RunWithNetwork("query", "utxo", "--whole-utxo", "--out-file", out.Name())
 	if err != nil {
-		return nil, fmt.Errorf("fail to parse utxos: %w", err)
+		return fmt.Errorf("fail to query utxo: %w", err)
 	}
-	return utxos, nil
+	if err := DecodeQueryUtxo(out, keep, fn); err != nil {
+		return fmt.Errorf("fail to parse utxos: %w", err)
+	}
+	return nil
+}
+
+// GetUtxosByAddresses return utxos from Bech32-encoded address(es). Many
+// addresses are queried by chunks, see QueryChunk; when some chunks fail, the
+// utxos of the others are returned with a *provider.ChunkError.
+func (c *CardanoCLI) GetUtxosByAddresses(addresses ...string) ([]ledger.Utxo, error) {
+	return provider.QueryChunked(len(addresses), c.QueryChunk, func(start, end int) ([]ledger.Utxo, error) {
+		var args []string
+		for _, addr := range addresses[start:end] {
+			args = append(args, "--address", addr)
+		}
+		return c.queryUtxo(args...)
+	})
 }
 
 func (c *CardanoCLI) GetUtxosByTxIns(txIns ...TxIn) ([]ledger.Utxo, error) {
+	return provider.QueryChunked(len(txIns), c.QueryChunk, func(start, end int) ([]ledger.Utxo, error) {
+		var args []string
+		for _, in := range txIns[start:end] {
+			args = append(args, "--tx-in", in.String())
+		}
+		return c.queryUtxo(args...)
+	})
+}
+
+func (c *CardanoCLI) queryUtxo(filters ...string) ([]ledger.Utxo, error) {
 	tempManager, err := NewTempManager()
 	if err != nil {
 		return nil, fmt.Errorf("fail to create TempManager: %w", err)
@@ -198,10 +231,7 @@ func (c *CardanoCLI) GetUtxosByTxIns(txIns ...TxIn) ([]ledger.Utxo, error) {
 	defer tempManager.Clean()
 
 	out := tempManager.NewFile("query-utxo")
-	args := []string{"query", "utxo", "--out-file", out.Name()}
-	for _, in := range txIns {
-		args = append(args, "--tx-in", fmt.Sprintf("%s#%d", in.TxID, in.TxIndex))
-	}
+	args := append([]string{"query", "utxo", "--out-file", out.Name()}, filters...)
 	if _, err := c.RunWithNetwork(args...); err != nil {
 		return nil, fmt.Errorf("fail to query utxo: %w", err)
 	}
@@ -213,6 +243,10 @@ func (c *CardanoCLI) GetUtxosByTxIns(txIns ...TxIn) ([]ledger.Utxo, error) {
 }
 
 func (c *CardanoCLI) BuildTx(txb txbuilder.TxBuilder) (tx *Tx, err error) {
+	if err := txb.ValidateNetwork(AddressNetwork(c.NetworkID)); err != nil {
+		return nil, fmt.Errorf("invalid tx: %w", err)
+	}
+
 	tempManager, err := NewTempManager()
 	if err != nil {
 		return nil, fmt.Errorf("fail to create TempManager: %w", err)
@@ -221,7 +255,10 @@ func (c *CardanoCLI) BuildTx(txb txbuilder.TxBuilder) (tx *Tx, err error) {
 
 	// Build tx
 	rawTx := tempManager.NewFile("raw-tx")
-	args := c.buildTx(txb, tempManager)
+	args, err := c.buildTx(txb, tempManager)
+	if err != nil {
+		return nil, fmt.Errorf("fail to build cardano-cli arguments: %w", err)
+	}
 	args = append(args, "--out-file", rawTx.Name())
 	if txb.IsRaw() {
 		_, err = c.Run(args...)
@@ -256,6 +293,10 @@ func (c *CardanoCLI) BuildTx(txb txbuilder.TxBuilder) (tx *Tx, err error) {
 }
 
 func (c *CardanoCLI) BuildAndSignTx(txb txbuilder.TxBuilder, skeyFilePaths ...string) (tx *Tx, err error) {
+	if err := txb.ValidateNetwork(AddressNetwork(c.NetworkID)); err != nil {
+		return nil, fmt.Errorf("invalid tx: %w", err)
+	}
+
 	tempManager, err := NewTempManager()
 	if err != nil {
 		return nil, fmt.Errorf("fail to create TempManager: %w", err)
@@ -264,7 +305,10 @@ func (c *CardanoCLI) BuildAndSignTx(txb txbuilder.TxBuilder, skeyFilePaths ...st
 
 	// Build tx
 	rawTx := tempManager.NewFile("raw-tx")
-	args := c.buildTx(txb, tempManager)
+	args, err := c.buildTx(txb, tempManager)
+	if err != nil {
+		return nil, fmt.Errorf("fail to build cardano-cli arguments: %w", err)
+	}
 	args = append(args, "--out-file", rawTx.Name())
 	if txb.IsRaw() {
 		_, err = c.Run(args...)
@@ -326,21 +370,8 @@ func (c *CardanoCLI) SubmitTxWithSkey(tx *Tx, skeyFilePaths ...string) error {
 
 	// Write tx body file
 	txBody := tempManager.NewFile("tx-body")
-	txType := ""
-	switch c.Era {
-	case Alonzo:
-		{
-			txType = "Unwitnessed Tx AlonzoEra"
-			break
-		}
-	case Babbage:
-		{
-			txType = "Unwitnessed Tx BabbageEra"
-			break
-		}
-	}
 	content, err := json.Marshal(CBORFile{
-		Type:        txType,
+		Type:        c.txFileType(false),
 		Description: "",
 		CBORHex:     tx.TxBody,
 	})
@@ -369,7 +400,7 @@ func (c *CardanoCLI) SubmitTxWithSkey(tx *Tx, skeyFilePaths ...string) error {
 	}
 
 	// Submit tx
-	if _, err := c.RunWithNetwork("transaction", "submit", "--tx-file", signedTx.Name()); err != nil {
+	if err := c.submitTxFile(signedTx.Name()); err != nil {
 		return fmt.Errorf("fail to submit tx: %w", err)
 	}
 
@@ -392,10 +423,18 @@ func (c *CardanoCLI) GetScriptAddress(scriptPath string) (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
+// GetStakingScriptAddress returns the address of a payment script staked with a
+// stake verification key file. It is built natively and falls back to
+// cardano-cli for key files pab-go cannot decode.
 func (c *CardanoCLI) GetStakingScriptAddress(scriptPath string, stakeVkeyPath string) (string, error) {
-	out, err := c.RunWithNetwork(
-		"address",
-		"build",
+	addr, err := c.GetScriptAddressWithStake(scriptPath, StakeVkeyFile{stakeVkeyPath})
+	if err == nil {
+		return addr, nil
+	}
+	if !errors.Is(err, errNotNative) {
+		return "", fmt.Errorf("fail to get staking script address: %w", err)
+	}
+	out, err := c.RunWithNetwork("address", "build",
 		"--payment-script-file", scriptPath,
 		"--stake-verification-key-file", stakeVkeyPath,
 	)
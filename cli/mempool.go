@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/minswap/pab-go/provider"
+)
+
+var _ provider.MempoolQuerier = (*CardanoCLI)(nil)
+
+// MempoolInfo is the output of query tx-mempool info.
+type MempoolInfo struct {
+	CapacityInBytes uint64 `json:"capacityInBytes"`
+	SizeInBytes     uint64 `json:"sizeInBytes"`
+	NumberOfTxs     uint64 `json:"numberOfTxs"`
+	Slot            uint64 `json:"slot"`
+}
+
+func (c *CardanoCLI) queryTxMempool(result interface{}, args ...string) error {
+	out, err := c.RunWithNetwork(append([]string{"query", "tx-mempool"}, args...)...)
+	if err != nil {
+		return fmt.Errorf("fail to query tx-mempool: %w", err)
+	}
+	if err := json.Unmarshal(out, result); err != nil {
+		return fmt.Errorf("fail to decode json: %w", err)
+	}
+	return nil
+}
+
+func (c *CardanoCLI) GetMempoolInfo() (*MempoolInfo, error) {
+	var info MempoolInfo
+	if err := c.queryTxMempool(&info, "info"); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// GetMempoolNextTx returns the hash of the next tx of the mempool, or an
+// empty string when the mempool is empty.
+func (c *CardanoCLI) GetMempoolNextTx() (string, error) {
+	var next struct {
+		NextTx *string `json:"nextTx"`
+	}
+	if err := c.queryTxMempool(&next, "next-tx"); err != nil {
+		return "", err
+	}
+	if next.NextTx == nil {
+		return "", nil
+	}
+	return *next.NextTx, nil
+}
+
+func (c *CardanoCLI) MempoolHasTx(txHash string) (bool, error) {
+	var exists struct {
+		Exists bool `json:"exists"`
+	}
+	if err := c.queryTxMempool(&exists, "tx-exists", txHash); err != nil {
+		return false, err
+	}
+	return exists.Exists, nil
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/minswap/pab-go/provider"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeCLI is a cardano-cli printing what query tx-mempool prints.
+const fakeCLI = `#!/bin/sh
+case "$3" in
+info) echo '{"capacityInBytes": 180224, "numberOfTxs": 2, "sizeInBytes": 1024, "slot": 74159190}' ;;
+next-tx) echo '{"nextTx": "5ca53b0eb10f317a5f1bf1bda679a04a8dd01c156643deee1d406ec2cc15e9e3", "slot": 74159190}' ;;
+tx-exists) if [ "$4" = "5ca53b0eb10f317a5f1bf1bda679a04a8dd01c156643deee1d406ec2cc15e9e3" ]; then exists=true; else exists=false; fi
+  echo "{\"exists\": $exists, \"slot\": 74159190, \"txId\": \"$4\"}" ;;
+esac
+case "$2" in
+utxo) echo '{}' > "$4" ;;
+esac
+`
+
+func TestMempool(t *testing.T) {
+	assert := assert.New(t)
+	path := filepath.Join(t.TempDir(), "cardano-cli")
+	if err := os.WriteFile(path, []byte(fakeCLI), 0755); err != nil {
+		t.Fatal(err)
+	}
+	c := &CardanoCLI{CLIPath: path, NetworkID: NetworkTestnetPreprod}
+
+	info, err := c.GetMempoolInfo()
+	if assert.NoError(err) {
+		assert.Equal(&MempoolInfo{CapacityInBytes: 180224, SizeInBytes: 1024, NumberOfTxs: 2, Slot: 74159190}, info)
+	}
+	next, err := c.GetMempoolNextTx()
+	assert.NoError(err)
+	assert.Equal("5ca53b0eb10f317a5f1bf1bda679a04a8dd01c156643deee1d406ec2cc15e9e3", next)
+
+	status, err := provider.GetTxStatus(c, "5ca53b0eb10f317a5f1bf1bda679a04a8dd01c156643deee1d406ec2cc15e9e3")
+	assert.NoError(err)
+	assert.Equal(provider.TxStatusInMempool, status)
+	status, err = provider.GetTxStatus(c, "76b0d16f5d09ac02dd1786981066f6fedf7ac165a08b3b6f0fb33f03")
+	assert.NoError(err)
+	assert.Equal(provider.TxStatusUnknown, status)
+}
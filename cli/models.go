@@ -1,26 +1,25 @@
 package cli
 
+import (
+	"github.com/minswap/pab-go/ledger"
+	"github.com/minswap/pab-go/provider"
+	"github.com/minswap/pab-go/txbuilder"
+)
+
 type CBORFile struct {
 	Type        string `json:"type"`
 	Description string `json:"description"`
 	CBORHex     string `json:"cborHex"`
 }
 
-type Tx struct {
-	TxHash string `json:"txHash"`
-	TxBody string `json:"txBody"`
-}
+type Tx = provider.Tx
 
-type TxIn struct {
-	TxID    string `json:"txID"`
-	TxIndex int    `json:"txIndex"`
+// TxBuildBackend builds transactions from a TxBuilder. CardanoCLI builds them
+// with cardano-cli, the offline package in Go.
+type TxBuildBackend interface {
+	BuildTx(txb txbuilder.TxBuilder) (*Tx, error)
 }
 
-type Tip struct {
-	Epoch        int    `json:"epoch"`
-	Hash         string `json:"hash"`
-	Slot         int    `json:"slot"`
-	Block        int    `json:"block"`
-	Era          string `json:"era"`
-	SyncProgress string `json:"syncProgress"`
-}
+type TxIn = ledger.OutRef
+
+type Tip = provider.Tip
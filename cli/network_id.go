@@ -1,5 +1,7 @@
 package cli
 
+import "github.com/minswap/pab-go/address"
+
 type NetworkID = uint32
 
 const (
@@ -9,3 +11,11 @@ const (
 	NetworkTestnetPreview  NetworkID = 2
 	NetworkTestnetPreprod  NetworkID = 1
 )
+
+// AddressNetwork returns the network tag used in addresses of the network with magic id.
+func AddressNetwork(id NetworkID) address.Network {
+	if id == NetworkMainnet {
+		return address.Mainnet
+	}
+	return address.Testnet
+}
@@ -1,89 +1,154 @@
 package cli
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"math/big"
 	"os"
-	"strconv"
 	"strings"
 
 	"github.com/minswap/pab-go/ledger"
 )
 
-type QueryUtxoOutFile = map[string]struct {
+type QueryUtxoTxOut struct {
 	Address string                     `json:"address"`
 	Value   map[string]json.RawMessage `json:"value"`
 	Data    *string                    `json:"datumhash"`
 }
 
+type QueryUtxoOutFile = map[string]QueryUtxoTxOut
+
 func parseTxIdTxIx(input string) (txId string, txIx int, err error) {
-	fields := strings.Split(input, "#")
-	if len(fields) != 2 {
-		return "", 0, fmt.Errorf("expect format txId#txIx, got %s", input)
-	}
-	txIx, err = strconv.Atoi(fields[1])
+	ref, err := ledger.ParseOutRef(input)
+	return ref.TxID, ref.TxIndex, err
+}
+
+func parseJSONValue(input map[string]json.RawMessage) (ledger.Value, error) {
+	return ledger.ValueFromNestedJSON(input)
+}
+
+// ParseCLIValue parses the cardano-cli value syntax printed by BuildValue, such
+// as "100 lovelace + 42 <policy>.<token name>". A bare amount is lovelace.
+func ParseCLIValue(s string) (ledger.Value, error) {
+	mint, err := ParseMintValue(s)
 	if err != nil {
-		return "", 0, fmt.Errorf("error when parse txIx: %s: %w", fields[1], err)
+		return nil, err
+	}
+	if burned := mint.Burned(); len(burned) > 0 {
+		return nil, fmt.Errorf("negative amount of %s", burned.Assets()[0])
 	}
-	return fields[0], txIx, nil
+	return mint.Minted(), nil
 }
 
-func parseValue(input map[string]json.RawMessage) (ledger.Value, error) {
-	val := ledger.NewValue()
-	for currencySymbol, raw := range input {
-		if currencySymbol == "lovelace" {
-			amount, ok := new(big.Int).SetString(string(raw), 10)
-			if !ok {
-				return nil, fmt.Errorf("fail to parse lovelace amount: %s", raw)
-			}
-			val[ledger.ADA] = amount
-			continue
+// ParseMintValue parses the signed --mint syntax printed by BuildMintValue.
+func ParseMintValue(s string) (ledger.MintValue, error) {
+	val := ledger.NewMintValue()
+	for _, part := range strings.Split(s, " + ") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 || len(fields) > 2 {
+			return nil, fmt.Errorf("expect <amount> <asset>, got %q", strings.TrimSpace(part))
 		}
-
-		var tokenNameMap map[string]*big.Int
-		if err := json.Unmarshal(raw, &tokenNameMap); err != nil {
-			return nil, fmt.Errorf("fail to decode map of token name to amount: %w", err)
+		amount, ok := new(big.Int).SetString(fields[0], 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid amount: %s", fields[0])
 		}
-		for tokenName, amount := range tokenNameMap {
-			val.Add(ledger.NewAsset(currencySymbol, tokenName), amount)
+		asset := ledger.ADA
+		if len(fields) == 2 {
+			var err error
+			if asset, err = ledger.AssetFromString(fields[1]); err != nil {
+				return nil, err
+			}
 		}
+		val.Add(asset, amount)
 	}
 	return val, nil
 }
 
-func parseQueryUtxoOutput(outputBytes []byte) ([]ledger.Utxo, error) {
-	var output QueryUtxoOutFile
-	if err := json.Unmarshal(outputBytes, &output); err != nil {
-		return nil, fmt.Errorf("fail to decode query utxo output: %w", err)
+func parseQueryUtxoTxOut(txIdTxIx string, txOut QueryUtxoTxOut) (ledger.Utxo, error) {
+	txId, txIx, err := parseTxIdTxIx(txIdTxIx)
+	if err != nil {
+		return ledger.Utxo{}, fmt.Errorf("fail to parse txIdTxIx: %w", err)
 	}
-	utxos := make([]ledger.Utxo, 0)
-	for txIdTxIx, txOut := range output {
-		txId, txIx, err := parseTxIdTxIx(txIdTxIx)
+	val, err := parseJSONValue(txOut.Value)
+	if err != nil {
+		return ledger.Utxo{}, fmt.Errorf("fail to parse txOut value: %w", err)
+	}
+	return ledger.Utxo{
+		TxID:      txId,
+		TxIndex:   txIx,
+		Address:   txOut.Address,
+		Value:     val,
+		DatumHash: txOut.Data,
+	}, nil
+}
+
+// DecodeQueryUtxo decodes the output of query utxo one UTxO at a time, so
+// that memory stays bounded on the whole UTxO set. It calls fn with every
+// UTxO for which keep, if not nil, returns true, and stops with the first
+// error fn returns.
+func DecodeQueryUtxo(r io.Reader, keep func(ledger.Utxo) bool, fn func(ledger.Utxo) error) error {
+	decoder := json.NewDecoder(bufio.NewReader(r))
+	if err := expectDelim(decoder, '{'); err != nil {
+		return err
+	}
+	for decoder.More() {
+		tok, err := decoder.Token()
 		if err != nil {
-			return nil, fmt.Errorf("fail to parse txIdTxIx: %w", err)
+			return fmt.Errorf("fail to decode query utxo output: %w", err)
 		}
-		val, err := parseValue(txOut.Value)
+		txIdTxIx, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("fail to decode query utxo output: unexpected %v", tok)
+		}
+		var txOut QueryUtxoTxOut
+		if err := decoder.Decode(&txOut); err != nil {
+			return fmt.Errorf("fail to decode query utxo output of %s: %w", txIdTxIx, err)
+		}
+		utxo, err := parseQueryUtxoTxOut(txIdTxIx, txOut)
 		if err != nil {
-			return nil, fmt.Errorf("fail to parse txOut value: %w", err)
+			return err
 		}
-		utxo := ledger.Utxo{
-			TxID:      txId,
-			TxIndex:   txIx,
-			Address:   txOut.Address,
-			Value:     val,
-			DatumHash: txOut.Data,
+		if keep != nil && !keep(utxo) {
+			continue
+		}
+		if err := fn(utxo); err != nil {
+			return err
 		}
-		utxos = append(utxos, utxo)
 	}
-	return utxos, nil
+	return expectDelim(decoder, '}')
 }
 
-func parseQueryUtxoOutFile(file *os.File) ([]ledger.Utxo, error) {
-	outputBytes, err := io.ReadAll(file)
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	tok, err := decoder.Token()
 	if err != nil {
-		return nil, fmt.Errorf("fail to read query utxo out file: %w", err)
+		return fmt.Errorf("fail to decode query utxo output: %w", err)
+	}
+	if tok != delim {
+		return fmt.Errorf("fail to decode query utxo output: expect %v, got %v", delim, tok)
 	}
-	return parseQueryUtxoOutput(outputBytes)
+	return nil
+}
+
+// collectQueryUtxo decodes the output of query utxo into a slice.
+func collectQueryUtxo(r io.Reader, keep func(ledger.Utxo) bool) ([]ledger.Utxo, error) {
+	utxos := make([]ledger.Utxo, 0)
+	err := DecodeQueryUtxo(r, keep, func(u ledger.Utxo) error {
+		utxos = append(utxos, u)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return utxos, nil
+}
+
+func parseQueryUtxoOutput(outputBytes []byte) ([]ledger.Utxo, error) {
+	return collectQueryUtxo(bytes.NewReader(outputBytes), nil)
+}
+
+func parseQueryUtxoOutFile(file *os.File) ([]ledger.Utxo, error) {
+	return collectQueryUtxo(file, nil)
 }
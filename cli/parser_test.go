@@ -1,8 +1,14 @@
 package cli
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
 	"testing"
 
+	"github.com/minswap/pab-go/ledger"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -29,3 +35,66 @@ func TestParseQueryUtxoOutput(t *testing.T) {
 	_, err := parseQueryUtxoOutput([]byte(testcase))
 	assert.NoError(t, err)
 }
+
+func TestDecodeQueryUtxo(t *testing.T) {
+	assert := assert.New(t)
+	const n = 1000
+	// write the output through a pipe, as a file too large to read at once
+	r, w := io.Pipe()
+	go func() {
+		fmt.Fprint(w, "{")
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintf(w, `"52db772a86ccc918a71ed3a6881692010f05f29b2839e7dc4c5ca95c129261fa#%d": {
+				"address": "addr_test1wr37myp6qxqjd5g2de002z27zecggjfwqgdwn0wav8m4y3ggavlh3",
+				"value": {"lovelace": %d}
+			}`, i, 1_000_000+i)
+		}
+		fmt.Fprint(w, "}")
+		w.Close()
+	}()
+	var utxos []ledger.Utxo
+	err := DecodeQueryUtxo(r, func(u ledger.Utxo) bool { return u.TxIndex%100 == 0 }, func(u ledger.Utxo) error {
+		utxos = append(utxos, u)
+		return nil
+	})
+	if assert.NoError(err) && assert.Len(utxos, n/100) {
+		assert.Equal(200, utxos[2].TxIndex)
+		assert.Equal(int64(1_000_200), utxos[2].Value[ledger.ADA].Int64())
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = DecodeQueryUtxo(strings.NewReader(`{
+		"52db772a86ccc918a71ed3a6881692010f05f29b2839e7dc4c5ca95c129261fa#0": {"address": "a", "value": {"lovelace": 1}},
+		"52db772a86ccc918a71ed3a6881692010f05f29b2839e7dc4c5ca95c129261fa#1": {"address": "a", "value": {"lovelace": 1}}
+	}`), nil, func(ledger.Utxo) error {
+		calls++
+		return stop
+	})
+	assert.Equal(stop, err)
+	assert.Equal(1, calls)
+
+	for _, input := range []string{`[]`, `{"52db": {}}`, `{"52db772a86ccc918a71ed3a6881692010f05f29b2839e7dc4c5ca95c129261fa#0": {"value": 1}}`, `{`} {
+		assert.Error(DecodeQueryUtxo(strings.NewReader(input), nil, func(ledger.Utxo) error { return nil }), input)
+	}
+}
+
+func TestParseCLIValue(t *testing.T) {
+	s := "5 lovelace + 2 1d7f33bd23d85e1a25d87d86fac4f199c3197a2f7afeb662a0f34e1e + 3 3f6092645942a54a75186b25e0975b79e1f50895ad958b42015eb6d2.4d494e53574150"
+	val, err := ParseCLIValue(s)
+	assert.NoError(t, err)
+	assert.Equal(t, s, BuildValue(val))
+
+	val, err = ParseCLIValue("7 + 1 3f6092645942a54a75186b25e0975b79e1f50895ad958b42015eb6d2")
+	assert.NoError(t, err)
+	assert.Equal(t, "7 lovelace + 1 3f6092645942a54a75186b25e0975b79e1f50895ad958b42015eb6d2", BuildValue(val))
+
+	_, err = ParseCLIValue("-1 3f6092645942a54a75186b25e0975b79e1f50895ad958b42015eb6d2")
+	assert.Error(t, err)
+	mint, err := ParseMintValue("-1 3f6092645942a54a75186b25e0975b79e1f50895ad958b42015eb6d2 + 2 1d7f33bd23d85e1a25d87d86fac4f199c3197a2f7afeb662a0f34e1e")
+	assert.NoError(t, err)
+	assert.Equal(t, "2 1d7f33bd23d85e1a25d87d86fac4f199c3197a2f7afeb662a0f34e1e + -1 3f6092645942a54a75186b25e0975b79e1f50895ad958b42015eb6d2", BuildMintValue(mint))
+}
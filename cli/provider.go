@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/minswap/pab-go/ledger"
+	"github.com/minswap/pab-go/provider"
+)
+
+var (
+	_ provider.ChainProvider    = (*CardanoCLI)(nil)
+	_ provider.WholeUtxoQuerier = (*CardanoCLI)(nil)
+	_ provider.PatternQuerier   = (*CardanoCLI)(nil)
+)
+
+// AwaitTxInterval is how often AwaitTx queries the node.
+var AwaitTxInterval = 2 * time.Second
+
+// GetProtocolParams queries the current protocol params, refreshing the file
+// at ProtocolParamsPath that the transaction commands read.
+func (c *CardanoCLI) GetProtocolParams() (*ledger.ProtocolParams, error) {
+	if err := c.initProtocolParamsFile(); err != nil {
+		return nil, err
+	}
+	return ledger.ReadProtocolParamsFile(c.ProtocolParamsPath)
+}
+
+func (c *CardanoCLI) txFileType(witnessed bool) string {
+	if witnessed {
+		return fmt.Sprintf("Witnessed Tx %sEra", c.Era)
+	}
+	return fmt.Sprintf("Unwitnessed Tx %sEra", c.Era)
+}
+
+// submitTxFile submits a signed tx file, classifying node rejections.
+func (c *CardanoCLI) submitTxFile(path string) error {
+	if _, err := c.RunWithNetwork("transaction", "submit", "--tx-file", path); err != nil {
+		var cliErr *CLIError
+		if errors.As(err, &cliErr) {
+			return &provider.SubmitError{
+				Kind:    provider.ClassifyLedgerError(cliErr.Error()),
+				Message: cliErr.Error(),
+				Err:     err,
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// SubmitTx submits a signed tx, such as the one of BuildAndSignTx.
+func (c *CardanoCLI) SubmitTx(tx *Tx) error {
+	tempManager, err := NewTempManager()
+	if err != nil {
+		return fmt.Errorf("fail to create TempManager: %w", err)
+	}
+	defer tempManager.Clean()
+
+	txFile := tempManager.NewFile("signed-tx")
+	content, err := json.Marshal(CBORFile{
+		Type:        c.txFileType(true),
+		Description: "",
+		CBORHex:     tx.TxBody,
+	})
+	if err != nil {
+		return fmt.Errorf("fail to encode tx file: %w", err)
+	}
+	if _, err := txFile.Write(content); err != nil {
+		return fmt.Errorf("fail to write tx file: %w", err)
+	}
+	if err := c.submitTxFile(txFile.Name()); err != nil {
+		return fmt.Errorf("fail to submit tx: %w", err)
+	}
+	return nil
+}
+
+// AwaitTx waits for the first output of the tx to appear in the UTxO set, so
+// it does not return if that output is spent before it is seen.
+func (c *CardanoCLI) AwaitTx(ctx context.Context, txHash string) error {
+	return provider.PollTx(ctx, AwaitTxInterval, func() (bool, error) {
+		utxos, err := c.GetUtxosByTxIns(TxIn{TxID: txHash, TxIndex: 0})
+		if err != nil {
+			return false, err
+		}
+		return len(utxos) > 0, nil
+	})
+}
+
+// GetUtxosByPattern filters the whole UTxO set while decoding it.
+func (c *CardanoCLI) GetUtxosByPattern(pattern provider.Pattern) ([]ledger.Utxo, error) {
+	return c.FilterAllUtxos(pattern.Match)
+}
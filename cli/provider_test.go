@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeParamsCLI is a cardano-cli printing the current protocol params.
+const fakeParamsCLI = `#!/bin/sh
+case "$1 $2" in
+"query protocol-parameters") echo '{"utxoCostPerByte": 4310}' ;;
+*) exit 1 ;;
+esac
+`
+
+func TestGetProtocolParams(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	cliPath := filepath.Join(dir, "cardano-cli")
+	assert.NoError(os.WriteFile(cliPath, []byte(fakeParamsCLI), 0755))
+	paramsPath := filepath.Join(dir, "protocol-params.json")
+	assert.NoError(os.WriteFile(paramsPath, []byte(`{"utxoCostPerByte": 1}`), 0644))
+	c := &CardanoCLI{CLIPath: cliPath, NetworkID: NetworkTestnetPreprod, ProtocolParamsPath: paramsPath}
+
+	// the stale file is refreshed
+	params, err := c.GetProtocolParams()
+	if assert.NoError(err) {
+		assert.Equal(int64(4310), params.UtxoCostPerByte)
+	}
+	content, _ := os.ReadFile(paramsPath)
+	assert.Contains(string(content), "4310")
+}
@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/minswap/pab-go/address"
+	"github.com/minswap/pab-go/ledger"
+)
+
+// StakeReference is the stake part of an address built by GetScriptAddressWithStake.
+type StakeReference interface {
+	isStakeReference()
+}
+
+type StakeVkeyFile struct {
+	Path string
+}
+
+func (StakeVkeyFile) isStakeReference() {}
+
+type StakeKeyHash struct {
+	Hash string
+}
+
+func (StakeKeyHash) isStakeReference() {}
+
+type StakeScriptFile struct {
+	Path string
+}
+
+func (StakeScriptFile) isStakeReference() {}
+
+type StakeScriptHash struct {
+	Hash string
+}
+
+func (StakeScriptHash) isStakeReference() {}
+
+type StakePointer struct {
+	address.Pointer
+}
+
+func (StakePointer) isStakeReference() {}
+
+// errNotNative tells that a file is valid for cardano-cli but not decoded by
+// pab-go, like a bech32 key file.
+var errNotNative = errors.New("cannot be decoded natively")
+
+// GetScriptHash returns the hash of a script file. It is computed natively and
+// falls back to cardano-cli for script files pab-go cannot decode.
+func (c *CardanoCLI) GetScriptHash(scriptPath string) (string, error) {
+	if script, err := ledger.ReadScriptFile(scriptPath); err == nil {
+		return script.Hash(), nil
+	}
+	return c.GetPolicyID(scriptPath)
+}
+
+func readVkeyHash(vkeyPath string) (string, error) {
+	content, err := os.ReadFile(vkeyPath)
+	if err != nil {
+		return "", fmt.Errorf("fail to read verification key file: %w", err)
+	}
+	var f CBORFile
+	if err := json.Unmarshal(content, &f); err != nil {
+		return "", fmt.Errorf("%w: fail to decode verification key file: %v", errNotNative, err)
+	}
+	b, err := hex.DecodeString(f.CBORHex)
+	// the key is a CBOR byte string of 32 bytes, or 64 bytes for extended keys
+	// whose last 32 bytes are the chain code
+	if err != nil || len(b) < 2 || b[0] != 0x58 || (b[1] != 32 && b[1] != 64) || len(b) != 2+int(b[1]) {
+		return "", fmt.Errorf("%w: invalid verification key cborHex: %s", errNotNative, f.CBORHex)
+	}
+	return ledger.KeyHash(b[2:34]), nil
+}
+
+func (c *CardanoCLI) stakeCredential(stake StakeReference) (address.Credential, error) {
+	switch s := stake.(type) {
+	case StakeVkeyFile:
+		hash, err := readVkeyHash(s.Path)
+		if err != nil {
+			return address.Credential{}, err
+		}
+		return address.NewKeyCredential(hash), nil
+	case StakeKeyHash:
+		return address.NewKeyCredential(s.Hash), nil
+	case StakeScriptFile:
+		hash, err := c.GetScriptHash(s.Path)
+		if err != nil {
+			return address.Credential{}, fmt.Errorf("fail to get stake script hash: %w", err)
+		}
+		return address.NewScriptCredential(hash), nil
+	case StakeScriptHash:
+		return address.NewScriptCredential(s.Hash), nil
+	default:
+		return address.Credential{}, fmt.Errorf("stake reference %T has no stake credential", stake)
+	}
+}
+
+// GetScriptAddressWithStake returns the address of a payment script whose stake
+// part is stake. Pass nil to get the enterprise address.
+func (c *CardanoCLI) GetScriptAddressWithStake(scriptPath string, stake StakeReference) (string, error) {
+	scriptHash, err := c.GetScriptHash(scriptPath)
+	if err != nil {
+		return "", fmt.Errorf("fail to get payment script hash: %w", err)
+	}
+	payment := address.NewScriptCredential(scriptHash)
+	network := AddressNetwork(c.NetworkID)
+
+	var addr address.Address
+	switch s := stake.(type) {
+	case nil:
+		addr, err = address.NewEnterpriseAddress(network, payment)
+	case StakePointer:
+		addr, err = address.NewPointerAddress(network, payment, s.Pointer)
+	default:
+		cred, credErr := c.stakeCredential(stake)
+		if credErr != nil {
+			return "", credErr
+		}
+		addr, err = address.NewBaseAddress(network, payment, cred)
+	}
+	if err != nil {
+		return "", fmt.Errorf("fail to build script address: %w", err)
+	}
+	return addr.String(), nil
+}
+
+// GetRewardAddress returns the reward address of a stake key or stake script.
+func (c *CardanoCLI) GetRewardAddress(stake StakeReference) (string, error) {
+	cred, err := c.stakeCredential(stake)
+	if err != nil {
+		return "", err
+	}
+	addr, err := address.NewRewardAddress(AddressNetwork(c.NetworkID), cred)
+	if err != nil {
+		return "", fmt.Errorf("fail to build reward address: %w", err)
+	}
+	return addr.String(), nil
+}
+
+// GetScriptRewardAddressByCLI returns the reward address of a stake script
+// using cardano-cli stake-address build.
+func (c *CardanoCLI) GetScriptRewardAddressByCLI(stakeScriptPath string) (string, error) {
+	out, err := c.RunWithNetwork("stake-address", "build", "--stake-script-file", stakeScriptPath)
+	if err != nil {
+		return "", fmt.Errorf("fail to get script reward address: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/minswap/pab-go/address"
+	"github.com/stretchr/testify/assert"
+)
+
+const alwaysSucceedsScript = `{"type": "PlutusScriptV1", "description": "", "cborHex": "4e4d01000033222220051200120011"}`
+
+func TestGetScriptAddressWithStake(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	scriptPath := filepath.Join(dir, "always-succeeds.plutus")
+	assert.NoError(os.WriteFile(scriptPath, []byte(alwaysSucceedsScript), 0644))
+	const scriptHash = "67f33146617a5e61936081db3b2117cbf59bd2123748f58ac9678656"
+	const stakeKeyHash = "337b62cfff6403a06a3acbc34f8c46003c69fe79a3628cefa9c47251"
+
+	c := &CardanoCLI{NetworkID: NetworkTestnetPreprod}
+	addr, err := c.GetScriptAddressWithStake(scriptPath, nil)
+	if assert.NoError(err) {
+		assert.Equal("addr_test1wpnlxv2xv9a9ucvnvzqakwepzl9ltx7jzgm53av2e9ncv4sysemm8", addr)
+	}
+
+	addr, err = c.GetScriptAddressWithStake(scriptPath, StakeScriptFile{scriptPath})
+	if assert.NoError(err) {
+		parsed, err := address.Parse(addr)
+		if assert.NoError(err) {
+			assert.Equal(address.KindBase, parsed.Kind)
+			assert.Equal(address.NewScriptCredential(scriptHash), *parsed.Payment)
+			assert.Equal(address.NewScriptCredential(scriptHash), *parsed.Stake)
+		}
+	}
+
+	addr, err = c.GetScriptAddressWithStake(scriptPath, StakeKeyHash{stakeKeyHash})
+	if assert.NoError(err) {
+		parsed, err := address.Parse(addr)
+		if assert.NoError(err) {
+			assert.Equal(address.NewKeyCredential(stakeKeyHash), *parsed.Stake)
+		}
+	}
+
+	addr, err = c.GetScriptAddressWithStake(scriptPath, StakePointer{address.Pointer{Slot: 1, TxIndex: 2, CertIndex: 3}})
+	if assert.NoError(err) {
+		parsed, err := address.Parse(addr)
+		if assert.NoError(err) {
+			assert.Equal(address.Pointer{Slot: 1, TxIndex: 2, CertIndex: 3}, *parsed.Pointer)
+		}
+	}
+
+	rewardAddr, err := c.GetRewardAddress(StakeScriptFile{scriptPath})
+	if assert.NoError(err) {
+		parsed, err := address.Parse(rewardAddr)
+		if assert.NoError(err) {
+			assert.Equal(address.KindReward, parsed.Kind)
+			assert.Equal("stake_test", parsed.HRP())
+			assert.Equal(address.NewScriptCredential(scriptHash), *parsed.Stake)
+		}
+	}
+	_, err = c.GetRewardAddress(StakePointer{})
+	assert.Error(err)
+}
+
+// fakeAddressCLI is a cardano-cli printing the address built from its
+// arguments, which it logs to $0.log.
+const fakeAddressCLI = `#!/bin/sh
+echo "$@" > "$0.log"
+case "$1 $2" in
+"address build") echo 'addr_test1zpnlxv2xv9a9ucvnvzqakwepzl9ltx7jzgm53av2e9ncv43n0d3vllmyqwsx5wktcd8cc3sq835lu7drv2xwl2wywfgs5gl3h4' ;;
+"stake-address build") echo 'stake_test17pnlxv2xv9a9ucvnvzqakwepzl9ltx7jzgm53av2e9ncv4syc8rvd' ;;
+*) exit 1 ;;
+esac
+`
+
+func TestStakeAddressByCLI(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	cliPath := filepath.Join(dir, "cardano-cli")
+	assert.NoError(os.WriteFile(cliPath, []byte(fakeAddressCLI), 0755))
+	scriptPath := filepath.Join(dir, "always-succeeds.plutus")
+	assert.NoError(os.WriteFile(scriptPath, []byte(alwaysSucceedsScript), 0644))
+	vkeyPath := filepath.Join(dir, "stake.vkey")
+	assert.NoError(os.WriteFile(vkeyPath, []byte(`{"type": "StakeVerificationKeyShelley_ed25519", "cborHex": "not a key"}`), 0644))
+	c := &CardanoCLI{CLIPath: cliPath, NetworkID: NetworkTestnetPreprod}
+
+	rewardAddr, err := c.GetScriptRewardAddressByCLI(scriptPath)
+	assert.NoError(err)
+	assert.Equal("stake_test17pnlxv2xv9a9ucvnvzqakwepzl9ltx7jzgm53av2e9ncv4syc8rvd", rewardAddr)
+	log, _ := os.ReadFile(cliPath + ".log")
+	assert.Contains(string(log), "stake-address build --stake-script-file "+scriptPath)
+
+	// the key file cannot be decoded natively
+	addr, err := c.GetStakingScriptAddress(scriptPath, vkeyPath)
+	assert.NoError(err)
+	assert.Equal("addr_test1zpnlxv2xv9a9ucvnvzqakwepzl9ltx7jzgm53av2e9ncv43n0d3vllmyqwsx5wktcd8cc3sq835lu7drv2xwl2wywfgs5gl3h4", addr)
+	log, _ = os.ReadFile(cliPath + ".log")
+	assert.Contains(string(log), "--payment-script-file "+scriptPath+" --stake-verification-key-file "+vkeyPath)
+
+	// other errors are not hidden by cardano-cli
+	assert.NoError(os.Remove(cliPath + ".log"))
+	_, err = c.GetStakingScriptAddress(scriptPath, filepath.Join(dir, "missing.vkey"))
+	assert.ErrorIs(err, os.ErrNotExist)
+	_, err = os.Stat(cliPath + ".log")
+	assert.True(os.IsNotExist(err), "cardano-cli is not run")
+}
@@ -0,0 +1,145 @@
+// Package coinselection chooses wallet UTxOs to fund a transaction.
+package coinselection
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"sort"
+
+	"github.com/minswap/pab-go/ledger"
+)
+
+type Algorithm int
+
+const (
+	LargestFirst Algorithm = iota
+	// RandomImprove is the CIP-2 random-improve algorithm.
+	RandomImprove
+)
+
+var (
+	ErrInsufficientFunds = errors.New("coinselection: insufficient funds")
+	ErrMaxInputsExceeded = errors.New("coinselection: maximum number of inputs exceeded")
+)
+
+type Params struct {
+	// Outputs are the values paid by the transaction, excluding change.
+	Outputs []ledger.Value
+	// Fee is the expected transaction fee in lovelace.
+	Fee *big.Int
+	// ChangeMinADA is the minimum lovelace of the change output. Change is
+	// either empty or carries at least this much ADA.
+	ChangeMinADA *big.Int
+	// MaxInputs limits the number of selected inputs, 0 means no limit.
+	MaxInputs int
+	// IsReserved excludes UTxOs, e.g. ones used by concurrent builds.
+	IsReserved func(u ledger.Utxo) bool
+	// Rand is the source of randomness of RandomImprove. Defaults to a source
+	// seeded with the current time.
+	Rand *rand.Rand
+}
+
+type Result struct {
+	Inputs []ledger.Utxo
+	// Change is the selected value minus outputs and fee.
+	Change ledger.Value
+}
+
+// Select runs algo over utxos.
+func Select(algo Algorithm, utxos []ledger.Utxo, params Params) (Result, error) {
+	switch algo {
+	case LargestFirst:
+		return SelectLargestFirst(utxos, params)
+	case RandomImprove:
+		return SelectRandomImprove(utxos, params)
+	default:
+		return Result{}, fmt.Errorf("coinselection: unknown algorithm %d", algo)
+	}
+}
+
+// target returns the sum of outputs plus fee.
+func (p Params) target() ledger.Value {
+	target := ledger.NewValue()
+	for _, out := range p.Outputs {
+		target.AddAll(out)
+	}
+	if p.Fee != nil {
+		target.Add(ledger.ADA, p.Fee)
+	}
+	return target
+}
+
+func (p Params) available(utxos []ledger.Utxo) []ledger.Utxo {
+	var ret []ledger.Utxo
+	for _, u := range utxos {
+		if p.IsReserved != nil && p.IsReserved(u) {
+			continue
+		}
+		ret = append(ret, u)
+	}
+	// sort so that results do not depend on the order utxos were queried in
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].TxID != ret[j].TxID {
+			return ret[i].TxID < ret[j].TxID
+		}
+		return ret[i].TxIndex < ret[j].TxIndex
+	})
+	return ret
+}
+
+func amountOf(v ledger.Value, asset ledger.Asset) *big.Int {
+	if amount, ok := v[asset]; ok {
+		return amount
+	}
+	return big.NewInt(0)
+}
+
+// targetAssets returns the assets of target, native tokens first and ADA last.
+func targetAssets(target ledger.Value) []ledger.Asset {
+	assets := target.Assets()
+	if len(assets) > 0 && assets[0] == ledger.ADA {
+		assets = append(assets[1:], ledger.ADA)
+	}
+	return assets
+}
+
+// deficit returns an asset that selected does not fully cover, preferring
+// native tokens over ADA so that ADA brought along with tokens is counted.
+func deficit(selected, target ledger.Value) (ledger.Asset, *big.Int, bool) {
+	for _, asset := range targetAssets(target) {
+		missing := new(big.Int).Sub(target[asset], amountOf(selected, asset))
+		if missing.Sign() > 0 {
+			return asset, missing, true
+		}
+	}
+	return ledger.Asset{}, nil, false
+}
+
+// change returns selected minus target, or false if the change would not meet
+// the minimum ADA requirement.
+func (p Params) change(selected, target ledger.Value) (ledger.Value, bool) {
+	change := selected.Clone()
+	for asset, amount := range target {
+		change.Add(asset, new(big.Int).Neg(amount))
+	}
+	change.Trim()
+	if len(change) == 0 || p.ChangeMinADA == nil {
+		return change, true
+	}
+	return change, amountOf(change, ledger.ADA).Cmp(p.ChangeMinADA) >= 0
+}
+
+func (p Params) checkInputCount(n int) error {
+	if p.MaxInputs > 0 && n > p.MaxInputs {
+		return fmt.Errorf("%w: need more than %d inputs", ErrMaxInputsExceeded, p.MaxInputs)
+	}
+	return nil
+}
+
+func (p Params) changeDeficit(selected, target ledger.Value) *big.Int {
+	change, _ := p.change(selected, target)
+	missing := new(big.Int).Sub(p.ChangeMinADA, amountOf(change, ledger.ADA))
+	return missing
+}
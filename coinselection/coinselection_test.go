@@ -0,0 +1,166 @@
+package coinselection
+
+import (
+	"errors"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/minswap/pab-go/ledger"
+	"github.com/stretchr/testify/assert"
+)
+
+var testToken = ledger.NewAsset("29d222ce763455e3d7a09a665ce554f00ac89d2e99a1a83d267170c6", "4d494e")
+
+func adaUtxo(txIndex int, lovelace int64) ledger.Utxo {
+	return ledger.Utxo{
+		TxID:    "5ca53b0eb10f317a5f1bf1bda679a04a8dd01c156643deee1d406ec2cc15e9e3",
+		TxIndex: txIndex,
+		Value:   ledger.NewValue().Add(ledger.ADA, big.NewInt(lovelace)),
+	}
+}
+
+func testUtxos() []ledger.Utxo {
+	tokenUtxo := adaUtxo(4, 2_000_000)
+	tokenUtxo.Value.Add(testToken, big.NewInt(500))
+	return []ledger.Utxo{
+		adaUtxo(0, 10_000_000),
+		adaUtxo(1, 50_000_000),
+		adaUtxo(2, 3_000_000),
+		adaUtxo(3, 20_000_000),
+		tokenUtxo,
+	}
+}
+
+func TestSelectLargestFirst(t *testing.T) {
+	assert := assert.New(t)
+	params := Params{
+		Outputs: []ledger.Value{
+			ledger.NewValue().Add(ledger.ADA, big.NewInt(55_000_000)),
+		},
+		Fee:          big.NewInt(200_000),
+		ChangeMinADA: big.NewInt(1_000_000),
+	}
+	res, err := SelectLargestFirst(testUtxos(), params)
+	if assert.NoError(err) {
+		assert.Len(res.Inputs, 2)
+		assert.Equal(1, res.Inputs[0].TxIndex)
+		assert.Equal(3, res.Inputs[1].TxIndex)
+		assert.Equal(int64(14_800_000), res.Change[ledger.ADA].Int64())
+	}
+
+	params.Outputs = append(params.Outputs, ledger.NewValue().Add(testToken, big.NewInt(100)))
+	res, err = SelectLargestFirst(testUtxos(), params)
+	if assert.NoError(err) {
+		assert.Len(res.Inputs, 3)
+		assert.Equal(4, res.Inputs[0].TxIndex)
+		assert.Equal(int64(400), res.Change[testToken].Int64())
+	}
+
+	params.MaxInputs = 2
+	_, err = SelectLargestFirst(testUtxos(), params)
+	assert.True(errors.Is(err, ErrMaxInputsExceeded))
+
+	params.MaxInputs = 0
+	params.IsReserved = func(u ledger.Utxo) bool { return u.TxIndex == 4 }
+	_, err = SelectLargestFirst(testUtxos(), params)
+	assert.True(errors.Is(err, ErrInsufficientFunds))
+}
+
+func TestSelectLargestFirstChangeMinADA(t *testing.T) {
+	// 10 ADA covers the output and fee, but the 0.3 ADA change is below the
+	// minimum, so another input must be added.
+	res, err := SelectLargestFirst(
+		[]ledger.Utxo{adaUtxo(0, 10_000_000), adaUtxo(1, 3_000_000)},
+		Params{
+			Outputs:      []ledger.Value{ledger.NewValue().Add(ledger.ADA, big.NewInt(9_500_000))},
+			Fee:          big.NewInt(200_000),
+			ChangeMinADA: big.NewInt(1_000_000),
+		},
+	)
+	if assert.NoError(t, err) {
+		assert.Len(t, res.Inputs, 2)
+		assert.Equal(t, int64(3_300_000), res.Change[ledger.ADA].Int64())
+	}
+}
+
+func TestSelectRandomImprove(t *testing.T) {
+	assert := assert.New(t)
+	params := Params{
+		Outputs: []ledger.Value{
+			ledger.NewValue().Add(ledger.ADA, big.NewInt(5_000_000)).Add(testToken, big.NewInt(100)),
+		},
+		Fee:          big.NewInt(200_000),
+		ChangeMinADA: big.NewInt(1_000_000),
+		Rand:         rand.New(rand.NewSource(42)),
+	}
+	for i := 0; i < 20; i++ {
+		res, err := SelectRandomImprove(testUtxos(), params)
+		if !assert.NoError(err) {
+			return
+		}
+		total := ledger.SumValueOfUtxos(res.Inputs)
+		assert.True(total.Contains(testToken))
+		assert.True(total[ledger.ADA].Cmp(big.NewInt(6_200_000)) >= 0)
+		assert.True(res.Change[ledger.ADA].Cmp(big.NewInt(1_000_000)) >= 0)
+	}
+
+	params.Outputs = []ledger.Value{ledger.NewValue().Add(ledger.ADA, big.NewInt(100_000_000))}
+	_, err := SelectRandomImprove(testUtxos(), params)
+	assert.True(errors.Is(err, ErrInsufficientFunds))
+}
+
+func TestSelectCollateral(t *testing.T) {
+	assert := assert.New(t)
+	const keyAddr = "addr_test1qpmtp5t0t5y6cqkaz7rfsyrx7mld77kpvksgkwm0p7en7qum7a589n30e80tclzrrnj8qr4qvzj6al0vpgtnmrkkksnqd8upj0"
+	utxos := testUtxos()
+	for i := range utxos {
+		utxos[i].Address = keyAddr
+	}
+	scriptUtxo := adaUtxo(5, 4_000_000)
+	scriptUtxo.Address = "addr_test1wpnlxv2xv9a9ucvnvzqakwepzl9ltx7jzgm53av2e9ncv4sysemm8"
+	utxos = append(utxos, scriptUtxo)
+	params := CollateralParams{
+		Fee:            big.NewInt(2_000_000),
+		ProtocolParams: ledger.ProtocolParams{UtxoCostPerByte: 4310, CollateralPercentage: 150, MaxCollateralInputs: 3},
+	}
+
+	// the smallest ADA-only key-locked UTxO covering 3 ADA
+	res, err := SelectCollateral(utxos, params)
+	if assert.NoError(err) {
+		assert.Equal([]ledger.Utxo{utxos[2]}, res.Inputs)
+		assert.Nil(res.Return)
+		assert.Equal(big.NewInt(3_000_000), res.TotalCollateral)
+	}
+
+	// two inputs when no single one covers
+	params.Fee = big.NewInt(40_000_000)
+	res, err = SelectCollateral(utxos, params)
+	if assert.NoError(err) {
+		assert.Equal([]ledger.Utxo{utxos[0], utxos[1]}, res.Inputs)
+	}
+
+	params.Fee = big.NewInt(100_000_000)
+	_, err = SelectCollateral(utxos, params)
+	var collateralErr *CollateralError
+	if assert.True(errors.As(err, &collateralErr)) {
+		assert.Equal(CollateralBelowRequired, collateralErr.Reason)
+		assert.Equal(big.NewInt(150_000_000), collateralErr.Required)
+	}
+	assert.ErrorIs(err, ledger.ErrNoCollateral)
+
+	// with a return address the token UTxO and the excess come back
+	params.Fee = big.NewInt(100_000)
+	params.ReturnAddress = keyAddr
+	res, err = SelectCollateral(utxos[4:5], params)
+	if assert.NoError(err) {
+		assert.Equal(big.NewInt(150_000), res.TotalCollateral)
+		assert.Equal(big.NewInt(1_850_000), res.Return[ledger.ADA])
+		assert.Equal(big.NewInt(500), res.Return[testToken])
+	}
+
+	_, err = SelectCollateral([]ledger.Utxo{scriptUtxo}, params)
+	if assert.True(errors.As(err, &collateralErr)) {
+		assert.Equal(NoCollateralCandidate, collateralErr.Reason)
+	}
+}
@@ -0,0 +1,167 @@
+package coinselection
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/minswap/pab-go/address"
+	"github.com/minswap/pab-go/ledger"
+)
+
+type CollateralParams struct {
+	// Fee is the estimated fee of the transaction, collateral must cover
+	// collateralPercentage of it.
+	Fee            *big.Int
+	ProtocolParams ledger.ProtocolParams
+	// ReturnAddress enables multi-asset collateral and a collateral return
+	// output holding everything above the required collateral.
+	ReturnAddress string
+	IsReserved    func(u ledger.Utxo) bool
+}
+
+type CollateralResult struct {
+	Inputs []ledger.Utxo
+	// Return is the collateral return value, nil without return output.
+	Return ledger.Value
+	// TotalCollateral is the lovelace forfeited if scripts fail.
+	TotalCollateral *big.Int
+}
+
+type CollateralErrorReason int
+
+const (
+	// NoCollateralCandidate means no UTxO is key-locked and, without a
+	// return address, ADA-only.
+	NoCollateralCandidate CollateralErrorReason = iota
+	// CollateralBelowRequired means candidates, up to maxCollateralInputs of
+	// them, do not cover the required collateral and the return output.
+	CollateralBelowRequired
+)
+
+// CollateralError explains why no collateral could be selected. It matches
+// ledger.ErrNoCollateral with errors.Is.
+type CollateralError struct {
+	Reason     CollateralErrorReason
+	Required   *big.Int
+	Candidates int
+	MaxInputs  int
+}
+
+func (e *CollateralError) Error() string {
+	switch e.Reason {
+	case NoCollateralCandidate:
+		return "no suitable collateral: no key-locked UTxO can be used as collateral"
+	default:
+		return fmt.Sprintf("no suitable collateral: %d candidates with at most %d inputs do not cover %s lovelace", e.Candidates, e.MaxInputs, e.Required)
+	}
+}
+
+func (e *CollateralError) Is(target error) bool {
+	return target == ledger.ErrNoCollateral
+}
+
+// RequiredCollateral returns ceil(fee * collateralPercentage / 100).
+func RequiredCollateral(fee *big.Int, params ledger.ProtocolParams) *big.Int {
+	required := new(big.Int).Mul(fee, big.NewInt(params.CollateralPercentage))
+	q, m := new(big.Int).QuoRem(required, big.NewInt(100), new(big.Int))
+	if m.Sign() > 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	return q
+}
+
+// SelectCollateral picks the fewest UTxOs, at most maxCollateralInputs, then
+// the smallest ones covering the required collateral, so big UTxOs are not
+// locked as collateral of every script transaction.
+func SelectCollateral(utxos []ledger.Utxo, params CollateralParams) (CollateralResult, error) {
+	required := RequiredCollateral(params.Fee, params.ProtocolParams)
+	maxInputs := params.ProtocolParams.MaxCollateralInputs
+	if maxInputs <= 0 {
+		maxInputs = 3
+	}
+	var candidates []ledger.Utxo
+	for _, u := range utxos {
+		if params.IsReserved != nil && params.IsReserved(u) {
+			continue
+		}
+		if !u.Value.HasOnlyADA() && params.ReturnAddress == "" {
+			continue
+		}
+		addr, err := address.Parse(u.Address)
+		if err != nil || addr.Payment == nil || addr.Payment.IsScript() {
+			continue
+		}
+		candidates = append(candidates, u)
+	}
+	if len(candidates) == 0 {
+		return CollateralResult{}, &CollateralError{Reason: NoCollateralCandidate, Required: required, MaxInputs: maxInputs}
+	}
+	// smallest first, ties broken by out ref for stable results
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if c := amountOf(candidates[i].Value, ledger.ADA).Cmp(amountOf(candidates[j].Value, ledger.ADA)); c != 0 {
+			return c < 0
+		}
+		return candidates[i].OutRef().Cmp(candidates[j].OutRef()) < 0
+	})
+
+	for k := 1; k <= maxInputs && k <= len(candidates); k++ {
+		// the k largest candidates cover the most, if they do not, no k do
+		chosen := append([]ledger.Utxo{}, candidates[len(candidates)-k:]...)
+		res, ok := params.collateral(chosen, required)
+		if !ok {
+			continue
+		}
+		// then swap each one, largest first, for the smallest unused candidate that still covers
+		used := make(map[ledger.OutRef]bool)
+		for _, u := range chosen {
+			used[u.OutRef()] = true
+		}
+		for i := len(chosen) - 1; i >= 0; i-- {
+			for _, c := range candidates {
+				if used[c.OutRef()] {
+					continue
+				}
+				if amountOf(c.Value, ledger.ADA).Cmp(amountOf(chosen[i].Value, ledger.ADA)) >= 0 {
+					break
+				}
+				trial := append([]ledger.Utxo{}, chosen...)
+				trial[i] = c
+				if r, ok := params.collateral(trial, required); ok {
+					used[chosen[i].OutRef()] = false
+					used[c.OutRef()] = true
+					chosen, res = trial, r
+					break
+				}
+			}
+		}
+		return res, nil
+	}
+	return CollateralResult{}, &CollateralError{Reason: CollateralBelowRequired, Required: required, Candidates: len(candidates), MaxInputs: maxInputs}
+}
+
+// collateral returns the collateral result of inputs if they cover required.
+func (p CollateralParams) collateral(inputs []ledger.Utxo, required *big.Int) (CollateralResult, bool) {
+	total := ledger.SumValueOfUtxos(inputs)
+	lovelace := amountOf(total, ledger.ADA)
+	if lovelace.Cmp(required) < 0 {
+		return CollateralResult{}, false
+	}
+	res := CollateralResult{Inputs: inputs, TotalCollateral: new(big.Int).Set(lovelace)}
+	if p.ReturnAddress == "" {
+		return res, true
+	}
+	ret := total.Clone()
+	ret[ledger.ADA] = new(big.Int).Sub(lovelace, required)
+	min, err := ledger.MinimumADAForOutput(ledger.Output{Address: p.ReturnAddress, Value: ret}, p.ProtocolParams)
+	if err != nil {
+		return CollateralResult{}, false
+	}
+	if ret[ledger.ADA].Cmp(min) < 0 {
+		// ADA-only excess too small for a return output is forfeited too
+		return res, total.HasOnlyADA()
+	}
+	res.Return = ret
+	res.TotalCollateral = required
+	return res, true
+}
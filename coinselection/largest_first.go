@@ -0,0 +1,47 @@
+package coinselection
+
+import (
+	"fmt"
+
+	"github.com/minswap/pab-go/ledger"
+)
+
+// SelectLargestFirst repeatedly picks the UTxO holding the most of the asset
+// that is still missing, until outputs, fee and change minimum ADA are covered.
+func SelectLargestFirst(utxos []ledger.Utxo, params Params) (Result, error) {
+	remaining := params.available(utxos)
+	target := params.target()
+	selected := ledger.NewValue()
+	var inputs []ledger.Utxo
+
+	for {
+		asset, missing, ok := deficit(selected, target)
+		if !ok {
+			change, ok := params.change(selected, target)
+			if ok {
+				return Result{Inputs: inputs, Change: change}, nil
+			}
+			asset, missing = ledger.ADA, params.changeDeficit(selected, target)
+		}
+
+		best := -1
+		for i, u := range remaining {
+			if !u.Value.Contains(asset) {
+				continue
+			}
+			if best < 0 || u.Value[asset].Cmp(remaining[best].Value[asset]) > 0 {
+				best = i
+			}
+		}
+		if best < 0 {
+			return Result{}, fmt.Errorf("%w: missing %s of %s", ErrInsufficientFunds, missing, asset)
+		}
+		if err := params.checkInputCount(len(inputs) + 1); err != nil {
+			return Result{}, err
+		}
+		u := remaining[best]
+		remaining = append(remaining[:best], remaining[best+1:]...)
+		inputs = append(inputs, u)
+		selected.AddAll(u.Value)
+	}
+}
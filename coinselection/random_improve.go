@@ -0,0 +1,91 @@
+package coinselection
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"time"
+
+	"github.com/minswap/pab-go/ledger"
+)
+
+// SelectRandomImprove implements CIP-2 random-improve per asset. It first
+// picks random UTxOs until every asset is covered, then keeps adding random
+// UTxOs while they bring each asset closer to twice its target without
+// exceeding three times the target, so change outputs stay useful for later
+// transactions.
+func SelectRandomImprove(utxos []ledger.Utxo, params Params) (Result, error) {
+	rnd := params.Rand
+	if rnd == nil {
+		rnd = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	remaining := params.available(utxos)
+	target := params.target()
+	selected := ledger.NewValue()
+	var inputs []ledger.Utxo
+
+	// pick removes a random remaining UTxO holding asset, or returns -1
+	pick := func(asset ledger.Asset) int {
+		var candidates []int
+		for i, u := range remaining {
+			if u.Value.Contains(asset) {
+				candidates = append(candidates, i)
+			}
+		}
+		if len(candidates) == 0 {
+			return -1
+		}
+		return candidates[rnd.Intn(len(candidates))]
+	}
+	take := func(i int) {
+		u := remaining[i]
+		remaining = append(remaining[:i], remaining[i+1:]...)
+		inputs = append(inputs, u)
+		selected.AddAll(u.Value)
+	}
+
+	// Phase 1: random selection
+	for {
+		asset, missing, ok := deficit(selected, target)
+		if !ok {
+			if _, ok := params.change(selected, target); ok {
+				break
+			}
+			asset, missing = ledger.ADA, params.changeDeficit(selected, target)
+		}
+		i := pick(asset)
+		if i < 0 {
+			return Result{}, fmt.Errorf("%w: missing %s of %s", ErrInsufficientFunds, missing, asset)
+		}
+		if err := params.checkInputCount(len(inputs) + 1); err != nil {
+			return Result{}, err
+		}
+		take(i)
+	}
+
+	// Phase 2: improvement
+	for _, asset := range targetAssets(target) {
+		ideal := new(big.Int).Mul(target[asset], big.NewInt(2))
+		upper := new(big.Int).Mul(target[asset], big.NewInt(3))
+		for params.MaxInputs == 0 || len(inputs) < params.MaxInputs {
+			i := pick(asset)
+			if i < 0 {
+				break
+			}
+			current := amountOf(selected, asset)
+			next := new(big.Int).Add(current, remaining[i].Value[asset])
+			if next.Cmp(upper) > 0 || distance(next, ideal).Cmp(distance(current, ideal)) >= 0 {
+				break
+			}
+			take(i)
+		}
+	}
+
+	change, _ := params.change(selected, target)
+	return Result{Inputs: inputs, Change: change}, nil
+}
+
+func distance(a, b *big.Int) *big.Int {
+	d := new(big.Int).Sub(a, b)
+	return d.Abs(d)
+}
@@ -0,0 +1,147 @@
+package fee
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/minswap/pab-go/cbor"
+	"github.com/minswap/pab-go/ledger"
+	"github.com/minswap/pab-go/txbuilder"
+)
+
+// placeholderFee encodes on 5 bytes like any fee between 0.065536 and 4294 ADA.
+const placeholderFee = 1<<32 - 1
+
+// EstimateTxBuilder estimates the fee of b signed by witnesses keys, with the
+// execution units of its Raw script options. refScriptSize is the total size
+// of the scripts referenced by its inputs.
+// Unless b has a fee, the placeholder fee and change output are sized for
+// the worst case, so the estimation is slightly above the real fee.
+func EstimateTxBuilder(b *txbuilder.TxBuilder, witnesses int, params ledger.ProtocolParams, refScriptSize int64) (Breakdown, error) {
+	tx, err := b.Transaction()
+	if err != nil {
+		return Breakdown{}, fmt.Errorf("fail to convert tx builder: %w", err)
+	}
+	if !b.IsRaw() {
+		tx.Fee = placeholderFee
+	}
+	if len(tx.Redeemers) > 0 || len(tx.Datums) > 0 {
+		tx.ScriptDataHash = strings.Repeat("00", 32)
+	}
+	if b.ChangeAddress != "" {
+		tx.Outputs = append(tx.Outputs, ledger.Output{Address: b.ChangeAddress, Value: estimateChange(b, tx)})
+	}
+	raw, err := tx.MarshalCBOR()
+	if err != nil {
+		return Breakdown{}, fmt.Errorf("fail to serialize tx: %w", err)
+	}
+	var exMem, exSteps int64
+	for _, r := range tx.Redeemers {
+		if r.ExMem <= 0 || r.ExCPU <= 0 {
+			return Breakdown{}, fmt.Errorf("invalid tx: redeemer %d of tag %d has no execution units, use the Raw options to set them", r.Index, r.Tag)
+		}
+		exMem += r.ExMem
+		exSteps += r.ExCPU
+	}
+	return Compute(int64(len(raw))+witnessesSize(0, witnesses), exMem, exSteps, refScriptSize, params)
+}
+
+func estimateChange(b *txbuilder.TxBuilder, tx *txbuilder.Transaction) ledger.Value {
+	change := ledger.NewValue()
+	for _, in := range b.PubKeyInputs {
+		change.AddAll(in.TxOut.Value)
+	}
+	for _, in := range b.ScriptInputs {
+		change.AddAll(in.TxOut.Value)
+	}
+	for _, w := range b.Withdrawals {
+		change.Add(ledger.ADA, big.NewInt(w.Amount))
+	}
+	change.AddAll(tx.Mint.Minted())
+	change.RemoveAll(tx.Mint.Burned())
+	for _, out := range tx.Outputs {
+		change.RemoveAll(out.Value)
+	}
+	for asset, amount := range change {
+		if amount.Sign() < 0 {
+			change.RemoveAsset(asset)
+		}
+	}
+	if !change.Contains(ledger.ADA) {
+		change.Add(ledger.ADA, big.NewInt(placeholderFee))
+	}
+	return change
+}
+
+// EstimateTx estimates the fee of a serialized tx once it holds witnesses
+// vkey witnesses, including the ones it already has.
+func EstimateTx(txCBOR []byte, witnesses int, params ledger.ProtocolParams, refScriptSize int64) (Breakdown, error) {
+	decoded, err := cbor.Unmarshal(txCBOR)
+	if err != nil {
+		return Breakdown{}, fmt.Errorf("fail to decode tx: %w", err)
+	}
+	tx, ok := decoded.([]interface{})
+	if !ok || len(tx) < 3 {
+		return Breakdown{}, fmt.Errorf("invalid tx: expect an array of at least 3 elements")
+	}
+	ws, ok := tx[1].(cbor.Map)
+	if !ok {
+		return Breakdown{}, fmt.Errorf("invalid tx: witness set is not a map")
+	}
+	existing := 0
+	if vkeys, ok := ws.Get(uint64(0)); ok {
+		if vkeys, ok := vkeys.([]interface{}); ok {
+			existing = len(vkeys)
+		}
+	}
+	redeemers, _ := ws.Get(uint64(5))
+	exMem, exSteps, err := redeemersExUnits(redeemers)
+	if err != nil {
+		return Breakdown{}, err
+	}
+	return Compute(int64(len(txCBOR))+witnessesSize(existing, witnesses-existing), exMem, exSteps, refScriptSize, params)
+}
+
+// redeemersExUnits sums the ex units of redeemers in the array format
+// [tag, index, data, ex_units] or the Conway map format {[tag, index]: [data, ex_units]}.
+func redeemersExUnits(redeemers interface{}) (int64, int64, error) {
+	var exUnits []interface{}
+	switch rs := redeemers.(type) {
+	case nil:
+		return 0, 0, nil
+	case []interface{}:
+		for _, r := range rs {
+			if r, ok := r.([]interface{}); ok && len(r) == 4 {
+				exUnits = append(exUnits, r[3])
+				continue
+			}
+			return 0, 0, fmt.Errorf("invalid redeemer")
+		}
+	case cbor.Map:
+		for _, e := range rs {
+			if r, ok := e.Value.([]interface{}); ok && len(r) == 2 {
+				exUnits = append(exUnits, r[1])
+				continue
+			}
+			return 0, 0, fmt.Errorf("invalid redeemer")
+		}
+	default:
+		return 0, 0, fmt.Errorf("invalid redeemers")
+	}
+	var mem, steps int64
+	for _, units := range exUnits {
+		u, ok := units.([]interface{})
+		if !ok || len(u) != 2 {
+			return 0, 0, fmt.Errorf("invalid redeemer ex units")
+		}
+		m, ok1 := u[0].(uint64)
+		s, ok2 := u[1].(uint64)
+		if !ok1 || !ok2 {
+			return 0, 0, fmt.Errorf("invalid redeemer ex units")
+		}
+		mem += int64(m)
+		steps += int64(s)
+	}
+	return mem, steps, nil
+}
@@ -0,0 +1,110 @@
+// Package fee estimates transaction fees offline from protocol parameters.
+package fee
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/minswap/pab-go/cbor"
+	"github.com/minswap/pab-go/ledger"
+)
+
+const (
+	// VkeyWitnessSize is the size of [vkey, signature] in the witness set.
+	VkeyWitnessSize = 101
+
+	refScriptTierSize = 25600
+)
+
+var refScriptTierMultiplier = big.NewRat(12, 10)
+
+var ErrTxTooLarge = errors.New("tx exceeds max tx size")
+
+// Breakdown is the detail of a fee estimation, all amounts are in lovelace.
+type Breakdown struct {
+	TxSize        int64
+	SizeFee       int64
+	FixedFee      int64
+	ExMem         int64
+	ExSteps       int64
+	ScriptFee     int64
+	RefScriptSize int64
+	RefScriptFee  int64
+	Total         int64
+}
+
+func (b Breakdown) String() string {
+	return fmt.Sprintf(
+		"total=%d size=%dB size_fee=%d fixed_fee=%d ex_mem=%d ex_steps=%d script_fee=%d ref_script_size=%dB ref_script_fee=%d",
+		b.Total, b.TxSize, b.SizeFee, b.FixedFee, b.ExMem, b.ExSteps, b.ScriptFee, b.RefScriptSize, b.RefScriptFee,
+	)
+}
+
+// Compute returns the fee of a tx of txSize bytes (witnesses included)
+// spending exMem and exSteps, and referencing refScriptSize bytes of scripts.
+func Compute(txSize, exMem, exSteps, refScriptSize int64, params ledger.ProtocolParams) (Breakdown, error) {
+	b := Breakdown{
+		TxSize:        txSize,
+		SizeFee:       params.TxFeePerByte * txSize,
+		FixedFee:      params.TxFeeFixed,
+		ExMem:         exMem,
+		ExSteps:       exSteps,
+		RefScriptSize: refScriptSize,
+	}
+	if exMem > 0 || exSteps > 0 {
+		prices := params.ExecutionUnitPrices
+		if prices.PriceMemory == nil || prices.PriceSteps == nil {
+			return b, errors.New("missing execution unit prices in protocol params")
+		}
+		fee := new(big.Rat).Mul(&prices.PriceMemory.Rat, new(big.Rat).SetInt64(exMem))
+		fee.Add(fee, new(big.Rat).Mul(&prices.PriceSteps.Rat, new(big.Rat).SetInt64(exSteps)))
+		b.ScriptFee = ceil(fee)
+	}
+	if refScriptSize > 0 && params.MinFeeRefScriptCostPerByte != nil {
+		b.RefScriptFee = refScriptFee(refScriptSize, &params.MinFeeRefScriptCostPerByte.Rat)
+	}
+	b.Total = b.SizeFee + b.FixedFee + b.ScriptFee + b.RefScriptFee
+	if params.MaxTxSize > 0 && txSize > params.MaxTxSize {
+		return b, fmt.Errorf("%w: %d > %d", ErrTxTooLarge, txSize, params.MaxTxSize)
+	}
+	return b, nil
+}
+
+// refScriptFee prices every tier of 25600 bytes 1.2 times the previous one.
+func refScriptFee(size int64, costPerByte *big.Rat) int64 {
+	fee := new(big.Rat)
+	price := new(big.Rat).Set(costPerByte)
+	for size > 0 {
+		n := size
+		if n > refScriptTierSize {
+			n = refScriptTierSize
+		}
+		fee.Add(fee, new(big.Rat).Mul(price, new(big.Rat).SetInt64(n)))
+		price.Mul(price, refScriptTierMultiplier)
+		size -= n
+	}
+	return new(big.Int).Quo(fee.Num(), fee.Denom()).Int64()
+}
+
+func ceil(r *big.Rat) int64 {
+	q, m := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if m.Sign() > 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	return q.Int64()
+}
+
+// witnessesSize estimates the bytes added to the witness set by n vkey
+// witnesses when it already holds existing ones.
+func witnessesSize(existing, n int) int64 {
+	if n <= 0 {
+		return 0
+	}
+	size := int64(n)*VkeyWitnessSize + int64(len(cbor.AppendArrayHeader(nil, existing+n)))
+	if existing == 0 {
+		// key 0 of the witness set map
+		return size + 1
+	}
+	return size - int64(len(cbor.AppendArrayHeader(nil, existing)))
+}
@@ -0,0 +1,159 @@
+package fee
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/minswap/pab-go/cbor"
+	"github.com/minswap/pab-go/ledger"
+	"github.com/minswap/pab-go/txbuilder"
+	"github.com/stretchr/testify/assert"
+)
+
+const paramsJSON = `{
+	"txFeePerByte": 44,
+	"txFeeFixed": 155381,
+	"maxTxSize": 16384,
+	"utxoCostPerByte": 4310,
+	"executionUnitPrices": {"priceMemory": 5.77e-2, "priceSteps": 7.21e-05},
+	"minFeeRefScriptCostPerByte": 15
+}`
+
+func testParams(t *testing.T) ledger.ProtocolParams {
+	var params ledger.ProtocolParams
+	assert.NoError(t, json.Unmarshal([]byte(paramsJSON), &params))
+	return params
+}
+
+func TestCompute(t *testing.T) {
+	params := testParams(t)
+	assert.Equal(t, big.NewRat(577, 10000), &params.ExecutionUnitPrices.PriceMemory.Rat)
+
+	b, err := Compute(300, 1_000_000, 500_000_000, 30000, params)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(13200), b.SizeFee)
+	assert.Equal(t, int64(93750), b.ScriptFee)
+	// 25600 bytes at 15 then 4400 bytes at 18
+	assert.Equal(t, int64(463200), b.RefScriptFee)
+	assert.Equal(t, int64(13200+155381+93750+463200), b.Total)
+
+	_, err = Compute(20000, 0, 0, 0, params)
+	assert.ErrorIs(t, err, ErrTxTooLarge)
+}
+
+func TestRationalJSON(t *testing.T) {
+	var prices ledger.ExecutionUnitPrices
+	assert.NoError(t, json.Unmarshal([]byte(`{"priceMemory": "577/10000", "priceSteps": {"numerator": 721, "denominator": 10000000}}`), &prices))
+	assert.Equal(t, big.NewRat(577, 10000), &prices.PriceMemory.Rat)
+	assert.Equal(t, big.NewRat(721, 10000000), &prices.PriceSteps.Rat)
+}
+
+func TestEstimate(t *testing.T) {
+	params := testParams(t)
+	addr := "addr_test1qpmtp5t0t5y6cqkaz7rfsyrx7mld77kpvksgkwm0p7en7qum7a589n30e80tclzrrnj8qr4qvzj6al0vpgtnmrkkksnqd8upj0"
+	b := txbuilder.New(
+		txbuilder.SpendPubKeyUtxos(ledger.Utxo{
+			TxID:    "a5c8c7bfd7a8d8a6e4f8b0b6aa66a8c7d1d6cdb9c4b4c3d1a5c8c7bfd7a8d8a6",
+			TxIndex: 0,
+			Address: addr,
+			Value:   ledger.NewValue().Add(ledger.ADA, big.NewInt(10_000_000)),
+		}),
+		txbuilder.PayToPubKey(addr, ledger.NewValue().Add(ledger.ADA, big.NewInt(2_000_000))),
+		txbuilder.PayChangeTo(addr),
+	)
+	est, err := EstimateTxBuilder(&b, 1, params, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, params.TxFeePerByte*est.TxSize+params.TxFeeFixed, est.Total)
+
+	tx, err := b.Transaction()
+	assert.NoError(t, err)
+	tx.Fee = placeholderFee
+	tx.Outputs = append(tx.Outputs, ledger.Output{Address: addr, Value: ledger.NewValue().Add(ledger.ADA, big.NewInt(8_000_000))})
+	raw, err := tx.MarshalCBOR()
+	assert.NoError(t, err)
+	fromTx, err := EstimateTx(raw, 1, params, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, est, fromTx)
+
+	signed, err := tx.MarshalWithWitnesses([]txbuilder.VkeyWitness{{Vkey: make([]byte, 32), Signature: make([]byte, 64)}})
+	assert.NoError(t, err)
+	assert.Equal(t, est.TxSize, int64(len(signed)))
+	fromSigned, err := EstimateTx(signed, 1, params, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, est, fromSigned)
+}
+
+func TestEstimateScripts(t *testing.T) {
+	params := testParams(t)
+	addr := "addr_test1qpmtp5t0t5y6cqkaz7rfsyrx7mld77kpvksgkwm0p7en7qum7a589n30e80tclzrrnj8qr4qvzj6al0vpgtnmrkkksnqd8upj0"
+	scriptPath := filepath.Join(t.TempDir(), "always-succeeds.plutus")
+	assert.NoError(t, os.WriteFile(scriptPath, []byte(`{"type":"PlutusScriptV2","description":"","cborHex":"49480100002221200101"}`), 0644))
+	datumHash := ledger.DatumHash([]byte{0xd8, 0x79, 0x80})
+	scriptUtxo := ledger.Utxo{
+		TxID:      "a5c8c7bfd7a8d8a6e4f8b0b6aa66a8c7d1d6cdb9c4b4c3d1a5c8c7bfd7a8d8a6",
+		TxIndex:   1,
+		Address:   addr,
+		Value:     ledger.NewValue().Add(ledger.ADA, big.NewInt(10_000_000)),
+		DatumHash: &datumHash,
+	}
+
+	b := txbuilder.New(
+		txbuilder.SpendScriptUtxoRaw(scriptUtxo, scriptPath, `{"constructor":0,"fields":[]}`, `{"int":1}`, 10_000, 20_000_000),
+		txbuilder.PayChangeTo(addr),
+	)
+	est, err := EstimateTxBuilder(&b, 1, params, 1000)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10_000), est.ExMem)
+	assert.Equal(t, int64(20_000_000), est.ExSteps)
+	assert.Equal(t, int64(577+1442), est.ScriptFee)
+	assert.Equal(t, int64(15_000), est.RefScriptFee)
+	assert.Equal(t, est.SizeFee+est.FixedFee+est.ScriptFee+est.RefScriptFee, est.Total)
+
+	// without the Raw option, the execution units are unknown
+	b = txbuilder.New(
+		txbuilder.SpendScriptUtxo(scriptUtxo, scriptPath, `{"constructor":0,"fields":[]}`, `{"int":1}`),
+		txbuilder.PayChangeTo(addr),
+	)
+	_, err = EstimateTxBuilder(&b, 1, params, 0)
+	assert.Error(t, err)
+}
+
+func TestEstimateTx(t *testing.T) {
+	params := testParams(t)
+	body := cbor.Map{{Key: uint64(2), Value: uint64(200_000)}}
+	redeemers := []interface{}{
+		[]interface{}{uint64(0), uint64(0), uint64(42), []interface{}{uint64(1_000_000), uint64(500_000_000)}},
+	}
+	unsigned, err := cbor.Marshal([]interface{}{body, cbor.Map{{Key: uint64(5), Value: redeemers}}, true, nil})
+	assert.NoError(t, err)
+	est, err := EstimateTx(unsigned, 1, params, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(unsigned))+VkeyWitnessSize+2, est.TxSize)
+	assert.Equal(t, int64(1_000_000), est.ExMem)
+	assert.Equal(t, int64(93750), est.ScriptFee)
+
+	// the same tx with its witness already counts it once
+	witness := []interface{}{make([]byte, 32), make([]byte, 64)}
+	signed, err := cbor.Marshal([]interface{}{body, cbor.Map{
+		{Key: uint64(0), Value: []interface{}{witness}},
+		{Key: uint64(5), Value: redeemers},
+	}, true, nil})
+	assert.NoError(t, err)
+	fromSigned, err := EstimateTx(signed, 1, params, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, est.TxSize, int64(len(signed)))
+	assert.Equal(t, int64(len(signed)), fromSigned.TxSize)
+	assert.Equal(t, est.Total, fromSigned.Total)
+
+	// Conway map redeemers
+	conway, err := cbor.Marshal([]interface{}{body, cbor.Map{{Key: uint64(5), Value: cbor.Map{
+		{Key: []interface{}{uint64(0), uint64(0)}, Value: []interface{}{uint64(42), []interface{}{uint64(1_000_000), uint64(500_000_000)}}},
+	}}}, true, nil})
+	assert.NoError(t, err)
+	fromConway, err := EstimateTx(conway, 1, params, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(93750), fromConway.ScriptFee)
+}
@@ -2,10 +2,15 @@ module github.com/minswap/pab-go
 
 go 1.18
 
-require github.com/stretchr/testify v1.7.0
+require (
+	github.com/gorilla/websocket v1.5.0
+	github.com/stretchr/testify v1.7.0
+	golang.org/x/crypto v0.14.0
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.13.0 // indirect
 	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b // indirect
 )
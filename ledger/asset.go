@@ -1,10 +1,18 @@
 package ledger
 
 import (
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"strings"
+
+	"github.com/minswap/pab-go/bech32"
+	"golang.org/x/crypto/blake2b"
 )
 
+// MaxTokenNameLength is the maximum length of a token name in bytes.
+const MaxTokenNameLength = 32
+
 var ADA = NewAsset("", "")
 
 type Asset struct {
@@ -21,19 +29,53 @@ func AssetFromString(s string) (Asset, error) {
 		return ADA, nil
 	}
 	as := strings.Split(s, ".")
+	var asset Asset
 	if len(as) == 1 {
-		return Asset{
+		asset = Asset{
 			CurrencySymbol: as[0],
 			TokenName:      "",
-		}, nil
+		}
 	} else if len(as) == 2 {
-		return Asset{
+		asset = Asset{
 			CurrencySymbol: as[0],
 			TokenName:      as[1],
-		}, nil
+		}
 	} else {
 		return Asset{}, errors.New("cannot parse asset from string, expect input to have format lovelace, $policyID or $policyID.$assetName")
 	}
+	if err := asset.Validate(); err != nil {
+		return Asset{}, err
+	}
+	return asset, nil
+}
+
+// Validate checks that the policy ID is 28 bytes and the token name at most
+// 32 bytes, both hex-encoded.
+func (as Asset) Validate() error {
+	if as == ADA {
+		return nil
+	}
+	if b, err := hex.DecodeString(as.CurrencySymbol); err != nil || len(b) != policyLength {
+		return fmt.Errorf("invalid policy ID %q: expect %d hex characters", as.CurrencySymbol, policyLength*2)
+	}
+	if b, err := hex.DecodeString(as.TokenName); err != nil || len(b) > MaxTokenNameLength {
+		return fmt.Errorf("invalid token name %q: expect at most %d hex-encoded bytes", as.TokenName, MaxTokenNameLength)
+	}
+	return nil
+}
+
+// Fingerprint returns the CIP-14 fingerprint of the asset, the bech32
+// encoding of the blake2b-160 hash of its policy ID and token name.
+func (as Asset) Fingerprint() (string, error) {
+	if err := as.Validate(); err != nil {
+		return "", err
+	}
+	policy, _ := hex.DecodeString(as.CurrencySymbol)
+	name, _ := hex.DecodeString(as.TokenName)
+	h, _ := blake2b.New(20, nil)
+	h.Write(policy)
+	h.Write(name)
+	return bech32.Encode("asset", h.Sum(nil))
 }
 
 func (as Asset) String() string {
@@ -0,0 +1,85 @@
+package ledger
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// CIP-67 asset name labels.
+const (
+	LabelReferenceNFT = 100
+	LabelNFT          = 222
+	LabelFT           = 333
+	LabelRFT          = 444
+)
+
+const maxAssetLabel = 0xffff
+
+// crc8 is the CRC-8 of CIP-67, polynomial 0x07 with no reflection.
+func crc8(data []byte) byte {
+	var crc byte
+	for _, b := range data {
+		crc ^= b
+		for i := 0; i < 8; i++ {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0x07
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// EncodeAssetLabel returns the hex-encoded 4-byte CIP-67 prefix of label:
+// 4 zero bits, the 16-bit label, its CRC-8 and 4 zero bits.
+func EncodeAssetLabel(label int) (string, error) {
+	if label < 0 || label > maxAssetLabel {
+		return "", fmt.Errorf("asset label %d out of range [0, %d]", label, maxAssetLabel)
+	}
+	n := []byte{byte(label >> 8), byte(label)}
+	return fmt.Sprintf("0%04x%02x0", label, crc8(n)), nil
+}
+
+// DecodeAssetLabel decodes a hex-encoded CIP-67 prefix, checking its CRC-8.
+func DecodeAssetLabel(prefix string) (int, error) {
+	b, err := hex.DecodeString(prefix)
+	if err != nil || len(b) != 4 {
+		return 0, fmt.Errorf("invalid asset label %q: expect 8 hex characters", prefix)
+	}
+	if b[0]>>4 != 0 || b[3]&0x0f != 0 {
+		return 0, fmt.Errorf("invalid asset label %q: missing zero brackets", prefix)
+	}
+	label := int(b[0])<<12 | int(b[1])<<4 | int(b[2])>>4
+	if checksum := b[2]<<4 | b[3]>>4; checksum != crc8([]byte{byte(label >> 8), byte(label)}) {
+		return 0, fmt.Errorf("invalid asset label %q: wrong checksum", prefix)
+	}
+	return label, nil
+}
+
+// NewLabeledAsset returns the asset whose token name is the CIP-67 prefix of
+// label followed by the hex-encoded name.
+func NewLabeledAsset(currencySymbol string, label int, name string) (Asset, error) {
+	prefix, err := EncodeAssetLabel(label)
+	if err != nil {
+		return Asset{}, err
+	}
+	asset := NewAsset(currencySymbol, prefix+name)
+	if err := asset.Validate(); err != nil {
+		return Asset{}, err
+	}
+	return asset, nil
+}
+
+// Label returns the CIP-67 label of the token name and the hex-encoded name
+// after it, or false when the token name has no valid label.
+func (as Asset) Label() (int, string, bool) {
+	if len(as.TokenName) < 8 {
+		return 0, "", false
+	}
+	label, err := DecodeAssetLabel(as.TokenName[:8])
+	if err != nil {
+		return 0, "", false
+	}
+	return label, as.TokenName[8:], true
+}
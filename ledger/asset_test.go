@@ -1,6 +1,7 @@
 package ledger
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -27,3 +28,58 @@ func TestAssetFromString(t *testing.T) {
 		}, a)
 	}
 }
+
+func TestAssetValidate(t *testing.T) {
+	for _, s := range []string{
+		"MIN",
+		"29d222ce763455e3d7a09a665ce554f00ac89d2e99a1a83d267170c",
+		"29d222ce763455e3d7a09a665ce554f00ac89d2e99a1a83d267170c6.4d494",
+		"29d222ce763455e3d7a09a665ce554f00ac89d2e99a1a83d267170c6.4d494g",
+		"29d222ce763455e3d7a09a665ce554f00ac89d2e99a1a83d267170c6." + strings.Repeat("00", 33),
+	} {
+		_, err := AssetFromString(s)
+		assert.Error(t, err, s)
+	}
+	_, err := AssetFromString("29d222ce763455e3d7a09a665ce554f00ac89d2e99a1a83d267170c6." + strings.Repeat("00", 32))
+	assert.NoError(t, err)
+}
+
+func TestAssetFingerprint(t *testing.T) {
+	// CIP-14 test vectors
+	for _, tc := range []struct{ policy, name, fingerprint string }{
+		{"7eae28af2208be856f7a119668ae52a49b73725e326dc16579dcc373", "", "asset1rjklcrnsdzqp65wjgrg55sy9723kw09mlgvlc3"},
+		{"7eae28af2208be856f7a119668ae52a49b73725e326dc16579dcc37e", "", "asset1nl0puwxmhas8fawxp8nx4e2q3wekg969n2auw3"},
+		{"7eae28af2208be856f7a119668ae52a49b73725e326dc16579dcc373", "504154415445", "asset13n25uv0yaf5kus35fm2k86cqy60z58d9xmde92"},
+		{"1e349c9bdea19fd6c147626a5260bc44b71635f398b67c59881df209", "504154415445", "asset1hv4p5tv2a837mzqrst04d0dcptdjmluqvdx9k3"},
+	} {
+		fp, err := NewAsset(tc.policy, tc.name).Fingerprint()
+		assert.NoError(t, err)
+		assert.Equal(t, tc.fingerprint, fp)
+	}
+}
+
+func TestAssetLabel(t *testing.T) {
+	// CIP-67 examples
+	for label, prefix := range map[int]string{0: "00000000", 1: "00001070", 23: "00017650", 100: "000643b0", 222: "000de140", 333: "0014df10", 444: "001bc280", 65535: "0ffff240"} {
+		p, err := EncodeAssetLabel(label)
+		assert.NoError(t, err)
+		assert.Equal(t, prefix, p, label)
+		l, err := DecodeAssetLabel(prefix)
+		assert.NoError(t, err)
+		assert.Equal(t, label, l)
+	}
+	_, err := DecodeAssetLabel("000de150")
+	assert.Error(t, err)
+	_, err = EncodeAssetLabel(65536)
+	assert.Error(t, err)
+
+	ref, err := NewLabeledAsset("29d222ce763455e3d7a09a665ce554f00ac89d2e99a1a83d267170c6", LabelReferenceNFT, "4d494e")
+	assert.NoError(t, err)
+	assert.Equal(t, "000643b04d494e", ref.TokenName)
+	label, name, ok := ref.Label()
+	assert.True(t, ok)
+	assert.Equal(t, LabelReferenceNFT, label)
+	assert.Equal(t, "4d494e", name)
+	_, _, ok = NewAsset("29d222ce763455e3d7a09a665ce554f00ac89d2e99a1a83d267170c6", "4d494e").Label()
+	assert.False(t, ok)
+}
@@ -0,0 +1,67 @@
+package ledger
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// CostModels are the Plutus cost models of the protocol parameters, each as
+// the list of its parameters in ledger order.
+type CostModels map[ScriptLanguage][]int64
+
+func (m *CostModels) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("fail to decode cost models: %w", err)
+	}
+	models := make(CostModels)
+	for name, content := range raw {
+		var lang ScriptLanguage
+		switch name {
+		case "PlutusV1", "PlutusScriptV1":
+			lang = PlutusV1
+		case "PlutusV2", "PlutusScriptV2":
+			lang = PlutusV2
+		case "PlutusV3", "PlutusScriptV3":
+			lang = PlutusV3
+		default:
+			return fmt.Errorf("unknown cost model language: %s", name)
+		}
+		model, err := decodeCostModel(lang, content)
+		if err != nil {
+			return fmt.Errorf("invalid %s cost model: %w", name, err)
+		}
+		models[lang] = model
+	}
+	*m = models
+	return nil
+}
+
+// decodeCostModel decodes a list of parameters, or a PlutusV1 map of parameters
+// by name as printed by older cardano-cli. The ledger orders PlutusV1
+// parameters by name, but not the PlutusV2 and PlutusV3 ones, so their named
+// maps are rejected.
+func decodeCostModel(lang ScriptLanguage, data []byte) ([]int64, error) {
+	var list []int64
+	if err := json.Unmarshal(data, &list); err == nil {
+		return list, nil
+	}
+	if lang != PlutusV1 {
+		return nil, fmt.Errorf("%s cost model must be a list of parameters in ledger order", lang)
+	}
+	var named map[string]int64
+	if err := json.Unmarshal(data, &named); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(named))
+	for name := range named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	list = make([]int64, len(names))
+	for i, name := range names {
+		list[i] = named[name]
+	}
+	return list, nil
+}
@@ -0,0 +1,52 @@
+package ledger
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+type keyFile struct {
+	Type    string `json:"type"`
+	CBORHex string `json:"cborHex"`
+}
+
+// ReadSigningKeyFile reads a cardano-cli signing key file. Normal keys hold a
+// 32-byte seed, extended keys a 64-byte extended secret followed by the
+// 32-byte public key and chain code; only normal keys return a usable
+// ed25519.PrivateKey.
+func ReadSigningKeyFile(path string) (ed25519.PublicKey, ed25519.PrivateKey, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fail to read signing key file: %w", err)
+	}
+	var f keyFile
+	if err := json.Unmarshal(content, &f); err != nil {
+		return nil, nil, fmt.Errorf("fail to decode signing key file: %w", err)
+	}
+	b, err := hex.DecodeString(f.CBORHex)
+	if err != nil || len(b) < 2 {
+		return nil, nil, fmt.Errorf("invalid signing key cborHex")
+	}
+	switch {
+	case len(b) == 34 && b[0] == 0x58 && b[1] == 32:
+		priv := ed25519.NewKeyFromSeed(b[2:])
+		return priv.Public().(ed25519.PublicKey), priv, nil
+	case len(b) == 130 && b[0] == 0x58 && b[1] == 128:
+		return ed25519.PublicKey(b[2+64 : 2+96]), nil, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported signing key of %d bytes", len(b))
+	}
+}
+
+// KeyHashFromSigningKeyFile returns the hash of the verification key matching
+// a signing key file.
+func KeyHashFromSigningKeyFile(path string) (string, error) {
+	pub, _, err := ReadSigningKeyFile(path)
+	if err != nil {
+		return "", err
+	}
+	return KeyHash(pub), nil
+}
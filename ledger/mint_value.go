@@ -0,0 +1,133 @@
+package ledger
+
+import (
+	"math/big"
+)
+
+// MintValue is the signed value of a transaction mint field: positive
+// amounts are minted, negative ones burned. It is kept apart from Value,
+// whose amounts are never negative.
+type MintValue map[Asset]*big.Int
+
+func NewMintValue() MintValue {
+	return make(MintValue)
+}
+
+func (m MintValue) Clone() MintValue {
+	ret := NewMintValue()
+	for asset, amount := range m {
+		ret[asset] = new(big.Int).Set(amount)
+	}
+	return ret
+}
+
+// Add adds a signed amount of asset, removing the asset when it nets to zero.
+func (m MintValue) Add(asset Asset, amount *big.Int) MintValue {
+	if amount.Sign() == 0 {
+		return m
+	}
+	sum := new(big.Int).Add(amountOf(m, asset), amount)
+	if sum.Sign() == 0 {
+		delete(m, asset)
+	} else {
+		m[asset] = sum
+	}
+	return m
+}
+
+// Mint adds every amount of val.
+func (m MintValue) Mint(val Value) MintValue {
+	for asset, amount := range val {
+		m.Add(asset, amount)
+	}
+	return m
+}
+
+// Burn subtracts every amount of val.
+func (m MintValue) Burn(val Value) MintValue {
+	for asset, amount := range val {
+		m.Add(asset, new(big.Int).Neg(amount))
+	}
+	return m
+}
+
+func (m MintValue) AddAll(n MintValue) MintValue {
+	for asset, amount := range n {
+		m.Add(asset, amount)
+	}
+	return m
+}
+
+func (m MintValue) Negate() MintValue {
+	ret := NewMintValue()
+	for asset, amount := range m {
+		ret[asset] = new(big.Int).Neg(amount)
+	}
+	return ret
+}
+
+func (m MintValue) IsZero() bool {
+	for _, amount := range m {
+		if amount.Sign() != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (m MintValue) Equal(n MintValue) bool {
+	for asset, amount := range m {
+		if amount.Cmp(amountOf(n, asset)) != 0 {
+			return false
+		}
+	}
+	for asset, amount := range n {
+		if amount.Cmp(amountOf(m, asset)) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Minted returns the positive part of m.
+func (m MintValue) Minted() Value {
+	ret := NewValue()
+	for asset, amount := range m {
+		if amount.Sign() > 0 {
+			ret.Add(asset, amount)
+		}
+	}
+	return ret
+}
+
+// Burned returns the negative part of m, as positive amounts.
+func (m MintValue) Burned() Value {
+	ret := NewValue()
+	for asset, amount := range m {
+		if amount.Sign() < 0 {
+			ret.Add(asset, new(big.Int).Neg(amount))
+		}
+	}
+	return ret
+}
+
+// Assets returns the assets of m sorted by policy ID then token name.
+func (m MintValue) Assets() []Asset {
+	keys := make([]Asset, 0, len(m))
+	for a := range m {
+		keys = append(keys, a)
+	}
+	SortAssets(keys)
+	return keys
+}
+
+// Policies returns the sorted policy IDs of m.
+func (m MintValue) Policies() []string {
+	var policies []string
+	for _, asset := range m.Assets() {
+		if len(policies) == 0 || policies[len(policies)-1] != asset.CurrencySymbol {
+			policies = append(policies, asset.CurrencySymbol)
+		}
+	}
+	return policies
+}
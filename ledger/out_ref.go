@@ -0,0 +1,49 @@
+package ledger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// OutRef references a transaction output by transaction ID and index.
+type OutRef struct {
+	TxID    string `json:"txID"`
+	TxIndex int    `json:"txIndex"`
+}
+
+// ParseOutRef parses the txId#txIx format of cardano-cli.
+func ParseOutRef(s string) (OutRef, error) {
+	fields := strings.Split(s, "#")
+	if len(fields) != 2 {
+		return OutRef{}, fmt.Errorf("expect format txId#txIx, got %s", s)
+	}
+	txIx, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return OutRef{}, fmt.Errorf("error when parse txIx: %s: %w", fields[1], err)
+	}
+	return OutRef{TxID: fields[0], TxIndex: txIx}, nil
+}
+
+func (r OutRef) String() string {
+	return fmt.Sprintf("%s#%d", r.TxID, r.TxIndex)
+}
+
+// Cmp orders refs by transaction ID then index, the order of transaction inputs.
+func (r OutRef) Cmp(o OutRef) int {
+	if c := strings.Compare(r.TxID, o.TxID); c != 0 {
+		return c
+	}
+	switch {
+	case r.TxIndex < o.TxIndex:
+		return -1
+	case r.TxIndex > o.TxIndex:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (u Utxo) OutRef() OutRef {
+	return OutRef{TxID: u.TxID, TxIndex: u.TxIndex}
+}
@@ -0,0 +1,138 @@
+package ledger
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/minswap/pab-go/address"
+	"github.com/minswap/pab-go/cbor"
+)
+
+// utxoEntryOverhead is the constant number of bytes added to the serialized
+// size of an output when computing its minimum ADA since Babbage.
+const utxoEntryOverhead = 160
+
+// mainnetUtxoCostPerByte is coinsPerUTxOByte on mainnet since Babbage.
+const mainnetUtxoCostPerByte = 4310
+
+// placeholderBaseAddress and placeholderDatumHash stand for a base address and
+// a datum hash when sizing an output whose address and datum are unknown.
+var (
+	placeholderBaseAddress = mustBaseAddress(strings.Repeat("00", 28))
+	placeholderDatumHash   = strings.Repeat("00", 32)
+)
+
+func mustBaseAddress(keyHash string) string {
+	addr, err := address.NewBaseAddress(address.Mainnet, address.NewKeyCredential(keyHash), address.NewKeyCredential(keyHash))
+	if err != nil {
+		panic(err)
+	}
+	return addr.String()
+}
+
+// Output is a transaction output. DatumHash and InlineDatum are mutually
+// exclusive, InlineDatum is CBOR-encoded Plutus data.
+type Output struct {
+	Address         string
+	Value           Value
+	DatumHash       string
+	InlineDatum     []byte
+	ReferenceScript *Script
+}
+
+// MarshalCBOR encodes the output as cardano-cli does: the legacy array
+// [address, value, datum hash?] when the output has neither inline datum nor
+// reference script, the Babbage map otherwise.
+func (o Output) MarshalCBOR() ([]byte, error) {
+	addr, err := address.Parse(o.Address)
+	if err != nil {
+		return nil, err
+	}
+	if o.InlineDatum == nil && o.ReferenceScript == nil {
+		arr := []interface{}{addr.Bytes(), o.Value}
+		if o.DatumHash != "" {
+			hash, err := decodeDatumHash(o.DatumHash)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, hash)
+		}
+		return cbor.Marshal(arr)
+	}
+	m := cbor.Map{
+		{Key: uint64(0), Value: addr.Bytes()},
+		{Key: uint64(1), Value: o.Value},
+	}
+	switch {
+	case o.DatumHash != "" && o.InlineDatum != nil:
+		return nil, errors.New("output cannot have both datum hash and inline datum")
+	case o.DatumHash != "":
+		hash, err := decodeDatumHash(o.DatumHash)
+		if err != nil {
+			return nil, err
+		}
+		m = append(m, cbor.MapEntry{Key: uint64(2), Value: []interface{}{uint64(0), hash}})
+	case o.InlineDatum != nil:
+		m = append(m, cbor.MapEntry{Key: uint64(2), Value: []interface{}{uint64(1), cbor.Tag{Number: cbor.TagEncodedCBOR, Content: o.InlineDatum}}})
+	}
+	if o.ReferenceScript != nil {
+		scriptRef, err := o.ReferenceScript.MarshalCBOR()
+		if err != nil {
+			return nil, err
+		}
+		m = append(m, cbor.MapEntry{Key: uint64(3), Value: cbor.Tag{Number: cbor.TagEncodedCBOR, Content: scriptRef}})
+	}
+	return cbor.Marshal(m)
+}
+
+func decodeDatumHash(s string) ([]byte, error) {
+	hash, err := hex.DecodeString(s)
+	if err != nil || len(hash) != 32 {
+		return nil, fmt.Errorf("invalid datum hash: %s", s)
+	}
+	return hash, nil
+}
+
+// MinimumADAForOutput returns the minimum lovelace an output must hold since
+// Babbage: (160 + serialized output size) * coinsPerUTxOByte, the output being
+// sized in the format MarshalCBOR encodes it in. The lovelace
+// amount is part of the serialized output, so it is raised until it covers the
+// minimum computed with itself.
+func MinimumADAForOutput(out Output, params ProtocolParams) (*big.Int, error) {
+	if params.UtxoCostPerByte <= 0 {
+		return nil, errors.New("protocol params have no utxoCostPerByte")
+	}
+	val := out.Value.Clone().RemoveAsset(ADA)
+	out.Value = val
+	lovelace := big.NewInt(0)
+	for {
+		val[ADA] = lovelace
+		b, err := out.MarshalCBOR()
+		if err != nil {
+			return nil, fmt.Errorf("fail to serialize output: %w", err)
+		}
+		min := big.NewInt(int64(utxoEntryOverhead+len(b)) * params.UtxoCostPerByte)
+		if min.Cmp(lovelace) <= 0 {
+			return lovelace, nil
+		}
+		lovelace = min
+	}
+}
+
+// AddMinimumADAForOutput raises the ADA of v to the minimum required by an
+// output holding v, with out giving the address, datum and reference script
+// of that output.
+func (v Value) AddMinimumADAForOutput(out Output, params ProtocolParams) (Value, error) {
+	out.Value = v
+	min, err := MinimumADAForOutput(out, params)
+	if err != nil {
+		return v, err
+	}
+	if !v.Contains(ADA) || v[ADA].Cmp(min) < 0 {
+		v[ADA] = min
+	}
+	return v, nil
+}
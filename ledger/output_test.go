@@ -0,0 +1,96 @@
+package ledger
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlutusDataFromJSON(t *testing.T) {
+	assert := assert.New(t)
+	testcases := []struct {
+		json string
+		hex  string
+	}{
+		{`{"constructor": 0, "fields": []}`, "d87980"},
+		{`{"int": 42}`, "182a"},
+		{`{"int": -5}`, "24"},
+		{`{"int": 340282366920938463463374607431768211456}`, "c25101" + "00000000000000000000000000000000"},
+		{`{"bytes": "4d494e"}`, "434d494e"},
+		{`{"list": [{"int": 1}, {"int": 2}]}`, "9f0102ff"},
+		{`{"map": [{"k": {"int": 2}, "v": {"int": 1}}, {"k": {"int": 1}, "v": {"int": 2}}]}`, "a20201" + "0102"},
+		{`{"constructor": 8, "fields": [{"int": 1}]}`, "d905019f01ff"},
+		{`{"constructor": 200, "fields": []}`, "d8668218c880"},
+	}
+	for _, tc := range testcases {
+		b, err := PlutusDataFromJSON(tc.json)
+		if assert.NoError(err, tc.json) {
+			assert.Equal(tc.hex, hex.EncodeToString(b), tc.json)
+		}
+	}
+
+	long := make([]byte, 70)
+	b, err := PlutusDataFromJSON(`{"bytes": "` + hex.EncodeToString(long) + `"}`)
+	if assert.NoError(err) {
+		assert.Equal("5f5840"+hex.EncodeToString(long[:64])+"46"+hex.EncodeToString(long[64:])+"ff", hex.EncodeToString(b))
+	}
+
+	_, err = PlutusDataFromJSON(`{"constructor": 0}`)
+	assert.Error(err)
+}
+
+func TestDatumHash(t *testing.T) {
+	b, _ := PlutusDataFromJSON(`{"constructor": 0, "fields": []}`)
+	assert.Equal(t, "923918e403bf43c34b4ef6b48eb2ee04babed17320d8d1b9ff9ad086e86f44ec", DatumHash(b))
+	b, _ = PlutusDataFromJSON(`{"int": 42}`)
+	assert.Equal(t, "9e1199a988ba72ffd6e9c269cadb3b53b5f360ff99f112d9b2ee30c4d74ad88b", DatumHash(b))
+}
+
+func TestMinimumADAForOutput(t *testing.T) {
+	assert := assert.New(t)
+	params := ProtocolParams{UtxoCostPerByte: 4310}
+	const addr = "addr_test1qpmtp5t0t5y6cqkaz7rfsyrx7mld77kpvksgkwm0p7en7qum7a589n30e80tclzrrnj8qr4qvzj6al0vpgtnmrkkksnqd8upj0"
+
+	// [57-byte address, 4-byte coin] is 65 bytes
+	min, err := MinimumADAForOutput(Output{Address: addr, Value: NewValue()}, params)
+	if assert.NoError(err) {
+		assert.Equal(int64((160+65)*4310), min.Int64())
+	}
+
+	token := NewAsset("29d222ce763455e3d7a09a665ce554f00ac89d2e99a1a83d267170c6", "4d494e")
+	withToken := NewValue().Add(token, big.NewInt(1))
+	min2, err := MinimumADAForOutput(Output{Address: addr, Value: withToken}, params)
+	if assert.NoError(err) {
+		// value becomes [coin, {policy: {name: 1}}]: 1 + 5 + 1 + 30 + 1 + 4 + 1 more bytes
+		assert.Equal(int64((160+65+1+1+30+1+4+1)*4310), min2.Int64())
+	}
+
+	// a datum hash adds 34 bytes to the legacy array
+	datumHash := "923918e403bf43c34b4ef6b48eb2ee04babed17320d8d1b9ff9ad086e86f44ec"
+	minHash, err := MinimumADAForOutput(Output{Address: addr, Value: NewValue(), DatumHash: datumHash}, params)
+	if assert.NoError(err) {
+		assert.Equal(int64((160+65+34)*4310), minHash.Int64())
+	}
+
+	// {0: address, 1: coin, 2: [1, 24(h'd87980')]} is the map format
+	minInline, err := MinimumADAForOutput(Output{Address: addr, Value: NewValue(), InlineDatum: []byte{0xd8, 0x79, 0x80}}, params)
+	if assert.NoError(err) {
+		assert.Equal(int64((160+67+1+1+1+2+4)*4310), minInline.Int64())
+	}
+	min3, err := MinimumADAForOutput(Output{Address: addr, Value: withToken, InlineDatum: []byte{0xd8, 0x79, 0x80}}, params)
+	if assert.NoError(err) {
+		assert.True(min3.Cmp(min2) > 0)
+	}
+
+	val, err := NewValue().Add(ADA, big.NewInt(5_000_000)).AddMinimumADAForOutput(Output{Address: addr}, params)
+	if assert.NoError(err) {
+		assert.Equal(int64(5_000_000), val[ADA].Int64())
+	}
+	val, err = withToken.Clone().AddMinimumADAForOutput(Output{Address: addr}, params)
+	if assert.NoError(err) {
+		assert.Equal(min2, val[ADA])
+		assert.Equal(int64(1), val[token].Int64())
+	}
+}
@@ -0,0 +1,159 @@
+package ledger
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/minswap/pab-go/cbor"
+	"golang.org/x/crypto/blake2b"
+)
+
+// plutusBytesChunkSize is the maximum length of a byte string chunk in Plutus data.
+const plutusBytesChunkSize = 64
+
+// PlutusDataFromJSON encodes a datum or redeemer written in cardano-cli's
+// detailed JSON schema ({"constructor": 0, "fields": [...]}, {"int": 1},
+// {"bytes": "..."}, {"list": [...]}, {"map": [{"k": ..., "v": ...}]}) to CBOR,
+// the same way cardano-cli serializes it.
+func PlutusDataFromJSON(s string) ([]byte, error) {
+	d := json.NewDecoder(bytes.NewReader([]byte(s)))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, fmt.Errorf("fail to decode plutus data json: %w", err)
+	}
+	return appendPlutusData(nil, v)
+}
+
+// DatumHash returns the hex-encoded blake2b-256 hash of CBOR-encoded Plutus data.
+func DatumHash(datum []byte) string {
+	h := blake2b.Sum256(datum)
+	return hex.EncodeToString(h[:])
+}
+
+func appendPlutusData(dst []byte, v interface{}) ([]byte, error) {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("plutus data must be a json object, got %T", v)
+	}
+	if constr, ok := obj["constructor"]; ok {
+		n, err := jsonInteger(constr)
+		if err != nil || n.Sign() < 0 || !n.IsUint64() {
+			return nil, fmt.Errorf("invalid constructor %v", constr)
+		}
+		fields, ok := obj["fields"].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("constructor must have a fields array")
+		}
+		ix := n.Uint64()
+		switch {
+		case ix < 7:
+			dst = cbor.AppendTag(dst, 121+ix)
+		case ix < 128:
+			dst = cbor.AppendTag(dst, 1280+ix-7)
+		default:
+			dst = cbor.AppendTag(dst, 102)
+			dst = cbor.AppendArrayHeader(dst, 2)
+			dst = cbor.AppendUint(dst, ix)
+		}
+		return appendPlutusList(dst, fields)
+	}
+	if list, ok := obj["list"]; ok {
+		items, ok := list.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("list must be a json array")
+		}
+		return appendPlutusList(dst, items)
+	}
+	if m, ok := obj["map"]; ok {
+		entries, ok := m.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("map must be a json array")
+		}
+		// entries keep their order, Plutus data maps are not sorted
+		dst = cbor.AppendMapHeader(dst, len(entries))
+		for _, e := range entries {
+			entry, ok := e.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("map entry must be a json object")
+			}
+			var err error
+			if dst, err = appendPlutusData(dst, entry["k"]); err != nil {
+				return nil, err
+			}
+			if dst, err = appendPlutusData(dst, entry["v"]); err != nil {
+				return nil, err
+			}
+		}
+		return dst, nil
+	}
+	if i, ok := obj["int"]; ok {
+		n, err := jsonInteger(i)
+		if err != nil {
+			return nil, err
+		}
+		if bigIsInt64OrUint64(n) {
+			return cbor.AppendBigInt(dst, n), nil
+		}
+		// bignums use chunked byte strings like any other Plutus bytes
+		tag, abs := cbor.TagPositiveBignum, new(big.Int).Set(n)
+		if n.Sign() < 0 {
+			tag = cbor.TagNegativeBignum
+			abs.Neg(abs).Sub(abs, big.NewInt(1))
+		}
+		dst = cbor.AppendTag(dst, tag)
+		return cbor.AppendChunkedBytes(dst, abs.Bytes(), plutusBytesChunkSize), nil
+	}
+	if b, ok := obj["bytes"]; ok {
+		s, ok := b.(string)
+		if !ok {
+			return nil, fmt.Errorf("bytes must be a hex string")
+		}
+		raw, err := hex.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("bytes must be a hex string: %w", err)
+		}
+		return cbor.AppendChunkedBytes(dst, raw, plutusBytesChunkSize), nil
+	}
+	return nil, fmt.Errorf("unknown plutus data json object")
+}
+
+// appendPlutusList writes empty lists with definite length and other lists
+// with indefinite length.
+func appendPlutusList(dst []byte, items []interface{}) ([]byte, error) {
+	if len(items) == 0 {
+		return cbor.AppendArrayHeader(dst, 0), nil
+	}
+	dst = append(dst, 0x9f)
+	var err error
+	for _, item := range items {
+		if dst, err = appendPlutusData(dst, item); err != nil {
+			return nil, err
+		}
+	}
+	return append(dst, 0xff), nil
+}
+
+func jsonInteger(v interface{}) (*big.Int, error) {
+	num, ok := v.(json.Number)
+	if !ok {
+		return nil, fmt.Errorf("expect an integer, got %v", v)
+	}
+	n, ok := new(big.Int).SetString(num.String(), 10)
+	if !ok {
+		return nil, fmt.Errorf("expect an integer, got %s", num)
+	}
+	return n, nil
+}
+
+func bigIsInt64OrUint64(n *big.Int) bool {
+	if n.Sign() >= 0 {
+		return n.IsUint64()
+	}
+	abs := new(big.Int).Neg(n)
+	abs.Sub(abs, big.NewInt(1))
+	return abs.IsUint64()
+}
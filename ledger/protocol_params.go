@@ -0,0 +1,98 @@
+package ledger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// ProtocolParams holds the protocol parameters pab-go computes with, decoded
+// from the output of cardano-cli query protocol-parameters.
+type ProtocolParams struct {
+	// UtxoCostPerByte is coinsPerUTxOByte since Babbage.
+	UtxoCostPerByte int64 `json:"utxoCostPerByte"`
+	// UtxoCostPerWord is the Alonzo predecessor of UtxoCostPerByte.
+	UtxoCostPerWord int64 `json:"utxoCostPerWord"`
+	MaxValueSize    int64 `json:"maxValueSize"`
+
+	// TxFeePerByte and TxFeeFixed are minFeeA and minFeeB.
+	TxFeePerByte        int64               `json:"txFeePerByte"`
+	TxFeeFixed          int64               `json:"txFeeFixed"`
+	MaxTxSize           int64               `json:"maxTxSize"`
+	ExecutionUnitPrices ExecutionUnitPrices `json:"executionUnitPrices"`
+	// MinFeeRefScriptCostPerByte is the Conway fee of reference scripts, per byte
+	// of the first 25 KiB tier.
+	MinFeeRefScriptCostPerByte *Rational `json:"minFeeRefScriptCostPerByte"`
+
+	CollateralPercentage int64 `json:"collateralPercentage"`
+	MaxCollateralInputs  int   `json:"maxCollateralInputs"`
+
+	StakeAddressDeposit int64      `json:"stakeAddressDeposit"`
+	CostModels          CostModels `json:"costModels"`
+}
+
+type ExecutionUnitPrices struct {
+	PriceMemory *Rational `json:"priceMemory"`
+	PriceSteps  *Rational `json:"priceSteps"`
+}
+
+// Rational is an exact rational number. It decodes from a JSON number such
+// as 7.21e-05, a string such as "577/10000" or a numerator/denominator object.
+type Rational struct {
+	big.Rat
+}
+
+func NewRational(a, b int64) *Rational {
+	r := &Rational{}
+	r.SetFrac64(a, b)
+	return r
+}
+
+func (r *Rational) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '{' {
+		var frac struct {
+			Numerator   json.Number `json:"numerator"`
+			Denominator json.Number `json:"denominator"`
+		}
+		if err := json.Unmarshal(data, &frac); err != nil {
+			return fmt.Errorf("fail to decode rational: %w", err)
+		}
+		data = []byte(frac.Numerator.String() + "/" + frac.Denominator.String())
+	} else if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return fmt.Errorf("fail to decode rational: %w", err)
+		}
+		data = []byte(s)
+	}
+	if _, ok := r.SetString(string(data)); !ok {
+		return fmt.Errorf("invalid rational: %s", data)
+	}
+	return nil
+}
+
+func (r Rational) MarshalJSON() ([]byte, error) {
+	if r.IsInt() {
+		return []byte(r.Num().String()), nil
+	}
+	f, _ := r.Float64()
+	return json.Marshal(f)
+}
+
+func ReadProtocolParamsFile(path string) (*ProtocolParams, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read protocol params file: %w", err)
+	}
+	var params ProtocolParams
+	if err := json.Unmarshal(content, &params); err != nil {
+		return nil, fmt.Errorf("fail to decode protocol params: %w", err)
+	}
+	return &params, nil
+}
@@ -0,0 +1,187 @@
+package ledger
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/minswap/pab-go/cbor"
+	"golang.org/x/crypto/blake2b"
+)
+
+type ScriptLanguage byte
+
+// Script languages, numbered by the tag prepended to a script before hashing.
+const (
+	NativeScript ScriptLanguage = 0
+	PlutusV1     ScriptLanguage = 1
+	PlutusV2     ScriptLanguage = 2
+	PlutusV3     ScriptLanguage = 3
+)
+
+func (l ScriptLanguage) String() string {
+	switch l {
+	case NativeScript:
+		return "NativeScript"
+	case PlutusV1:
+		return "PlutusV1"
+	case PlutusV2:
+		return "PlutusV2"
+	case PlutusV3:
+		return "PlutusV3"
+	default:
+		return fmt.Sprintf("ScriptLanguage(%d)", byte(l))
+	}
+}
+
+// Script is a script as serialized in the ledger: the CBOR of a native script,
+// or the flat-encoded program wrapped in a CBOR byte string for Plutus scripts.
+type Script struct {
+	Language ScriptLanguage
+	Bytes    []byte
+}
+
+// Hash returns the hex-encoded blake2b-224 script hash, which is also the
+// policy ID of minting scripts.
+func (s Script) Hash() string {
+	h, _ := blake2b.New(28, nil)
+	h.Write([]byte{byte(s.Language)})
+	h.Write(s.Bytes)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type scriptFile struct {
+	Type    string `json:"type"`
+	CBORHex string `json:"cborHex"`
+
+	// Native script JSON fields
+	KeyHash  string            `json:"keyHash"`
+	Required uint64            `json:"required"`
+	Slot     uint64            `json:"slot"`
+	Scripts  []json.RawMessage `json:"scripts"`
+}
+
+// ReadScriptFile reads a Plutus script text envelope or a native script JSON
+// file as written by cardano-cli.
+func ReadScriptFile(path string) (Script, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Script{}, fmt.Errorf("fail to read script file: %w", err)
+	}
+	return ParseScriptJSON(content)
+}
+
+func ParseScriptJSON(content []byte) (Script, error) {
+	var f scriptFile
+	if err := json.Unmarshal(content, &f); err != nil {
+		return Script{}, fmt.Errorf("fail to decode script file: %w", err)
+	}
+	var lang ScriptLanguage
+	switch f.Type {
+	case "PlutusScriptV1":
+		lang = PlutusV1
+	case "PlutusScriptV2":
+		lang = PlutusV2
+	case "PlutusScriptV3":
+		lang = PlutusV3
+	case "SimpleScript":
+		b, err := hex.DecodeString(f.CBORHex)
+		if err != nil {
+			return Script{}, fmt.Errorf("fail to decode cborHex: %w", err)
+		}
+		return Script{NativeScript, b}, nil
+	default:
+		b, err := encodeNativeScript(f)
+		if err != nil {
+			return Script{}, fmt.Errorf("fail to encode native script: %w", err)
+		}
+		return Script{NativeScript, b}, nil
+	}
+	envelope, err := hex.DecodeString(f.CBORHex)
+	if err != nil {
+		return Script{}, fmt.Errorf("fail to decode cborHex: %w", err)
+	}
+	// the text envelope wraps the ledger bytes in one more CBOR byte string
+	v, err := cbor.Unmarshal(envelope)
+	if err != nil {
+		return Script{}, fmt.Errorf("fail to decode plutus script envelope: %w", err)
+	}
+	b, ok := v.([]byte)
+	if !ok {
+		return Script{}, fmt.Errorf("plutus script envelope must be a byte string")
+	}
+	return Script{lang, b}, nil
+}
+
+func encodeNativeScript(f scriptFile) ([]byte, error) {
+	v, err := nativeScriptTerm(f)
+	if err != nil {
+		return nil, err
+	}
+	return cbor.Marshal(v)
+}
+
+func nativeScriptTerm(f scriptFile) (interface{}, error) {
+	subScripts := func() ([]interface{}, error) {
+		terms := make([]interface{}, 0, len(f.Scripts))
+		for _, raw := range f.Scripts {
+			var sub scriptFile
+			if err := json.Unmarshal(raw, &sub); err != nil {
+				return nil, err
+			}
+			term, err := nativeScriptTerm(sub)
+			if err != nil {
+				return nil, err
+			}
+			terms = append(terms, term)
+		}
+		return terms, nil
+	}
+	switch f.Type {
+	case "sig":
+		keyHash, err := hex.DecodeString(f.KeyHash)
+		if err != nil || len(keyHash) != 28 {
+			return nil, fmt.Errorf("invalid key hash %q", f.KeyHash)
+		}
+		return []interface{}{uint64(0), keyHash}, nil
+	case "all", "any":
+		scripts, err := subScripts()
+		if err != nil {
+			return nil, err
+		}
+		tag := uint64(1)
+		if f.Type == "any" {
+			tag = 2
+		}
+		return []interface{}{tag, scripts}, nil
+	case "atLeast":
+		scripts, err := subScripts()
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{uint64(3), f.Required, scripts}, nil
+	case "after":
+		return []interface{}{uint64(4), f.Slot}, nil
+	case "before":
+		return []interface{}{uint64(5), f.Slot}, nil
+	default:
+		return nil, fmt.Errorf("unknown script type %q", f.Type)
+	}
+}
+
+// KeyHash returns the hex-encoded blake2b-224 hash of a verification key.
+func KeyHash(vkey []byte) string {
+	h, _ := blake2b.New(28, nil)
+	h.Write(vkey)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// MarshalCBOR encodes the script as [language, script], the form used by
+// reference scripts.
+func (s Script) MarshalCBOR() ([]byte, error) {
+	if s.Language == NativeScript {
+		return cbor.Marshal([]interface{}{uint64(s.Language), cbor.RawMessage(s.Bytes)})
+	}
+	return cbor.Marshal([]interface{}{uint64(s.Language), s.Bytes})
+}
@@ -0,0 +1,83 @@
+package ledger
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/minswap/pab-go/cbor"
+	"golang.org/x/crypto/blake2b"
+)
+
+// LanguageViews encodes the cost models of langs as hashed by the script
+// data hash. PlutusV2 and later views map the language ID to the list of
+// parameters. PlutusV1 keeps the legacy Alonzo encoding: its key is the
+// language ID serialized twice and its value the serialized indefinite
+// list of parameters wrapped in a byte string.
+func LanguageViews(langs []ScriptLanguage, costModels CostModels) ([]byte, error) {
+	seen := make(map[ScriptLanguage]struct{})
+	views := cbor.Map{}
+	for _, lang := range langs {
+		if _, ok := seen[lang]; ok {
+			continue
+		}
+		seen[lang] = struct{}{}
+		if lang == NativeScript {
+			continue
+		}
+		model, ok := costModels[lang]
+		if !ok {
+			return nil, fmt.Errorf("missing %s cost model", lang)
+		}
+		id := uint64(lang - PlutusV1)
+		if lang == PlutusV1 {
+			params := make(cbor.IndefiniteArray, len(model))
+			for i, p := range model {
+				params[i] = p
+			}
+			b, err := cbor.Marshal(params)
+			if err != nil {
+				return nil, err
+			}
+			views = append(views, cbor.MapEntry{Key: cbor.AppendUint(nil, id), Value: b})
+			continue
+		}
+		params := make([]interface{}, len(model))
+		for i, p := range model {
+			params[i] = p
+		}
+		views = append(views, cbor.MapEntry{Key: id, Value: params})
+	}
+	return cbor.Marshal(views)
+}
+
+// ScriptDataHash returns the hex-encoded script integrity hash of a
+// transaction: blake2b-256 of its redeemers, its witness datums when it has
+// any, and the language views of langs. redeemers and datums are the CBOR of
+// the witness set entries; nil redeemers stand for none, encoded as the empty
+// redeemers of era: an empty list before Conway, an empty map since.
+func ScriptDataHash(era string, redeemers, datums []byte, langs []ScriptLanguage, costModels CostModels) (string, error) {
+	sorted := append([]ScriptLanguage{}, langs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	views, err := LanguageViews(sorted, costModels)
+	if err != nil {
+		return "", err
+	}
+	var preimage []byte
+	if redeemers == nil {
+		switch era {
+		case "Alonzo", "Babbage":
+			preimage = cbor.AppendArrayHeader(preimage, 0)
+		case "Conway":
+			preimage = cbor.AppendMapHeader(preimage, 0)
+		default:
+			return "", fmt.Errorf("unsupported era %q", era)
+		}
+	} else {
+		preimage = append(preimage, redeemers...)
+	}
+	preimage = append(preimage, datums...)
+	preimage = append(preimage, views...)
+	hash := blake2b.Sum256(preimage)
+	return hex.EncodeToString(hash[:]), nil
+}
@@ -0,0 +1,138 @@
+package ledger
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLanguageViews(t *testing.T) {
+	models := CostModels{PlutusV1: {1, -1, 100000}, PlutusV2: {1, -1, 100000}}
+	views, err := LanguageViews([]ScriptLanguage{PlutusV1, PlutusV2}, models)
+	assert.NoError(t, err)
+	// the 1-byte PlutusV2 key sorts before the legacy PlutusV1 key h'00'
+	assert.Equal(t, "a2018301201a000186a04100499f01201a000186a0ff", hex.EncodeToString(views))
+
+	_, err = LanguageViews([]ScriptLanguage{PlutusV3}, models)
+	assert.Error(t, err)
+}
+
+func TestScriptDataHash(t *testing.T) {
+	redeemers, _ := hex.DecodeString("81840000d87980821903e81907d0")
+	datums, _ := hex.DecodeString("81d87980")
+	models := CostModels{PlutusV1: {1, -1, 100000}, PlutusV2: {1, -1, 100000}, PlutusV3: {1, -1, 100000}}
+
+	// blake2b-256 of redeemers || datums || a1 4100 49 9f01201a000186a0ff
+	hash, err := ScriptDataHash("Babbage", redeemers, datums, []ScriptLanguage{PlutusV1}, models)
+	assert.NoError(t, err)
+	assert.Equal(t, "ac8385e6f71a61088e6244b88a34951868c77cbe50448b0751dfa5ff5c9ceb50", hash)
+
+	// redeemers || a2 01 8301201a000186a0 4100 49 9f01201a000186a0ff
+	hash, err = ScriptDataHash("Conway", redeemers, nil, []ScriptLanguage{PlutusV2, PlutusV1, NativeScript}, models)
+	assert.NoError(t, err)
+	assert.Equal(t, "786c40ddccb5295628e79a45e0f5929a7d09dd8498d6831957434a9863f08e60", hash)
+
+	// redeemers || a1 02 8301201a000186a0
+	hash, err = ScriptDataHash("Conway", redeemers, nil, []ScriptLanguage{PlutusV3}, models)
+	assert.NoError(t, err)
+	assert.Equal(t, "a9579268a21bab64c6a8aae8a4e9fec6c7459f170defdcb229d63b59153269af", hash)
+
+	// datums only: a0 || datums || a0 in Conway, 80 || datums || a0 before
+	hash, err = ScriptDataHash("Conway", nil, datums, nil, models)
+	assert.NoError(t, err)
+	assert.Equal(t, "244926529564c04ffdea89005076a6b6aac5e4a2f38182cd48bfbc734b3be296", hash)
+
+	hash, err = ScriptDataHash("Babbage", nil, datums, nil, models)
+	assert.NoError(t, err)
+	assert.Equal(t, "2f50ea2546f8ce020ca45bfcf2abeb02ff18af2283466f888ae489184b3d2d39", hash)
+
+	_, err = ScriptDataHash("Mary", nil, datums, nil, models)
+	assert.Error(t, err)
+}
+
+func readCostModels(t *testing.T, name string) CostModels {
+	content, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var models CostModels
+	if err := json.Unmarshal(content, &models); err != nil {
+		t.Fatal(err)
+	}
+	return models
+}
+
+// The script data hashes below are the ones in the bodies of mainnet
+// transactions, computed from the redeemers and datums of their witness
+// sets. cost_models_alonzo.json is the PlutusV1 model of the Alonzo genesis
+// and cost_models_conway.json the PlutusV2 model in effect at the Conway
+// transaction. No PlutusV3 transaction is covered yet.
+func TestScriptDataHashMainnet(t *testing.T) {
+	testcases := []struct {
+		tx         string
+		era        string
+		redeemers  string
+		datums     string
+		langs      []ScriptLanguage
+		costModels string
+		hash       string
+	}{
+		{
+			tx:         "96adca1dc65edb4903bf970843c4a8f3aff5be5e248994ad402463483a0fb4e7",
+			era:        "Alonzo",
+			redeemers:  "81840000d87a80821a003c19aa1a541fdb09",
+			datums:     "9fd8799f581c2605b6d3b67730dc13f393861841ee3c6b751531558a94cc05c54ee49fd8799fd8799fd8799f581cec1b21968ba227e7a0bff663efe7d160416508cb3042524b91031625ffd8799fd8799fd8799f581c3573abf694a555b81aeee68f61f3f91ed5a18bf7eeeb43ac1900c9abffffffffa140d8799f00a1401a002625a0ffffd8799fd8799fd8799f581c70e60f3b5ea7153e0acc7a803e4401d44b8ed1bae1c7baaad1a62a72ffd8799fd8799fd8799f581c1e78aae7c90cc36d624f7b3bb6d86b52696dc84e490f343eba89005fffffffffa140d8799f00a1401a000f4240ffffd8799fd8799fd8799f581c2605b6d3b67730dc13f393861841ee3c6b751531558a94cc05c54ee4ffd8799fd8799fd8799f581c183f69a0fd8d581f6e0cf57fd8b856cd856b5d82758183c9fc22350cffffffffa140d8799f00a1401a02c588a0ffffffffff",
+			langs:      []ScriptLanguage{PlutusV1},
+			costModels: "cost_models_alonzo.json",
+			hash:       "3ba6872fbd19dff7b0d46f5d2848e14ac4c302b339bbdd86f18380e429a98f97",
+		},
+		{
+			tx:         "797d1e3c7b6da7e9f8baeb4122813f5c7e7b4391427356a614e1c31e2ebe3d8f",
+			era:        "Conway",
+			redeemers:  "82840001d8799f02ff821982a41a00868b7d840002d8799f02009fd8799f0100ffffff821a000f5c0f1a133dd2d8",
+			langs:      []ScriptLanguage{PlutusV2},
+			costModels: "cost_models_conway.json",
+			hash:       "4678656214f6d284b1f69831aff2886e4ab3e4927a4e457714015f67f0a9bf7b",
+		},
+		{
+			// datums only, the empty redeemers are 0x80 in Babbage
+			tx:         "ee5500bfb93f70692048a1be658408dd77f398b80f8698a49742e48c6b60b297",
+			era:        "Babbage",
+			datums:     "9fd8799f42df02d8799fd8799fd8799fd8799f581c206a54629f155d28a5db2cfd7f337b852cfcc1c4c52e8681282eb4e6ffd8799fd8799fd8799f581c650e633f78334f94681806c9f56e9d9c9faa40e22853ff4f9133662dffffffffd87a80ffd87a80ff1a002625a0d8799fd879801a1d663469d8799f1b0000004de1043a2fffffffff",
+			costModels: "cost_models_conway.json",
+			hash:       "ea824d166eaee5f622181e2eb41beaed21183f5b91d4cf2527025b3743ebcbdd",
+		},
+	}
+	for _, tc := range testcases {
+		var redeemers, datums []byte
+		if tc.redeemers != "" {
+			redeemers, _ = hex.DecodeString(tc.redeemers)
+		}
+		if tc.datums != "" {
+			datums, _ = hex.DecodeString(tc.datums)
+		}
+		hash, err := ScriptDataHash(tc.era, redeemers, datums, tc.langs, readCostModels(t, tc.costModels))
+		if assert.NoError(t, err, tc.tx) {
+			assert.Equal(t, tc.hash, hash, tc.tx)
+		}
+	}
+}
+
+func TestCostModelsJSON(t *testing.T) {
+	var params ProtocolParams
+	err := json.Unmarshal([]byte(`{"costModels": {
+		"PlutusV1": {"b-param": 2, "a-param": 1},
+		"PlutusV2": [3, 4, -5]
+	}}`), &params)
+	assert.NoError(t, err)
+	assert.Equal(t, CostModels{PlutusV1: {1, 2}, PlutusV2: {3, 4, -5}}, params.CostModels)
+
+	// only PlutusV1 parameters are in ledger order by name
+	var models CostModels
+	assert.Error(t, json.Unmarshal([]byte(`{"PlutusV2": {"b-param": 2, "a-param": 1}}`), &models))
+	assert.Error(t, json.Unmarshal([]byte(`{"PlutusV3": {"b-param": 2, "a-param": 1}}`), &models))
+}
@@ -0,0 +1,33 @@
+package ledger
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseScriptJSON(t *testing.T) {
+	assert := assert.New(t)
+	s, err := ParseScriptJSON([]byte(`{"type": "PlutusScriptV1", "description": "", "cborHex": "4e4d01000033222220051200120011"}`))
+	if assert.NoError(err) {
+		assert.Equal(PlutusV1, s.Language)
+		assert.Equal("67f33146617a5e61936081db3b2117cbf59bd2123748f58ac9678656", s.Hash())
+	}
+
+	s, err = ParseScriptJSON([]byte(`{
+		"type": "atLeast",
+		"required": 1,
+		"scripts": [
+			{"type": "sig", "keyHash": "9493315cd92eb5d8c4304e67b7e16ae36d61d34502694657811a2c8e"},
+			{"type": "before", "slot": 1000}
+		]
+	}`))
+	if assert.NoError(err) {
+		assert.Equal(NativeScript, s.Language)
+		assert.Equal("830301828200581c9493315cd92eb5d8c4304e67b7e16ae36d61d34502694657811a2c8e82051903e8", hex.EncodeToString(s.Bytes))
+	}
+
+	_, err = ParseScriptJSON([]byte(`{"type": "sig", "keyHash": "abcd"}`))
+	assert.Error(err)
+}
@@ -0,0 +1,122 @@
+package ledger
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// TokenMetadata is the display metadata of a token.
+type TokenMetadata struct {
+	Asset    Asset
+	Name     string
+	Ticker   string
+	Decimals int
+}
+
+// TokenRegistry looks up token metadata by asset or ticker.
+type TokenRegistry struct {
+	byAsset  map[Asset]TokenMetadata
+	byTicker map[string]TokenMetadata
+}
+
+func NewTokenRegistry(tokens ...TokenMetadata) *TokenRegistry {
+	r := &TokenRegistry{
+		byAsset:  make(map[Asset]TokenMetadata),
+		byTicker: make(map[string]TokenMetadata),
+	}
+	for _, t := range tokens {
+		r.Add(t)
+	}
+	return r
+}
+
+func (r *TokenRegistry) Add(t TokenMetadata) {
+	r.byAsset[t.Asset] = t
+	if t.Ticker != "" {
+		r.byTicker[strings.ToUpper(t.Ticker)] = t
+	}
+}
+
+func (r *TokenRegistry) Lookup(asset Asset) (TokenMetadata, bool) {
+	if r == nil {
+		return TokenMetadata{}, false
+	}
+	t, ok := r.byAsset[asset]
+	return t, ok
+}
+
+// LookupTicker finds a token by its case-insensitive ticker.
+func (r *TokenRegistry) LookupTicker(ticker string) (TokenMetadata, bool) {
+	if r == nil {
+		return TokenMetadata{}, false
+	}
+	t, ok := r.byTicker[strings.ToUpper(ticker)]
+	return t, ok
+}
+
+// registryProperty is a property of a token registry entry, either wrapped
+// in {"value": ...} as in the Cardano token registry or plain.
+type registryProperty struct {
+	Value json.RawMessage
+}
+
+func (p *registryProperty) UnmarshalJSON(data []byte) error {
+	var wrapped struct {
+		Value json.RawMessage `json:"value"`
+	}
+	if len(data) > 0 && data[0] == '{' {
+		if err := json.Unmarshal(data, &wrapped); err != nil {
+			return err
+		}
+		p.Value = wrapped.Value
+		return nil
+	}
+	p.Value = data
+	return nil
+}
+
+type registryEntry struct {
+	Subject  string           `json:"subject"`
+	Name     registryProperty `json:"name"`
+	Ticker   registryProperty `json:"ticker"`
+	Decimals registryProperty `json:"decimals"`
+}
+
+// ReadTokenRegistryFile reads a JSON list of Cardano token registry entries,
+// whose subject is the policy ID followed by the hex token name.
+func ReadTokenRegistryFile(path string) (*TokenRegistry, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read token registry file: %w", err)
+	}
+	var entries []registryEntry
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return nil, fmt.Errorf("fail to decode token registry: %w", err)
+	}
+	r := NewTokenRegistry()
+	for _, e := range entries {
+		if len(e.Subject) < policyLength*2 {
+			return nil, fmt.Errorf("invalid token registry subject: %s", e.Subject)
+		}
+		t := TokenMetadata{Asset: NewAsset(e.Subject[:policyLength*2], e.Subject[policyLength*2:])}
+		if e.Name.Value != nil {
+			if err := json.Unmarshal(e.Name.Value, &t.Name); err != nil {
+				return nil, fmt.Errorf("invalid name of %s: %w", e.Subject, err)
+			}
+		}
+		if e.Ticker.Value != nil {
+			if err := json.Unmarshal(e.Ticker.Value, &t.Ticker); err != nil {
+				return nil, fmt.Errorf("invalid ticker of %s: %w", e.Subject, err)
+			}
+		}
+		if e.Decimals.Value != nil {
+			if err := json.Unmarshal(e.Decimals.Value, &t.Decimals); err != nil {
+				return nil, fmt.Errorf("invalid decimals of %s: %w", e.Subject, err)
+			}
+		}
+		r.Add(t)
+	}
+	return r, nil
+}
@@ -8,11 +8,16 @@ type Utxo struct {
 	Address   string  `json:"address"`
 	Value     Value   `json:"value"`
 	DatumHash *string `json:"datumHash"`
+	// ReferenceScript is the script held by the output, when known.
+	ReferenceScript *Script `json:"referenceScript,omitempty"`
 }
 
 var ErrNoCollateral = errors.New("no suitable collateral")
 
 // FindCollateral find the biggest only-ADA UTxO
+//
+// Deprecated: it locks the biggest UTxO as collateral of every script
+// transaction. Use coinselection.SelectCollateral.
 func FindCollateral(utxos []Utxo) (Utxo, error) {
 	var ret Utxo
 	found := false
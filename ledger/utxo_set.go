@@ -0,0 +1,123 @@
+package ledger
+
+import "sort"
+
+// UtxoSet is a set of UTxOs keyed by their OutRef. Methods returning a
+// UtxoSet return a new set and leave the receiver unchanged.
+type UtxoSet map[OutRef]Utxo
+
+func NewUtxoSet(utxos ...Utxo) UtxoSet {
+	s := make(UtxoSet, len(utxos))
+	s.Add(utxos...)
+	return s
+}
+
+// Add adds utxos to s, replacing the ones with the same OutRef.
+func (s UtxoSet) Add(utxos ...Utxo) {
+	for _, u := range utxos {
+		s[u.OutRef()] = u
+	}
+}
+
+func (s UtxoSet) Get(ref OutRef) (Utxo, bool) {
+	u, ok := s[ref]
+	return u, ok
+}
+
+func (s UtxoSet) Contains(ref OutRef) bool {
+	_, ok := s[ref]
+	return ok
+}
+
+// Refs returns the OutRefs of s in input order.
+func (s UtxoSet) Refs() []OutRef {
+	refs := make([]OutRef, 0, len(s))
+	for ref := range s {
+		refs = append(refs, ref)
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].Cmp(refs[j]) < 0 })
+	return refs
+}
+
+// Utxos returns the UTxOs of s sorted by OutRef.
+func (s UtxoSet) Utxos() []Utxo {
+	utxos := make([]Utxo, 0, len(s))
+	for _, ref := range s.Refs() {
+		utxos = append(utxos, s[ref])
+	}
+	return utxos
+}
+
+func (s UtxoSet) Filter(keep func(u Utxo) bool) UtxoSet {
+	ret := make(UtxoSet)
+	for ref, u := range s {
+		if keep(u) {
+			ret[ref] = u
+		}
+	}
+	return ret
+}
+
+// FilterByAsset returns the UTxOs holding asset.
+func (s UtxoSet) FilterByAsset(asset Asset) UtxoSet {
+	return s.Filter(func(u Utxo) bool {
+		return u.Value.Contains(asset)
+	})
+}
+
+// FilterByPolicy returns the UTxOs holding an asset of the policy.
+func (s UtxoSet) FilterByPolicy(currencySymbol string) UtxoSet {
+	return s.Filter(func(u Utxo) bool {
+		return len(u.Value.FilterByPolicy(currencySymbol)) > 0
+	})
+}
+
+// Without returns s without the given refs, e.g. the ones reserved by
+// pending transactions.
+func (s UtxoSet) Without(refs ...OutRef) UtxoSet {
+	excluded := make(map[OutRef]struct{}, len(refs))
+	for _, ref := range refs {
+		excluded[ref] = struct{}{}
+	}
+	return s.Filter(func(u Utxo) bool {
+		_, ok := excluded[u.OutRef()]
+		return !ok
+	})
+}
+
+func (s UtxoSet) GroupByAddress() map[string]UtxoSet {
+	groups := make(map[string]UtxoSet)
+	for ref, u := range s {
+		if _, ok := groups[u.Address]; !ok {
+			groups[u.Address] = make(UtxoSet)
+		}
+		groups[u.Address][ref] = u
+	}
+	return groups
+}
+
+// Merge returns the union of s and others, later sets winning on conflicts.
+func (s UtxoSet) Merge(others ...UtxoSet) UtxoSet {
+	ret := make(UtxoSet, len(s))
+	for ref, u := range s {
+		ret[ref] = u
+	}
+	for _, o := range others {
+		for ref, u := range o {
+			ret[ref] = u
+		}
+	}
+	return ret
+}
+
+// Diff returns the UTxOs of s missing from o.
+func (s UtxoSet) Diff(o UtxoSet) UtxoSet {
+	return s.Filter(func(u Utxo) bool {
+		return !o.Contains(u.OutRef())
+	})
+}
+
+// Total returns the sum of the values of s.
+func (s UtxoSet) Total() Value {
+	return SumValueOfUtxos(s.Utxos())
+}
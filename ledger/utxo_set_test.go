@@ -0,0 +1,40 @@
+package ledger
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUtxoSet(t *testing.T) {
+	min := NewAsset("29d222ce763455e3d7a09a665ce554f00ac89d2e99a1a83d267170c6", "4d494e")
+	addr1 := "addr_test1vz1"
+	addr2 := "addr_test1vz2"
+	u1 := Utxo{TxID: "bb", TxIndex: 0, Address: addr1, Value: NewValue().Add(ADA, big.NewInt(1))}
+	u2 := Utxo{TxID: "aa", TxIndex: 10, Address: addr1, Value: NewValue().Add(ADA, big.NewInt(2)).Add(min, big.NewInt(5))}
+	u3 := Utxo{TxID: "aa", TxIndex: 2, Address: addr2, Value: NewValue().Add(ADA, big.NewInt(4))}
+	s := NewUtxoSet(u1, u2, u3)
+
+	assert.Equal(t, []OutRef{{"aa", 2}, {"aa", 10}, {"bb", 0}}, s.Refs())
+	assert.Equal(t, []Utxo{u3, u2, u1}, s.Utxos())
+	got, ok := s.Get(OutRef{"aa", 10})
+	assert.True(t, ok)
+	assert.Equal(t, u2, got)
+
+	assert.Equal(t, NewUtxoSet(u2), s.FilterByAsset(min))
+	assert.Equal(t, NewUtxoSet(u2), s.FilterByPolicy(min.CurrencySymbol))
+	assert.Equal(t, NewUtxoSet(u1, u3), s.Without(u2.OutRef()))
+	groups := s.GroupByAddress()
+	assert.Equal(t, NewUtxoSet(u1, u2), groups[addr1])
+	assert.Equal(t, NewUtxoSet(u3), groups[addr2])
+
+	assert.Equal(t, s, NewUtxoSet(u1).Merge(NewUtxoSet(u2), NewUtxoSet(u3)))
+	assert.Equal(t, NewUtxoSet(u1), s.Diff(NewUtxoSet(u2, u3)))
+	assert.True(t, s.Total().Equal(NewValue().Add(ADA, big.NewInt(7)).Add(min, big.NewInt(5))))
+
+	ref, err := ParseOutRef("aa#10")
+	assert.NoError(t, err)
+	assert.Equal(t, u2.OutRef(), ref)
+	assert.Equal(t, "aa#10", ref.String())
+}
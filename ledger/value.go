@@ -1,7 +1,10 @@
 package ledger
 
 import (
+	"errors"
+	"fmt"
 	"math/big"
+	"sort"
 )
 
 const (
@@ -49,8 +52,20 @@ func (v Value) Add(asset Asset, amount *big.Int) Value {
 	return v
 }
 
+// AddMinimumADA raises the ADA of v to the minimum required since Babbage by
+// an output holding v at a base address, with a datum hash if isScriptUtxo:
+// (160 + output size) * 4310, the mainnet coinsPerUTxOByte. When v cannot be
+// serialized, e.g. it holds a negative amount, the Alonzo MinimumADA is used.
+// Use AddMinimumADAForOutput when the output and protocol params are known.
 func (v Value) AddMinimumADA(isScriptUtxo bool) Value {
-	min := v.MinimumADA(isScriptUtxo)
+	out := Output{Address: placeholderBaseAddress, Value: v}
+	if isScriptUtxo {
+		out.DatumHash = placeholderDatumHash
+	}
+	min, err := MinimumADAForOutput(out, ProtocolParams{UtxoCostPerByte: mainnetUtxoCostPerByte})
+	if err != nil {
+		min = v.MinimumADA(isScriptUtxo)
+	}
 	if !v.Contains(ADA) || v[ADA].Cmp(min) < 0 {
 		v[ADA] = min
 	}
@@ -83,6 +98,92 @@ func (v Value) RemoveAll(w Value) Value {
 	return v
 }
 
+var ErrNegativeValue = errors.New("value would be negative")
+
+// Sub returns v minus w, or ErrNegativeValue if w holds more of an asset
+// than v. Unlike RemoveAll, v is not modified.
+func (v Value) Sub(w Value) (Value, error) {
+	ret := v.Clone()
+	for _, asset := range w.Assets() {
+		amount := w[asset]
+		if amount.Cmp(amountOf(ret, asset)) > 0 {
+			return nil, fmt.Errorf("%w: %s of %s is more than %s", ErrNegativeValue, amount, asset, amountOf(ret, asset))
+		}
+		ret.Add(asset, new(big.Int).Neg(amount))
+	}
+	return ret, nil
+}
+
+// Negate returns -v as a MintValue, burning every asset of v.
+func (v Value) Negate() MintValue {
+	return NewMintValue().Burn(v)
+}
+
+func (v Value) IsZero() bool {
+	for _, amount := range v {
+		if amount.Sign() != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal reports whether v and w hold the same amounts, assets with zero
+// amount being the same as missing ones.
+func (v Value) Equal(w Value) bool {
+	return v.Geq(w) && w.Geq(v)
+}
+
+// Geq reports whether v holds at least every amount of w.
+func (v Value) Geq(w Value) bool {
+	for asset, amount := range w {
+		if amountOf(v, asset).Cmp(amount) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Leq reports whether w holds at least every amount of v.
+func (v Value) Leq(w Value) bool {
+	return w.Geq(v)
+}
+
+// FilterByPolicy returns the assets of v under the given policy ID.
+func (v Value) FilterByPolicy(currencySymbol string) Value {
+	ret := NewValue()
+	for asset, amount := range v {
+		if asset.CurrencySymbol == currencySymbol {
+			ret.Add(asset, amount)
+		}
+	}
+	return ret
+}
+
+// Split returns the lovelace of v and its native tokens.
+func (v Value) Split() (*big.Int, Value) {
+	tokens := v.Clone().RemoveAsset(ADA)
+	return new(big.Int).Set(amountOf(v, ADA)), tokens
+}
+
+// Scale returns v with every amount multiplied by k. k is unsigned since a
+// Value cannot hold negative amounts.
+func (v Value) Scale(k uint64) Value {
+	ret := NewValue()
+	factor := new(big.Int).SetUint64(k)
+	for asset, amount := range v {
+		ret.Add(asset, new(big.Int).Mul(amount, factor))
+	}
+	return ret
+}
+
+func amountOf(v map[Asset]*big.Int, asset Asset) *big.Int {
+	if amount, ok := v[asset]; ok {
+		return amount
+	}
+	return big.NewInt(0)
+}
+
 func (v Value) RemoveAsset(c Asset) Value {
 	delete(v, c)
 	return v
@@ -97,14 +198,34 @@ func (v Value) HasOnlyADA() bool {
 	return len(v) == 1 && v.Contains(ADA)
 }
 
+// Assets returns the assets of v sorted by policy ID then token name, ADA first.
 func (v Value) Assets() []Asset {
 	keys := make([]Asset, 0, len(v))
 	for a := range v {
 		keys = append(keys, a)
 	}
+	SortAssets(keys)
 	return keys
 }
 
+// ForEach calls fn for each asset of v in the order of Assets.
+func (v Value) ForEach(fn func(asset Asset, amount *big.Int)) {
+	for _, asset := range v.Assets() {
+		fn(asset, v[asset])
+	}
+}
+
+func SortAssets(assets []Asset) {
+	sort.Slice(assets, func(i, j int) bool {
+		return assets[i].Cmp(assets[j]) < 0
+	})
+}
+
+// MinimumADA returns the minimum ADA of an output holding val under the
+// Alonzo rules.
+//
+// Deprecated: outputs are sized in bytes since Babbage. Use
+// MinimumADAForOutput.
 func (val Value) MinimumADA(isScriptUtxo bool) *big.Int {
 	newVal := val.Clone()
 	newVal.RemoveAsset(ADA)
@@ -0,0 +1,49 @@
+package ledger
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/minswap/pab-go/cbor"
+)
+
+// MarshalCBOR encodes v as the ledger does: a plain coin when v only holds ADA,
+// [coin, multiasset] otherwise.
+func (v Value) MarshalCBOR() ([]byte, error) {
+	coin := uint64(0)
+	multiAsset := make(map[string]cbor.Map)
+	var policies []string
+	for _, asset := range v.Assets() {
+		amount := v[asset]
+		if amount.Sign() < 0 || !amount.IsUint64() {
+			return nil, fmt.Errorf("amount of %s out of range: %s", asset, amount)
+		}
+		if asset == ADA {
+			coin = amount.Uint64()
+			continue
+		}
+		if amount.Sign() == 0 {
+			continue
+		}
+		tokenName, err := hex.DecodeString(asset.TokenName)
+		if err != nil {
+			return nil, fmt.Errorf("token name of %s is not hex: %w", asset, err)
+		}
+		if _, ok := multiAsset[asset.CurrencySymbol]; !ok {
+			policies = append(policies, asset.CurrencySymbol)
+		}
+		multiAsset[asset.CurrencySymbol] = append(multiAsset[asset.CurrencySymbol], cbor.MapEntry{Key: tokenName, Value: amount.Uint64()})
+	}
+	if len(multiAsset) == 0 {
+		return cbor.Marshal(coin)
+	}
+	policyMap := make(cbor.Map, 0, len(policies))
+	for _, policy := range policies {
+		policyID, err := hex.DecodeString(policy)
+		if err != nil {
+			return nil, fmt.Errorf("policy ID %s is not hex: %w", policy, err)
+		}
+		policyMap = append(policyMap, cbor.MapEntry{Key: policyID, Value: multiAsset[policy]})
+	}
+	return cbor.Marshal([]interface{}{coin, policyMap})
+}
@@ -0,0 +1,133 @@
+package ledger
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const adaDecimals = 6
+
+// Format renders v for humans: ADA with six decimals, tokens by ticker and
+// decimals when registry knows them, otherwise by policy ID and token name,
+// decoded when it is printable UTF-8. registry may be nil.
+// Example: 12.500000 ADA + 100.5 MIN + 3 e4214b7cce62ac6fbba385d164df48e157eae5863521b4b67ca71d86.LP
+func (v Value) Format(registry *TokenRegistry) string {
+	var parts []string
+	v.ForEach(func(asset Asset, amount *big.Int) {
+		if asset == ADA {
+			parts = append(parts, formatDecimals(amount, adaDecimals)+" ADA")
+			return
+		}
+		if t, ok := registry.Lookup(asset); ok && t.Ticker != "" {
+			parts = append(parts, formatDecimals(amount, t.Decimals)+" "+t.Ticker)
+			return
+		}
+		name := asset.CurrencySymbol
+		if asset.TokenName != "" {
+			name += "." + displayTokenName(asset.TokenName)
+		}
+		decimals := 0
+		if t, ok := registry.Lookup(asset); ok {
+			decimals = t.Decimals
+		}
+		parts = append(parts, formatDecimals(amount, decimals)+" "+name)
+	})
+	if len(parts) == 0 {
+		return "0 ADA"
+	}
+	return strings.Join(parts, " + ")
+}
+
+func (v Value) String() string {
+	return v.Format(nil)
+}
+
+func displayTokenName(tokenName string) string {
+	b, err := hex.DecodeString(tokenName)
+	if err != nil || !utf8.Valid(b) {
+		return tokenName
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) {
+			return tokenName
+		}
+	}
+	return string(b)
+}
+
+func formatDecimals(amount *big.Int, decimals int) string {
+	if decimals <= 0 {
+		return amount.String()
+	}
+	s := new(big.Int).Abs(amount).String()
+	if len(s) <= decimals {
+		s = strings.Repeat("0", decimals-len(s)+1) + s
+	}
+	s = s[:len(s)-decimals] + "." + s[len(s)-decimals:]
+	if amount.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+func parseDecimals(s string, decimals int) (*big.Int, error) {
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+	}
+	if len(frac) > decimals {
+		return nil, fmt.Errorf("%s has more than %d decimals", s, decimals)
+	}
+	if whole == "" || strings.ContainsAny(whole+frac, "+-") {
+		return nil, fmt.Errorf("invalid amount: %s", s)
+	}
+	amount, ok := new(big.Int).SetString(whole+frac+strings.Repeat("0", decimals-len(frac)), 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount: %s", s)
+	}
+	return amount, nil
+}
+
+// ParseValue parses a human-readable value such as "12.5 ADA + 100 MIN".
+// Units are ADA, lovelace, tickers of registry, or policy ID and hex token
+// name joined by a dot. registry may be nil.
+func ParseValue(s string, registry *TokenRegistry) (Value, error) {
+	val := NewValue()
+	for _, part := range strings.Split(s, "+") {
+		fields := strings.Fields(part)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("expect <amount> <unit>, got %q", strings.TrimSpace(part))
+		}
+		amountStr, unit := fields[0], fields[1]
+		var asset Asset
+		decimals := 0
+		switch {
+		case strings.EqualFold(unit, "ADA"):
+			asset, decimals = ADA, adaDecimals
+		case unit == "lovelace":
+			asset = ADA
+		default:
+			if t, ok := registry.LookupTicker(unit); ok {
+				asset, decimals = t.Asset, t.Decimals
+				break
+			}
+			var err error
+			if asset, err = AssetFromString(unit); err != nil {
+				return nil, fmt.Errorf("unknown unit %s: %w", unit, err)
+			}
+			if t, ok := registry.Lookup(asset); ok {
+				decimals = t.Decimals
+			}
+		}
+		amount, err := parseDecimals(amountStr, decimals)
+		if err != nil {
+			return nil, err
+		}
+		val.Add(asset, amount)
+	}
+	return val, nil
+}
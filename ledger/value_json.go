@@ -1,7 +1,9 @@
 package ledger
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"math/big"
 )
 
@@ -12,21 +14,28 @@ type ValueJSONItem struct {
 
 type ValueJSON []ValueJSONItem
 
+// MarshalJSON encodes v as a list of ValueJSONItem. Convert v to NestedValue
+// for the cardano-cli format.
 func (v Value) MarshalJSON() ([]byte, error) {
 	if v == nil {
 		return []byte("null"), nil
 	}
-	arr := make(ValueJSON, 0)
-	for asset, amount := range v {
+	arr := make(ValueJSON, 0, len(v))
+	v.ForEach(func(asset Asset, amount *big.Int) {
 		arr = append(arr, ValueJSONItem{asset, amount})
-	}
+	})
 	return json.Marshal(arr)
 }
 
+// UnmarshalJSON decodes a list of ValueJSONItem or a nested cardano-cli value.
 func (v *Value) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
 	if string(data) == "null" {
 		return nil
 	}
+	if len(data) > 0 && data[0] == '{' {
+		return (*NestedValue)(v).UnmarshalJSON(data)
+	}
 	var arr ValueJSON
 	if err := json.Unmarshal(data, &arr); err != nil {
 		return err
@@ -37,3 +46,97 @@ func (v *Value) UnmarshalJSON(data []byte) error {
 	}
 	return nil
 }
+
+// NestedValue is a Value encoded to JSON as cardano-cli, Blockfrost and most
+// tools do: {"lovelace": n, "<policy>": {"<token name>": n}}.
+type NestedValue Value
+
+func (v NestedValue) MarshalJSON() ([]byte, error) {
+	if v == nil {
+		return []byte("null"), nil
+	}
+	var buf bytes.Buffer
+	writeKey := func(key string) {
+		b, _ := json.Marshal(key)
+		buf.Write(b)
+		buf.WriteByte(':')
+	}
+	buf.WriteByte('{')
+	// zero token amounts are dropped, a zero lovelace amount is kept like
+	// the decoding does
+	trimmed := Value(v).Clone().Trim()
+	if amount, ok := v[ADA]; ok && amount != nil && amount.Sign() == 0 {
+		trimmed[ADA] = amount
+	}
+	policy := ""
+	for i, asset := range trimmed.Assets() {
+		if i > 0 && asset.CurrencySymbol == policy {
+			buf.WriteByte(',')
+		} else {
+			if policy != "" {
+				buf.WriteByte('}')
+			}
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if asset == ADA {
+				writeKey("lovelace")
+				buf.WriteString(v[asset].String())
+				continue
+			}
+			policy = asset.CurrencySymbol
+			writeKey(policy)
+			buf.WriteByte('{')
+		}
+		writeKey(asset.TokenName)
+		buf.WriteString(v[asset].String())
+	}
+	if policy != "" {
+		buf.WriteByte('}')
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (v *NestedValue) UnmarshalJSON(data []byte) error {
+	if string(bytes.TrimSpace(data)) == "null" {
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	val, err := ValueFromNestedJSON(fields)
+	if err != nil {
+		return err
+	}
+	*v = NestedValue(val)
+	return nil
+}
+
+// ValueFromNestedJSON decodes the fields of a nested cardano-cli value.
+func ValueFromNestedJSON(fields map[string]json.RawMessage) (Value, error) {
+	val := NewValue()
+	for currencySymbol, raw := range fields {
+		if currencySymbol == "lovelace" {
+			amount, ok := new(big.Int).SetString(string(bytes.TrimSpace(raw)), 10)
+			if !ok {
+				return nil, fmt.Errorf("fail to parse lovelace amount: %s", raw)
+			}
+			val[ADA] = amount
+			continue
+		}
+
+		var tokenNameMap map[string]*big.Int
+		if err := json.Unmarshal(raw, &tokenNameMap); err != nil {
+			return nil, fmt.Errorf("fail to decode map of token name to amount: %w", err)
+		}
+		for tokenName, amount := range tokenNameMap {
+			if amount == nil {
+				return nil, fmt.Errorf("missing amount of %s.%s", currencySymbol, tokenName)
+			}
+			val.Add(NewAsset(currencySymbol, tokenName), amount)
+		}
+	}
+	return val, nil
+}
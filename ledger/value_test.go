@@ -1,7 +1,10 @@
 package ledger
 
 import (
+	"encoding/json"
 	"math/big"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -36,6 +39,18 @@ func TestMinimumADA(t *testing.T) {
 	)
 }
 
+func TestAddMinimumADA(t *testing.T) {
+	// [57-byte base address, 4-byte coin] and 34 more bytes of datum hash
+	assert.Equal(t, int64((160+65)*4310), NewValue().AddMinimumADA(false)[ADA].Int64())
+	assert.Equal(t, int64((160+65+34)*4310), NewValue().AddMinimumADA(true)[ADA].Int64())
+	assert.Equal(t, int64(5_000_000), NewValue().Add(ADA, big.NewInt(5_000_000)).AddMinimumADA(true)[ADA].Int64())
+
+	// a token name that is not hex cannot be serialized: the Alonzo formula
+	// is used instead of failing
+	bad := NewValue().Add(NewAsset("29d222ce763455e3d7a09a665ce554f00ac89d2e99a1a83d267170c6", "MIN"), big.NewInt(1))
+	assert.Equal(t, bad.MinimumADA(false), bad.Clone().AddMinimumADA(false)[ADA])
+}
+
 func TestTrimValue(t *testing.T) {
 	testAsset1 := NewAsset("1d7f33bd23d85e1a25d87d86fac4f199c3197a2f7afeb662a0f34e1e", "776f726c646d6f62696c65746f6b656e")
 	testAsset2 := NewAsset("1d7f33bd23d85e1a25d87d86fac4f199c3197a2f7afeb662a0f34e1e", "3d6e0553e80f44a201b15eba1d31666083adc505e738efcccd84d464200183a7")
@@ -51,3 +66,171 @@ func TestTrimValue(t *testing.T) {
 	assert.Nil(t,
 		trimmedVal[testAsset1])
 }
+
+func TestValueSortedAssets(t *testing.T) {
+	a := NewAsset("1d7f33bd23d85e1a25d87d86fac4f199c3197a2f7afeb662a0f34e1e", "4d494e")
+	b := NewAsset("1d7f33bd23d85e1a25d87d86fac4f199c3197a2f7afeb662a0f34e1e", "")
+	c := NewAsset("3f6092645942a54a75186b25e0975b79e1f50895ad958b42015eb6d2", "4d494e53574150")
+	val := NewValue().Add(c, big.NewInt(3)).Add(a, big.NewInt(1)).Add(ADA, big.NewInt(5)).Add(b, big.NewInt(2))
+
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, []Asset{ADA, b, a, c}, val.Assets())
+	}
+	var amounts []int64
+	val.ForEach(func(_ Asset, amount *big.Int) {
+		amounts = append(amounts, amount.Int64())
+	})
+	assert.Equal(t, []int64{5, 2, 1, 3}, amounts)
+
+	b1, err := val.MarshalJSON()
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"asset":{"currencySymbol":"","tokenName":""},"amount":5},{"asset":{"currencySymbol":"1d7f33bd23d85e1a25d87d86fac4f199c3197a2f7afeb662a0f34e1e","tokenName":""},"amount":2},{"asset":{"currencySymbol":"1d7f33bd23d85e1a25d87d86fac4f199c3197a2f7afeb662a0f34e1e","tokenName":"4d494e"},"amount":1},{"asset":{"currencySymbol":"3f6092645942a54a75186b25e0975b79e1f50895ad958b42015eb6d2","tokenName":"4d494e53574150"},"amount":3}]`, string(b1))
+}
+
+func TestNestedValueJSON(t *testing.T) {
+	a := NewAsset("1d7f33bd23d85e1a25d87d86fac4f199c3197a2f7afeb662a0f34e1e", "4d494e")
+	b := NewAsset("1d7f33bd23d85e1a25d87d86fac4f199c3197a2f7afeb662a0f34e1e", "")
+	c := NewAsset("3f6092645942a54a75186b25e0975b79e1f50895ad958b42015eb6d2", "4d494e53574150")
+	val := NewValue().Add(ADA, big.NewInt(5)).Add(a, big.NewInt(1)).Add(b, big.NewInt(2)).Add(c, big.NewInt(3))
+	nested := `{"lovelace":5,"1d7f33bd23d85e1a25d87d86fac4f199c3197a2f7afeb662a0f34e1e":{"":2,"4d494e":1},"3f6092645942a54a75186b25e0975b79e1f50895ad958b42015eb6d2":{"4d494e53574150":3}}`
+
+	b1, err := json.Marshal(NestedValue(val))
+	assert.NoError(t, err)
+	assert.Equal(t, nested, string(b1))
+
+	var decoded NestedValue
+	assert.NoError(t, json.Unmarshal([]byte(nested), &decoded))
+	assert.Equal(t, val, Value(decoded))
+
+	// Value accepts both formats, so UTxOs stored in either shape decode
+	var u Utxo
+	assert.NoError(t, json.Unmarshal([]byte(`{"txID":"aa","txIndex":0,"value":`+nested+`}`), &u))
+	assert.Equal(t, val, u.Value)
+
+	// a zero lovelace amount is kept both ways, as cardano-cli query utxo
+	// prints it
+	zero, err := ValueFromNestedJSON(map[string]json.RawMessage{"lovelace": json.RawMessage("0")})
+	if assert.NoError(t, err) {
+		assert.Equal(t, Value{ADA: big.NewInt(0)}, zero)
+	}
+	zeroADA := NewValue().Add(c, big.NewInt(3))
+	zeroADA[ADA] = big.NewInt(0)
+	b2, err := json.Marshal(NestedValue(zeroADA))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"lovelace":0,"3f6092645942a54a75186b25e0975b79e1f50895ad958b42015eb6d2":{"4d494e53574150":3}}`, string(b2))
+	var roundTrip NestedValue
+	assert.NoError(t, json.Unmarshal(b2, &roundTrip))
+	assert.Equal(t, zeroADA, Value(roundTrip))
+
+	onlyADA, err := json.Marshal(NestedValue(NewValue().Add(ADA, big.NewInt(7))))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"lovelace":7}`, string(onlyADA))
+	onlyToken, err := json.Marshal(NestedValue(NewValue().Add(c, big.NewInt(3))))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"3f6092645942a54a75186b25e0975b79e1f50895ad958b42015eb6d2":{"4d494e53574150":3}}`, string(onlyToken))
+}
+
+func TestValueAlgebra(t *testing.T) {
+	min := NewAsset("29d222ce763455e3d7a09a665ce554f00ac89d2e99a1a83d267170c6", "4d494e")
+	lp := NewAsset("e4214b7cce62ac6fbba385d164df48e157eae5863521b4b67ca71d86", "")
+	v := NewValue().Add(ADA, big.NewInt(10)).Add(min, big.NewInt(5))
+	w := NewValue().Add(ADA, big.NewInt(4)).Add(min, big.NewInt(5))
+
+	diff, err := v.Sub(w)
+	assert.NoError(t, err)
+	assert.True(t, diff.Equal(NewValue().Add(ADA, big.NewInt(6))))
+	assert.Equal(t, big.NewInt(10), v[ADA], "Sub must not modify its receiver")
+	_, err = w.Sub(v)
+	assert.ErrorIs(t, err, ErrNegativeValue)
+	_, err = v.Sub(NewValue().Add(lp, big.NewInt(1)))
+	assert.ErrorIs(t, err, ErrNegativeValue)
+
+	assert.True(t, v.Geq(w))
+	assert.False(t, w.Geq(v))
+	assert.True(t, w.Leq(v))
+	assert.False(t, v.Equal(w))
+	assert.True(t, v.Equal(NewValue().Add(min, big.NewInt(5)).Add(ADA, big.NewInt(10))))
+	assert.True(t, NewValue().IsZero())
+	assert.False(t, v.IsZero())
+
+	assert.True(t, v.FilterByPolicy(min.CurrencySymbol).Equal(NewValue().Add(min, big.NewInt(5))))
+	lovelace, tokens := v.Split()
+	assert.Equal(t, big.NewInt(10), lovelace)
+	assert.True(t, tokens.Equal(NewValue().Add(min, big.NewInt(5))))
+	assert.True(t, v.Scale(3).Equal(NewValue().Add(ADA, big.NewInt(30)).Add(min, big.NewInt(15))))
+	assert.True(t, v.Scale(0).IsZero())
+}
+
+func TestMintValue(t *testing.T) {
+	min := NewAsset("29d222ce763455e3d7a09a665ce554f00ac89d2e99a1a83d267170c6", "4d494e")
+	lp := NewAsset("e4214b7cce62ac6fbba385d164df48e157eae5863521b4b67ca71d86", "")
+	m := NewMintValue().
+		Mint(NewValue().Add(min, big.NewInt(5))).
+		Burn(NewValue().Add(lp, big.NewInt(2)))
+	assert.True(t, m.Minted().Equal(NewValue().Add(min, big.NewInt(5))))
+	assert.True(t, m.Burned().Equal(NewValue().Add(lp, big.NewInt(2))))
+	assert.Equal(t, []string{min.CurrencySymbol, lp.CurrencySymbol}, m.Policies())
+	assert.True(t, m.Negate().Equal(NewValue().Add(min, big.NewInt(5)).Negate().Mint(NewValue().Add(lp, big.NewInt(2)))))
+
+	m.Burn(NewValue().Add(min, big.NewInt(5)))
+	assert.NotContains(t, m, min)
+	assert.False(t, m.IsZero())
+	assert.True(t, m.AddAll(NewMintValue().Add(lp, big.NewInt(2))).IsZero())
+}
+
+func TestValueFormat(t *testing.T) {
+	min := NewAsset("29d222ce763455e3d7a09a665ce554f00ac89d2e99a1a83d267170c6", "4d494e")
+	lp := NewAsset("e4214b7cce62ac6fbba385d164df48e157eae5863521b4b67ca71d86", "4c50")
+	raw := NewAsset("e4214b7cce62ac6fbba385d164df48e157eae5863521b4b67ca71d86", "00ff")
+	registry := NewTokenRegistry(TokenMetadata{Asset: min, Ticker: "MIN", Decimals: 6})
+	val := NewValue().
+		Add(ADA, big.NewInt(12_500_000)).
+		Add(min, big.NewInt(100_500_000)).
+		Add(lp, big.NewInt(3)).
+		Add(raw, big.NewInt(1))
+
+	assert.Equal(t,
+		"12.500000 ADA + 100.500000 MIN + 1 e4214b7cce62ac6fbba385d164df48e157eae5863521b4b67ca71d86.00ff + 3 e4214b7cce62ac6fbba385d164df48e157eae5863521b4b67ca71d86.LP",
+		val.Format(registry),
+	)
+	assert.Equal(t, "0.000001 ADA", NewValue().Add(ADA, big.NewInt(1)).String())
+
+	parsed, err := ParseValue("12.5 ADA + 100.5 min + 3 e4214b7cce62ac6fbba385d164df48e157eae5863521b4b67ca71d86.4c50 + 1 e4214b7cce62ac6fbba385d164df48e157eae5863521b4b67ca71d86.00ff", registry)
+	assert.NoError(t, err)
+	assert.True(t, val.Equal(parsed))
+
+	_, err = ParseValue("1.0000001 ADA", registry)
+	assert.Error(t, err)
+	_, err = ParseValue("-1 ADA", registry)
+	assert.Error(t, err)
+}
+
+func TestReadTokenRegistryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registry.json")
+	content := `[{
+		"subject": "29d222ce763455e3d7a09a665ce554f00ac89d2e99a1a83d267170c64d494e",
+		"name": {"value": "Minswap", "sequenceNumber": 0},
+		"ticker": {"value": "MIN"},
+		"decimals": {"value": 6}
+	}, {
+		"subject": "e4214b7cce62ac6fbba385d164df48e157eae5863521b4b67ca71d864c50",
+		"ticker": "LP",
+		"decimals": 2
+	}]`
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	registry, err := ReadTokenRegistryFile(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	min, ok := registry.LookupTicker("min")
+	assert.True(t, ok)
+	assert.Equal(t, TokenMetadata{
+		Asset:    NewAsset("29d222ce763455e3d7a09a665ce554f00ac89d2e99a1a83d267170c6", "4d494e"),
+		Name:     "Minswap",
+		Ticker:   "MIN",
+		Decimals: 6,
+	}, min)
+	lp, ok := registry.Lookup(NewAsset("e4214b7cce62ac6fbba385d164df48e157eae5863521b4b67ca71d86", "4c50"))
+	assert.True(t, ok)
+	assert.Equal(t, 2, lp.Decimals)
+}
@@ -0,0 +1,487 @@
+// Package offline builds balanced transactions in Go, without cardano-cli or
+// a node socket.
+package offline
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/minswap/pab-go/address"
+	"github.com/minswap/pab-go/cli"
+	"github.com/minswap/pab-go/coinselection"
+	"github.com/minswap/pab-go/fee"
+	"github.com/minswap/pab-go/ledger"
+	"github.com/minswap/pab-go/txbuilder"
+)
+
+const (
+	maxFeeIterations   = 10
+	maxSelectionRounds = 10
+)
+
+var (
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrNoCollateral      = errors.New("plutus tx has no collateral")
+	ErrNoExUnits         = errors.New("plutus redeemer has no execution units")
+)
+
+// UtxoResolver resolves inputs added without their UTxO, like the ones of
+// SpendScriptUtxoRaw. CardanoCLI implements it.
+type UtxoResolver interface {
+	GetUtxosByTxIns(txIns ...cli.TxIn) ([]ledger.Utxo, error)
+}
+
+type Options struct {
+	Network address.Network
+	Params  ledger.ProtocolParams
+	// Era is the ledger era of the built transactions, Conway by default.
+	Era cli.Era
+	// Resolver is optional, inputs without value fail to build without it.
+	Resolver UtxoResolver
+	// Utxos are optional spendable UTxOs of the wallet. When the inputs of a
+	// TxBuilder do not cover its outputs, fee and minimum change, more are
+	// selected from them with CoinSelection.
+	Utxos         []ledger.Utxo
+	CoinSelection coinselection.Algorithm
+}
+
+// Builder builds transactions like CardanoCLI.BuildTx: it adds a change output
+// to ChangeAddress and computes the fee, unless the TxBuilder has a fee.
+type Builder struct {
+	Network  address.Network
+	Params   ledger.ProtocolParams
+	Era      cli.Era
+	Resolver UtxoResolver
+
+	Utxos         []ledger.Utxo
+	CoinSelection coinselection.Algorithm
+}
+
+var _ cli.TxBuildBackend = (*Builder)(nil)
+
+func New(options Options) *Builder {
+	era := options.Era
+	if era == "" {
+		era = cli.Conway
+	}
+	return &Builder{
+		Network:  options.Network,
+		Params:   options.Params,
+		Era:      era,
+		Resolver: options.Resolver,
+
+		Utxos:         options.Utxos,
+		CoinSelection: options.CoinSelection,
+	}
+}
+
+func (b *Builder) BuildTx(txb txbuilder.TxBuilder) (*cli.Tx, error) {
+	tx, err := b.Balance(txb)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := tx.MarshalCBOR()
+	if err != nil {
+		return nil, fmt.Errorf("fail to serialize tx: %w", err)
+	}
+	txHash, err := tx.ID()
+	if err != nil {
+		return nil, fmt.Errorf("fail to get tx hash: %w", err)
+	}
+	return &cli.Tx{
+		TxHash: txHash,
+		TxBody: hex.EncodeToString(raw),
+	}, nil
+}
+
+// Balance returns the balanced transaction of txb.
+func (b *Builder) Balance(txb txbuilder.TxBuilder) (*txbuilder.Transaction, error) {
+	if err := txb.ValidateNetwork(b.Network); err != nil {
+		return nil, fmt.Errorf("invalid tx: %w", err)
+	}
+	if txb.MinimumADAParams != nil {
+		if err := txb.ApplyMinimumADA(*txb.MinimumADAParams); err != nil {
+			return nil, err
+		}
+	}
+	resolved, err := b.resolveInputs(&txb)
+	if err != nil {
+		return nil, err
+	}
+	known := ledger.NewUtxoSet(b.Utxos...)
+	known.Add(resolved...)
+	for round := 0; ; round++ {
+		tx, err := b.balanceInputs(&txb, known)
+		var short *shortfallError
+		if err == nil || !errors.As(err, &short) || len(b.Utxos) == 0 || round == maxSelectionRounds {
+			return tx, err
+		}
+		// select inputs covering what is missing, then balance again: the
+		// new inputs raise the fee and may carry tokens raising the change
+		// minimum, so the next round may miss a little more
+		if err := b.selectInputs(&txb, short.missing); err != nil {
+			if errors.Is(err, coinselection.ErrInsufficientFunds) {
+				return nil, short
+			}
+			return nil, fmt.Errorf("fail to select inputs: %w", err)
+		}
+	}
+}
+
+// shortfallError is an ErrInsufficientFunds error telling what is missing.
+type shortfallError struct {
+	missing ledger.Value
+	err     error
+}
+
+func (e *shortfallError) Error() string { return e.err.Error() }
+func (e *shortfallError) Unwrap() error { return e.err }
+
+func shortfall(missing ledger.Value, format string, args ...interface{}) error {
+	return &shortfallError{missing: missing, err: fmt.Errorf("%w: "+format, append([]interface{}{ErrInsufficientFunds}, args...)...)}
+}
+
+// selectInputs adds UTxOs of b.Utxos, not already spent or used as
+// collateral by txb, covering missing.
+func (b *Builder) selectInputs(txb *txbuilder.TxBuilder, missing ledger.Value) error {
+	used := make(map[ledger.OutRef]bool)
+	for _, in := range txb.PubKeyInputs {
+		used[ledger.OutRef{TxID: in.TxID, TxIndex: in.TxIndex}] = true
+	}
+	for _, in := range txb.ScriptInputs {
+		used[ledger.OutRef{TxID: in.TxID, TxIndex: in.TxIndex}] = true
+	}
+	for _, in := range txb.Collaterals {
+		used[ledger.OutRef{TxID: in.TxID, TxIndex: in.TxIndex}] = true
+	}
+	res, err := coinselection.Select(b.CoinSelection, b.Utxos, coinselection.Params{
+		Outputs:    []ledger.Value{missing},
+		IsReserved: func(u ledger.Utxo) bool { return used[u.OutRef()] },
+	})
+	if err != nil {
+		return err
+	}
+	txb.Add(txbuilder.SpendPubKeyUtxos(res.Inputs...))
+	return nil
+}
+
+// selectCollateral sets the collaterals of tx, selected from the UTxOs of
+// b.Utxos that txb does not spend, covering the current fee of tx. The excess
+// goes back to the change address.
+func (b *Builder) selectCollateral(txb *txbuilder.TxBuilder, tx *txbuilder.Transaction) ([]ledger.Utxo, error) {
+	spent := make(map[ledger.OutRef]bool)
+	for _, in := range tx.Inputs {
+		spent[ledger.OutRef{TxID: in.TxID, TxIndex: in.TxIndex}] = true
+	}
+	res, err := coinselection.SelectCollateral(b.Utxos, coinselection.CollateralParams{
+		Fee:            big.NewInt(tx.Fee),
+		ProtocolParams: b.Params,
+		ReturnAddress:  txb.ChangeAddress,
+		IsReserved:     func(u ledger.Utxo) bool { return spent[u.OutRef()] },
+	})
+	if err != nil {
+		return nil, err
+	}
+	tx.Collaterals = tx.Collaterals[:0]
+	for _, u := range res.Inputs {
+		tx.Collaterals = append(tx.Collaterals, txbuilder.TxInput{
+			TxID:    u.TxID,
+			TxIndex: u.TxIndex,
+			TxOut:   txbuilder.TxOutput{Address: u.Address, Value: u.Value},
+		})
+	}
+	sort.Slice(tx.Collaterals, func(i, j int) bool {
+		if tx.Collaterals[i].TxID != tx.Collaterals[j].TxID {
+			return tx.Collaterals[i].TxID < tx.Collaterals[j].TxID
+		}
+		return tx.Collaterals[i].TxIndex < tx.Collaterals[j].TxIndex
+	})
+	tx.CollateralReturn, tx.TotalCollateral = nil, 0
+	if res.Return != nil {
+		tx.CollateralReturn = &ledger.Output{Address: txb.ChangeAddress, Value: res.Return}
+		tx.TotalCollateral = res.TotalCollateral.Int64()
+	}
+	return res.Inputs, nil
+}
+
+// balanceInputs balances txb with its inputs as they are. known holds the
+// UTxOs of the inputs whose reference scripts add to the fee.
+func (b *Builder) balanceInputs(txb *txbuilder.TxBuilder, known ledger.UtxoSet) (*txbuilder.Transaction, error) {
+	tx, err := txb.Transaction()
+	if err != nil {
+		return nil, fmt.Errorf("fail to convert tx builder: %w", err)
+	}
+	// the redeemers of the non-Raw options have no execution units, which
+	// only cardano-cli evaluates
+	for _, r := range tx.Redeemers {
+		if r.ExMem <= 0 || r.ExCPU <= 0 {
+			return nil, fmt.Errorf("%w: redeemer %d of tag %d, use the Raw options to set them", ErrNoExUnits, r.Index, r.Tag)
+		}
+	}
+	sort.SliceStable(tx.Redeemers, func(i, j int) bool {
+		if tx.Redeemers[i].Tag != tx.Redeemers[j].Tag {
+			return tx.Redeemers[i].Tag < tx.Redeemers[j].Tag
+		}
+		return tx.Redeemers[i].Index < tx.Redeemers[j].Index
+	})
+	// without collaterals, they are selected from b.Utxos for the fee
+	autoCollateral := len(tx.Redeemers) > 0 && len(tx.Collaterals) == 0
+	if autoCollateral && len(b.Utxos) == 0 {
+		return nil, ErrNoCollateral
+	}
+	if len(tx.Redeemers) > 0 || len(tx.Datums) > 0 {
+		hash, err := scriptDataHash(b.Era, tx, b.Params.CostModels)
+		if err != nil {
+			return nil, fmt.Errorf("fail to compute script data hash: %w", err)
+		}
+		tx.ScriptDataHash = hash
+	}
+	if txb.IsRaw() {
+		return tx, nil
+	}
+	if txb.ChangeAddress == "" {
+		return nil, errors.New("invalid tx: no change address")
+	}
+
+	available, err := b.balance(txb, tx)
+	if err != nil {
+		return nil, err
+	}
+	outputs := tx.Outputs
+	witnesses := witnessCount(txb, nil)
+	refScripts := refScriptSize(txb, known)
+	tx.Fee = 0
+	for i := 0; i < maxFeeIterations; i++ {
+		change := available.Clone()
+		if !change.Contains(ledger.ADA) || change[ledger.ADA].Cmp(big.NewInt(tx.Fee)) < 0 {
+			missing := tx.Fee - adaOf(change)
+			return nil, shortfall(ledger.NewValue().Add(ledger.ADA, big.NewInt(missing)), "missing %d lovelace for fee", missing)
+		}
+		change.Add(ledger.ADA, big.NewInt(-tx.Fee))
+		tx.Outputs = outputs
+		if len(change) > 0 {
+			changeOut := ledger.Output{Address: txb.ChangeAddress, Value: change}
+			if b.Params.UtxoCostPerByte > 0 {
+				min, err := ledger.MinimumADAForOutput(changeOut, b.Params)
+				if err != nil {
+					return nil, err
+				}
+				if change[ledger.ADA] == nil || change[ledger.ADA].Cmp(min) < 0 {
+					missing := new(big.Int).Sub(min, amountOf(change, ledger.ADA))
+					return nil, shortfall(ledger.NewValue().Add(ledger.ADA, missing), "change of %d lovelace is under the minimum of %s", adaOf(change), min)
+				}
+			}
+			tx.Outputs = append(outputs[:len(outputs):len(outputs)], changeOut)
+		}
+		if autoCollateral {
+			collaterals, err := b.selectCollateral(txb, tx)
+			if err != nil {
+				return nil, fmt.Errorf("fail to select collateral: %w", err)
+			}
+			witnesses = witnessCount(txb, collaterals)
+		}
+		raw, err := tx.MarshalCBOR()
+		if err != nil {
+			return nil, fmt.Errorf("fail to serialize tx: %w", err)
+		}
+		estimated, err := fee.EstimateTx(raw, witnesses, b.Params, refScripts)
+		if err != nil {
+			return nil, fmt.Errorf("fail to estimate fee: %w", err)
+		}
+		if estimated.Total <= tx.Fee {
+			return tx, nil
+		}
+		tx.Fee = estimated.Total
+	}
+	return nil, errors.New("fail to balance tx: fee does not converge")
+}
+
+func adaOf(val ledger.Value) int64 {
+	if amount, ok := val[ledger.ADA]; ok {
+		return amount.Int64()
+	}
+	return 0
+}
+
+// refScriptSize sums the size of the reference scripts held by the inputs of
+// txb, as far as known has their UTxO.
+func refScriptSize(txb *txbuilder.TxBuilder, known ledger.UtxoSet) int64 {
+	var size int64
+	add := func(in txbuilder.TxInput) {
+		u, ok := known.Get(ledger.OutRef{TxID: in.TxID, TxIndex: in.TxIndex})
+		if ok && u.ReferenceScript != nil {
+			size += int64(len(u.ReferenceScript.Bytes))
+		}
+	}
+	for _, in := range txb.PubKeyInputs {
+		add(in)
+	}
+	for _, in := range txb.ScriptInputs {
+		add(in.TxInput)
+	}
+	return size
+}
+
+// resolveInputs fills the UTxO of inputs added without it and returns the
+// resolved UTxOs.
+func (b *Builder) resolveInputs(txb *txbuilder.TxBuilder) ([]ledger.Utxo, error) {
+	var missing []cli.TxIn
+	for _, in := range txb.PubKeyInputs {
+		if in.TxOut.Value == nil {
+			missing = append(missing, cli.TxIn{TxID: in.TxID, TxIndex: in.TxIndex})
+		}
+	}
+	for _, in := range txb.ScriptInputs {
+		if in.TxOut.Value == nil {
+			missing = append(missing, cli.TxIn{TxID: in.TxID, TxIndex: in.TxIndex})
+		}
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+	if b.Resolver == nil {
+		return nil, fmt.Errorf("fail to resolve %d inputs: no UTxO resolver", len(missing))
+	}
+	utxos, err := b.Resolver.GetUtxosByTxIns(missing...)
+	if err != nil {
+		return nil, fmt.Errorf("fail to resolve inputs: %w", err)
+	}
+	resolved := ledger.NewUtxoSet(utxos...)
+	pubKeyInputs := append([]txbuilder.TxInput{}, txb.PubKeyInputs...)
+	for i, in := range pubKeyInputs {
+		if in.TxOut.Value != nil {
+			continue
+		}
+		u, ok := resolved.Get(ledger.OutRef{TxID: in.TxID, TxIndex: in.TxIndex})
+		if !ok {
+			return nil, fmt.Errorf("fail to resolve input %s#%d", in.TxID, in.TxIndex)
+		}
+		pubKeyInputs[i].TxOut = txbuilder.TxOutput{Address: u.Address, Value: u.Value}
+	}
+	scriptInputs := append([]txbuilder.ScriptInput{}, txb.ScriptInputs...)
+	for i, in := range scriptInputs {
+		if in.TxOut.Value != nil {
+			continue
+		}
+		u, ok := resolved.Get(ledger.OutRef{TxID: in.TxID, TxIndex: in.TxIndex})
+		if !ok {
+			return nil, fmt.Errorf("fail to resolve input %s#%d", in.TxID, in.TxIndex)
+		}
+		scriptInputs[i].TxOut.TxOutput = txbuilder.TxOutput{Address: u.Address, Value: u.Value}
+	}
+	txb.PubKeyInputs = pubKeyInputs
+	txb.ScriptInputs = scriptInputs
+	return utxos, nil
+}
+
+// balance returns what is left for fee and change: inputs, withdrawals,
+// minted assets and refunds minus outputs and deposits.
+func (b *Builder) balance(txb *txbuilder.TxBuilder, tx *txbuilder.Transaction) (ledger.Value, error) {
+	in := ledger.NewValue()
+	out := ledger.NewValue()
+	for _, i := range txb.PubKeyInputs {
+		in.AddAll(i.TxOut.Value)
+	}
+	for _, i := range txb.ScriptInputs {
+		in.AddAll(i.TxOut.Value)
+	}
+	for _, w := range tx.Withdrawals {
+		in.Add(ledger.ADA, big.NewInt(w.Amount))
+	}
+	in.AddAll(tx.Mint.Minted())
+	out.AddAll(tx.Mint.Burned())
+	for _, cert := range tx.Certificates {
+		switch cert.Kind {
+		case txbuilder.StakeRegistration:
+			out.Add(ledger.ADA, big.NewInt(b.Params.StakeAddressDeposit))
+		case txbuilder.StakeDeregistration:
+			in.Add(ledger.ADA, big.NewInt(b.Params.StakeAddressDeposit))
+		case txbuilder.DRepRegistration:
+			out.Add(ledger.ADA, big.NewInt(cert.Deposit))
+		case txbuilder.DRepDeregistration:
+			in.Add(ledger.ADA, big.NewInt(cert.Deposit))
+		}
+	}
+	for _, p := range tx.Proposals {
+		out.Add(ledger.ADA, big.NewInt(p.Deposit))
+	}
+	for _, o := range tx.Outputs {
+		out.AddAll(o.Value)
+	}
+
+	available := in.Clone()
+	for asset, amount := range out {
+		if !available.Contains(asset) || available[asset].Cmp(amount) < 0 {
+			missing := new(big.Int).Sub(amount, amountOf(available, asset))
+			return nil, shortfall(ledger.NewValue().Add(asset, missing), "missing %s of %s", missing, asset)
+		}
+		available.Add(asset, new(big.Int).Neg(amount))
+	}
+	return available, nil
+}
+
+func amountOf(val ledger.Value, asset ledger.Asset) *big.Int {
+	if amount, ok := val[asset]; ok {
+		return amount
+	}
+	return big.NewInt(0)
+}
+
+// witnessCount counts the distinct keys expected to sign the tx: payment keys
+// of inputs and collaterals, selected ones included, required signers and key
+// stake credentials.
+func witnessCount(txb *txbuilder.TxBuilder, collaterals []ledger.Utxo) int {
+	keys := make(map[string]struct{})
+	addPaymentKey := func(addr string) {
+		a, err := address.Parse(addr)
+		if err == nil && a.Payment != nil && !a.Payment.IsScript() {
+			keys[a.Payment.Hash] = struct{}{}
+		}
+	}
+	for _, in := range txb.PubKeyInputs {
+		addPaymentKey(in.TxOut.Address)
+	}
+	for _, in := range txb.Collaterals {
+		addPaymentKey(in.TxOut.Address)
+	}
+	for _, u := range collaterals {
+		addPaymentKey(u.Address)
+	}
+	for _, h := range txb.RequiredSignerVkeyHashes {
+		keys[h] = struct{}{}
+	}
+	for _, skey := range txb.SignerSkeyPaths {
+		keys[skey] = struct{}{}
+	}
+	for _, cert := range txb.Certificates {
+		switch cert.Kind {
+		case txbuilder.StakeRegistration:
+		case txbuilder.DRepRegistration, txbuilder.DRepDeregistration, txbuilder.DRepUpdate:
+			if !cert.DRepCredential.IsScript() {
+				keys[cert.DRepCredential.Hash] = struct{}{}
+			}
+		default:
+			if !cert.StakeCredential.IsScript() {
+				keys[cert.StakeCredential.Hash] = struct{}{}
+			}
+		}
+	}
+	for _, w := range txb.Withdrawals {
+		a, err := address.Parse(w.RewardAddress)
+		if err == nil && a.Stake != nil && !a.Stake.IsScript() {
+			keys[a.Stake.Hash] = struct{}{}
+		}
+	}
+	for _, v := range txb.Votes {
+		if v.ScriptFilePath == "" {
+			keys[v.Voter.Hash] = struct{}{}
+		}
+	}
+	if len(keys) == 0 {
+		return 1
+	}
+	return len(keys)
+}
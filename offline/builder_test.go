@@ -0,0 +1,239 @@
+package offline
+
+import (
+	"encoding/hex"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/minswap/pab-go/address"
+	"github.com/minswap/pab-go/cbor"
+	"github.com/minswap/pab-go/cli"
+	"github.com/minswap/pab-go/fee"
+	"github.com/minswap/pab-go/ledger"
+	"github.com/minswap/pab-go/txbuilder"
+	"github.com/stretchr/testify/assert"
+)
+
+const testAddr = "addr_test1qpmtp5t0t5y6cqkaz7rfsyrx7mld77kpvksgkwm0p7en7qum7a589n30e80tclzrrnj8qr4qvzj6al0vpgtnmrkkksnqd8upj0"
+
+func testParams() ledger.ProtocolParams {
+	return ledger.ProtocolParams{
+		UtxoCostPerByte: 4310,
+		TxFeePerByte:    44,
+		TxFeeFixed:      155381,
+		MaxTxSize:       16384,
+		ExecutionUnitPrices: ledger.ExecutionUnitPrices{
+			PriceMemory: ledger.NewRational(577, 10000),
+			PriceSteps:  ledger.NewRational(721, 10000000),
+		},
+		CostModels: ledger.CostModels{ledger.PlutusV2: {1, 2, 3}},
+	}
+}
+
+func ada(n int64) ledger.Value {
+	return ledger.NewValue().Add(ledger.ADA, big.NewInt(n))
+}
+
+func utxo(txID string, index int, val ledger.Value) ledger.Utxo {
+	return ledger.Utxo{TxID: txID, TxIndex: index, Address: testAddr, Value: val}
+}
+
+func TestBalance(t *testing.T) {
+	assert := assert.New(t)
+	b := New(Options{Network: address.Testnet, Params: testParams()})
+	token := ledger.NewAsset("29d222ce763455e3d7a09a665ce554f00ac89d2e99a1a83d267170c6", "4d494e")
+	txb := txbuilder.New(
+		txbuilder.SpendPubKeyUtxos(
+			utxo("aa00000000000000000000000000000000000000000000000000000000000000", 0, ada(10_000_000).Add(token, big.NewInt(5))),
+		),
+		txbuilder.PayToPubKey(testAddr, ada(2_000_000)),
+		txbuilder.PayChangeTo(testAddr),
+	)
+	tx, err := b.Balance(txb)
+	if !assert.NoError(err) {
+		return
+	}
+	if assert.Len(tx.Outputs, 2) {
+		change := tx.Outputs[1].Value
+		assert.Equal(big.NewInt(5), change[token])
+		assert.Equal(int64(8_000_000), change[ledger.ADA].Int64()+tx.Fee)
+	}
+	raw, err := tx.MarshalCBOR()
+	assert.NoError(err)
+	estimated, err := fee.EstimateTx(raw, 1, testParams(), 0)
+	assert.NoError(err)
+	assert.Equal(estimated.Total, tx.Fee)
+
+	built, err := b.BuildTx(txb)
+	assert.NoError(err)
+	assert.Equal(hex.EncodeToString(raw), built.TxBody)
+
+	txb.Add(txbuilder.PayToPubKey(testAddr, ada(9_000_000)))
+	_, err = b.Balance(txb)
+	assert.ErrorIs(err, ErrInsufficientFunds)
+}
+
+func TestBalanceSelectsInputs(t *testing.T) {
+	assert := assert.New(t)
+	small := utxo("aa00000000000000000000000000000000000000000000000000000000000000", 0, ada(2_300_000))
+	txb := txbuilder.New(
+		txbuilder.SpendPubKeyUtxos(small),
+		txbuilder.PayToPubKey(testAddr, ada(2_000_000)),
+		txbuilder.PayChangeTo(testAddr),
+	)
+	b := New(Options{Network: address.Testnet, Params: testParams()})
+	_, err := b.Balance(txb)
+	assert.ErrorIs(err, ErrInsufficientFunds)
+
+	// the change is under the minimum until another input is selected
+	b.Utxos = []ledger.Utxo{
+		small,
+		utxo("cc00000000000000000000000000000000000000000000000000000000000000", 0, ada(700_000)),
+		utxo("dd00000000000000000000000000000000000000000000000000000000000000", 0, ada(5_000_000)),
+	}
+	tx, err := b.Balance(txb)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Len(tx.Inputs, 2)
+	assert.Equal("dd00000000000000000000000000000000000000000000000000000000000000", tx.Inputs[1].TxID)
+	if assert.Len(tx.Outputs, 2) {
+		assert.Equal(int64(5_300_000), tx.Outputs[1].Value[ledger.ADA].Int64()+tx.Fee)
+	}
+
+	b.Utxos = b.Utxos[:2]
+	_, err = b.Balance(txb)
+	assert.ErrorIs(err, ErrInsufficientFunds)
+}
+
+func TestBalancePlutus(t *testing.T) {
+	assert := assert.New(t)
+	scriptPath := filepath.Join(t.TempDir(), "always-succeeds.plutus")
+	assert.NoError(os.WriteFile(scriptPath, []byte(`{"type":"PlutusScriptV2","description":"","cborHex":"49480100002221200101"}`), 0644))
+
+	datumHash := ledger.DatumHash([]byte{0xd8, 0x79, 0x80})
+	scriptUtxo := utxo("bb00000000000000000000000000000000000000000000000000000000000000", 1, ada(5_000_000))
+	scriptUtxo.DatumHash = &datumHash
+	b := New(Options{Network: address.Testnet, Params: testParams()})
+	txb := txbuilder.New(
+		txbuilder.SpendScriptUtxoRaw(scriptUtxo, scriptPath, `{"constructor":0,"fields":[]}`, `{"int":1}`, 1000, 2000),
+		txbuilder.SpendPubKeyUtxos(utxo("aa00000000000000000000000000000000000000000000000000000000000000", 0, ada(10_000_000))),
+		txbuilder.PayChangeTo(testAddr),
+	)
+	_, err := b.Balance(txb)
+	assert.Error(err, "raw script inputs need a resolver")
+
+	b.Resolver = resolverFunc(func() []ledger.Utxo { return []ledger.Utxo{scriptUtxo} })
+	_, err = b.Balance(txb)
+	assert.ErrorIs(err, ErrNoCollateral)
+
+	txb.Add(txbuilder.UseCollaterals(utxo("aa00000000000000000000000000000000000000000000000000000000000000", 0, ada(10_000_000))))
+	tx, err := b.Balance(txb)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Len(tx.Redeemers, 1)
+	assert.Equal(1, tx.Redeemers[0].Index)
+	assert.Len(tx.ScriptDataHash, 64)
+	assert.Greater(tx.Fee, int64(0))
+	assert.Equal(int64(15_000_000), tx.Outputs[0].Value[ledger.ADA].Int64()+tx.Fee)
+
+	raw, err := tx.MarshalCBOR()
+	assert.NoError(err)
+	decoded, err := cbor.Unmarshal(raw)
+	assert.NoError(err)
+	ws := decoded.([]interface{})[1].(cbor.Map)
+	_, hasV2 := ws.Get(uint64(6))
+	assert.True(hasV2)
+}
+
+func TestBalanceNoExUnits(t *testing.T) {
+	assert := assert.New(t)
+	scriptPath := filepath.Join(t.TempDir(), "always-succeeds.plutus")
+	assert.NoError(os.WriteFile(scriptPath, []byte(`{"type":"PlutusScriptV2","description":"","cborHex":"49480100002221200101"}`), 0644))
+
+	datumHash := ledger.DatumHash([]byte{0xd8, 0x79, 0x80})
+	scriptUtxo := utxo("bb00000000000000000000000000000000000000000000000000000000000000", 1, ada(5_000_000))
+	scriptUtxo.DatumHash = &datumHash
+	input := utxo("aa00000000000000000000000000000000000000000000000000000000000000", 0, ada(10_000_000))
+	b := New(Options{Network: address.Testnet, Params: testParams()})
+	txb := txbuilder.New(
+		txbuilder.SpendScriptUtxo(scriptUtxo, scriptPath, `{"constructor":0,"fields":[]}`, `{"int":1}`),
+		txbuilder.SpendPubKeyUtxos(input),
+		txbuilder.UseCollaterals(input),
+		txbuilder.PayChangeTo(testAddr),
+	)
+	_, err := b.Balance(txb)
+	assert.ErrorIs(err, ErrNoExUnits)
+}
+
+func TestBalanceReferenceScripts(t *testing.T) {
+	assert := assert.New(t)
+	input := utxo("aa00000000000000000000000000000000000000000000000000000000000000", 0, ada(10_000_000))
+	txb := txbuilder.New(
+		txbuilder.SpendPubKeyUtxos(input),
+		txbuilder.PayToPubKey(testAddr, ada(2_000_000)),
+		txbuilder.PayChangeTo(testAddr),
+	)
+	params := testParams()
+	params.MinFeeRefScriptCostPerByte = ledger.NewRational(15, 1)
+	b := New(Options{Network: address.Testnet, Params: params})
+	tx, err := b.Balance(txb)
+	if !assert.NoError(err) {
+		return
+	}
+	feeWithout := tx.Fee
+
+	// the resolved input holds a 1000 bytes reference script
+	input.ReferenceScript = &ledger.Script{Language: ledger.PlutusV2, Bytes: make([]byte, 1000)}
+	b.Utxos = []ledger.Utxo{input}
+	tx, err = b.Balance(txb)
+	if assert.NoError(err) {
+		assert.Equal(feeWithout+15*1000, tx.Fee)
+	}
+}
+
+func TestBalanceSelectsCollateral(t *testing.T) {
+	assert := assert.New(t)
+	scriptPath := filepath.Join(t.TempDir(), "always-succeeds.plutus")
+	assert.NoError(os.WriteFile(scriptPath, []byte(`{"type":"PlutusScriptV2","description":"","cborHex":"49480100002221200101"}`), 0644))
+
+	datumHash := ledger.DatumHash([]byte{0xd8, 0x79, 0x80})
+	scriptUtxo := utxo("bb00000000000000000000000000000000000000000000000000000000000000", 1, ada(5_000_000))
+	scriptUtxo.DatumHash = &datumHash
+	input := utxo("aa00000000000000000000000000000000000000000000000000000000000000", 0, ada(10_000_000))
+	txb := txbuilder.New(
+		txbuilder.SpendScriptUtxoRaw(scriptUtxo, scriptPath, `{"constructor":0,"fields":[]}`, `{"int":1}`, 1000, 2000),
+		txbuilder.SpendPubKeyUtxos(input),
+		txbuilder.PayChangeTo(testAddr),
+	)
+	b := New(Options{
+		Network: address.Testnet,
+		Params:  testParams(),
+		Utxos: []ledger.Utxo{
+			input,
+			utxo("cc00000000000000000000000000000000000000000000000000000000000000", 0, ada(100_000_000)),
+			utxo("dd00000000000000000000000000000000000000000000000000000000000000", 0, ada(3_000_000)),
+		},
+	})
+	b.Params.CollateralPercentage = 150
+	b.Resolver = resolverFunc(func() []ledger.Utxo { return []ledger.Utxo{scriptUtxo} })
+	tx, err := b.Balance(txb)
+	if !assert.NoError(err) {
+		return
+	}
+	// the smallest UTxO not spent covers 150% of the fee, the rest returns
+	if assert.Len(tx.Collaterals, 1) && assert.NotNil(tx.CollateralReturn) {
+		assert.Equal("dd00000000000000000000000000000000000000000000000000000000000000", tx.Collaterals[0].TxID)
+		assert.Equal((tx.Fee*150+99)/100, tx.TotalCollateral)
+		assert.Equal(int64(3_000_000)-tx.TotalCollateral, tx.CollateralReturn.Value[ledger.ADA].Int64())
+	}
+}
+
+type resolverFunc func() []ledger.Utxo
+
+func (f resolverFunc) GetUtxosByTxIns(txIns ...cli.TxIn) ([]ledger.Utxo, error) {
+	return f(), nil
+}
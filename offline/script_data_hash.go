@@ -0,0 +1,27 @@
+package offline
+
+import (
+	"github.com/minswap/pab-go/ledger"
+	"github.com/minswap/pab-go/txbuilder"
+)
+
+// scriptDataHash hashes the redeemers and datums of tx with the language
+// views of the Plutus languages it runs, as hashed by the ledger in era.
+func scriptDataHash(era string, tx *txbuilder.Transaction, costModels ledger.CostModels) (string, error) {
+	var redeemers, datums []byte
+	var langs []ledger.ScriptLanguage
+	if len(tx.Redeemers) > 0 {
+		var err error
+		if redeemers, err = tx.EncodeRedeemers(); err != nil {
+			return "", err
+		}
+		langs = tx.Languages()
+	}
+	if len(tx.Datums) > 0 {
+		var err error
+		if datums, err = tx.EncodeDatums(); err != nil {
+			return "", err
+		}
+	}
+	return ledger.ScriptDataHash(era, redeemers, datums, langs, costModels)
+}
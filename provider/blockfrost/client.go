@@ -0,0 +1,148 @@
+// Package blockfrost is a ChainProvider over the Blockfrost REST API.
+package blockfrost
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	MainnetURL = "https://cardano-mainnet.blockfrost.io/api/v0"
+	PreprodURL = "https://cardano-preprod.blockfrost.io/api/v0"
+	PreviewURL = "https://cardano-preview.blockfrost.io/api/v0"
+
+	// PageSize is the largest page Blockfrost serves.
+	PageSize = 100
+)
+
+var ErrNotFound = errors.New("blockfrost: not found")
+
+// APIError is an error response of Blockfrost.
+type APIError struct {
+	StatusCode int    `json:"status_code"`
+	Err        string `json:"error"`
+	Message    string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("blockfrost error %d: %s: %s", e.StatusCode, e.Err, e.Message)
+}
+
+func (e *APIError) Is(target error) bool {
+	return target == ErrNotFound && e.StatusCode == http.StatusNotFound
+}
+
+type Options struct {
+	// BaseURL is one of MainnetURL, PreprodURL, PreviewURL, or the URL of a
+	// self-hosted Blockfrost-compatible API.
+	BaseURL string
+	// ProjectID is sent as the project_id header when not empty.
+	ProjectID  string
+	HTTPClient *http.Client
+	// MaxRetries is how many times a rate-limited request is retried, waiting
+	// Backoff, then twice as long each time, unless Retry-After says otherwise.
+	// It defaults to 3, a negative value disables retries.
+	MaxRetries int
+	Backoff    time.Duration
+}
+
+type Client struct {
+	Options
+}
+
+func New(options Options) *Client {
+	if options.HTTPClient == nil {
+		options.HTTPClient = http.DefaultClient
+	}
+	if options.MaxRetries == 0 {
+		options.MaxRetries = 3
+	}
+	if options.Backoff == 0 {
+		options.Backoff = time.Second
+	}
+	return &Client{Options: options}
+}
+
+func (c *Client) do(ctx context.Context, method, path, contentType string, body []byte) ([]byte, error) {
+	backoff := c.Backoff
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("fail to create request: %w", err)
+		}
+		if c.ProjectID != "" {
+			req.Header.Set("project_id", c.ProjectID)
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("fail to request %s: %w", path, err)
+		}
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("fail to read response of %s: %w", path, err)
+		}
+		if resp.StatusCode == http.StatusOK {
+			return respBody, nil
+		}
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < c.MaxRetries {
+			wait := backoff
+			if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+				wait = time.Duration(secs) * time.Second
+			}
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(wait):
+			}
+			backoff *= 2
+			continue
+		}
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		if err := json.Unmarshal(respBody, apiErr); err != nil || apiErr.Message == "" {
+			apiErr.Message = string(respBody)
+		}
+		apiErr.StatusCode = resp.StatusCode
+		return nil, apiErr
+	}
+}
+
+func (c *Client) get(path string, result interface{}) error {
+	body, err := c.do(context.Background(), http.MethodGet, path, "", nil)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("fail to decode %s: %w", path, err)
+	}
+	return nil
+}
+
+// getPages gets every page of path, calling decode with each page until one
+// has fewer than PageSize items.
+func (c *Client) getPages(path string, decode func(page []byte) (int, error)) error {
+	for page := 1; ; page++ {
+		body, err := c.do(context.Background(), http.MethodGet,
+			fmt.Sprintf("%s?page=%d&count=%d", path, page, PageSize), "", nil)
+		if err != nil {
+			return err
+		}
+		n, err := decode(body)
+		if err != nil {
+			return fmt.Errorf("fail to decode %s: %w", path, err)
+		}
+		if n < PageSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package blockfrost
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/minswap/pab-go/ledger"
+	"github.com/minswap/pab-go/provider"
+)
+
+var (
+	_ provider.ChainProvider  = (*Client)(nil)
+	_ provider.MempoolQuerier = (*Client)(nil)
+)
+
+// AwaitTxInterval is how often AwaitTx queries Blockfrost.
+var AwaitTxInterval = 5 * time.Second
+
+type block struct {
+	Hash   string `json:"hash"`
+	Height int    `json:"height"`
+	Slot   int    `json:"slot"`
+	Epoch  int    `json:"epoch"`
+}
+
+func (c *Client) GetTip() (*provider.Tip, error) {
+	var b block
+	if err := c.get("/blocks/latest", &b); err != nil {
+		return nil, fmt.Errorf("fail to query tip: %w", err)
+	}
+	return &provider.Tip{
+		Epoch:        b.Epoch,
+		Hash:         b.Hash,
+		Slot:         b.Slot,
+		Block:        b.Height,
+		SyncProgress: "100.00",
+	}, nil
+}
+
+type protocolParameters struct {
+	MinFeeA                    int64             `json:"min_fee_a"`
+	MinFeeB                    int64             `json:"min_fee_b"`
+	MaxTxSize                  int64             `json:"max_tx_size"`
+	KeyDeposit                 int64             `json:"key_deposit,string"`
+	MaxValSize                 int64             `json:"max_val_size,string"`
+	PriceMem                   *ledger.Rational  `json:"price_mem"`
+	PriceStep                  *ledger.Rational  `json:"price_step"`
+	CollateralPercent          int64             `json:"collateral_percent"`
+	MaxCollateralInputs        int               `json:"max_collateral_inputs"`
+	CoinsPerUtxoSize           int64             `json:"coins_per_utxo_size,string"`
+	CostModelsRaw              ledger.CostModels `json:"cost_models_raw"`
+	MinFeeRefScriptCostPerByte *ledger.Rational  `json:"min_fee_ref_script_cost_per_byte"`
+}
+
+func (c *Client) GetProtocolParams() (*ledger.ProtocolParams, error) {
+	var pp protocolParameters
+	if err := c.get("/epochs/latest/parameters", &pp); err != nil {
+		return nil, fmt.Errorf("fail to query protocol parameters: %w", err)
+	}
+	return &ledger.ProtocolParams{
+		UtxoCostPerByte: pp.CoinsPerUtxoSize,
+		MaxValueSize:    pp.MaxValSize,
+		TxFeePerByte:    pp.MinFeeA,
+		TxFeeFixed:      pp.MinFeeB,
+		MaxTxSize:       pp.MaxTxSize,
+		ExecutionUnitPrices: ledger.ExecutionUnitPrices{
+			PriceMemory: pp.PriceMem,
+			PriceSteps:  pp.PriceStep,
+		},
+		MinFeeRefScriptCostPerByte: pp.MinFeeRefScriptCostPerByte,
+		CollateralPercentage:       pp.CollateralPercent,
+		MaxCollateralInputs:        pp.MaxCollateralInputs,
+		StakeAddressDeposit:        pp.KeyDeposit,
+		CostModels:                 pp.CostModelsRaw,
+	}, nil
+}
+
+type amount struct {
+	Unit     string `json:"unit"`
+	Quantity string `json:"quantity"`
+}
+
+// valueFromAmounts converts an amount list, whose units are lovelace or the
+// policy ID followed by the hex asset name.
+func valueFromAmounts(amounts []amount) (ledger.Value, error) {
+	val := ledger.NewValue()
+	for _, a := range amounts {
+		n, ok := new(big.Int).SetString(a.Quantity, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid quantity of %s: %s", a.Unit, a.Quantity)
+		}
+		if a.Unit == "lovelace" {
+			val.Add(ledger.ADA, n)
+			continue
+		}
+		if len(a.Unit) < 56 {
+			return nil, fmt.Errorf("invalid unit: %s", a.Unit)
+		}
+		asset := ledger.NewAsset(a.Unit[:56], a.Unit[56:])
+		if err := asset.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid unit %s: %w", a.Unit, err)
+		}
+		val.Add(asset, n)
+	}
+	return val, nil
+}
+
+type utxo struct {
+	Address     string   `json:"address"`
+	TxHash      string   `json:"tx_hash"`
+	OutputIndex int      `json:"output_index"`
+	Amount      []amount `json:"amount"`
+	DataHash    *string  `json:"data_hash"`
+	// ConsumedByTx is only set in outputs of /txs/{hash}/utxos.
+	ConsumedByTx *string `json:"consumed_by_tx"`
+}
+
+func (u utxo) ledgerUtxo() (ledger.Utxo, error) {
+	val, err := valueFromAmounts(u.Amount)
+	if err != nil {
+		return ledger.Utxo{}, fmt.Errorf("fail to parse utxo %s#%d: %w", u.TxHash, u.OutputIndex, err)
+	}
+	return ledger.Utxo{
+		TxID:      u.TxHash,
+		TxIndex:   u.OutputIndex,
+		Address:   u.Address,
+		Value:     val,
+		DatumHash: u.DataHash,
+	}, nil
+}
+
+func (c *Client) GetUtxosByAddresses(addresses ...string) ([]ledger.Utxo, error) {
+	var utxos []ledger.Utxo
+	for _, addr := range addresses {
+		err := c.getPages("/addresses/"+addr+"/utxos", func(page []byte) (int, error) {
+			var result []utxo
+			if err := json.Unmarshal(page, &result); err != nil {
+				return 0, err
+			}
+			for _, u := range result {
+				lu, err := u.ledgerUtxo()
+				if err != nil {
+					return 0, err
+				}
+				utxos = append(utxos, lu)
+			}
+			return len(result), nil
+		})
+		// addresses never seen on chain are not found
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("fail to query utxos of %s: %w", addr, err)
+		}
+	}
+	return utxos, nil
+}
+
+func (c *Client) GetUtxosByTxIns(txIns ...ledger.OutRef) ([]ledger.Utxo, error) {
+	outputs := make(map[string][]utxo)
+	var utxos []ledger.Utxo
+	for _, in := range txIns {
+		txOutputs, ok := outputs[in.TxID]
+		if !ok {
+			var result struct {
+				Outputs []utxo `json:"outputs"`
+			}
+			err := c.get("/txs/"+in.TxID+"/utxos", &result)
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				return nil, fmt.Errorf("fail to query utxos of tx %s: %w", in.TxID, err)
+			}
+			txOutputs = result.Outputs
+			outputs[in.TxID] = txOutputs
+		}
+		for _, out := range txOutputs {
+			if out.OutputIndex != in.TxIndex || out.ConsumedByTx != nil {
+				continue
+			}
+			out.TxHash = in.TxID
+			lu, err := out.ledgerUtxo()
+			if err != nil {
+				return nil, err
+			}
+			utxos = append(utxos, lu)
+		}
+	}
+	return utxos, nil
+}
+
+// GetDatum returns the CBOR of the datum with the given hash.
+func (c *Client) GetDatum(datumHash string) ([]byte, error) {
+	var result struct {
+		CBOR string `json:"cbor"`
+	}
+	if err := c.get("/scripts/datum/"+datumHash+"/cbor", &result); err != nil {
+		return nil, fmt.Errorf("fail to query datum %s: %w", datumHash, err)
+	}
+	b, err := hex.DecodeString(result.CBOR)
+	if err != nil {
+		return nil, fmt.Errorf("fail to decode datum %s: %w", datumHash, err)
+	}
+	return b, nil
+}
+
+func (c *Client) SubmitTx(tx *provider.Tx) error {
+	b, err := hex.DecodeString(tx.TxBody)
+	if err != nil {
+		return fmt.Errorf("fail to decode tx: %w", err)
+	}
+	_, err = c.do(context.Background(), http.MethodPost, "/tx/submit", "application/cbor", b)
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest {
+			err = &provider.SubmitError{
+				Kind:    provider.ClassifyLedgerError(apiErr.Message),
+				Message: apiErr.Message,
+				Err:     apiErr,
+			}
+		}
+		return fmt.Errorf("fail to submit tx: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) AwaitTx(ctx context.Context, txHash string) error {
+	return provider.PollTx(ctx, AwaitTxInterval, func() (bool, error) {
+		_, err := c.do(ctx, http.MethodGet, "/txs/"+txHash, "", nil)
+		if errors.Is(err, ErrNotFound) {
+			return false, nil
+		}
+		return err == nil, err
+	})
+}
+
+// MempoolHasTx reports whether the tx is in the Blockfrost mempool, which
+// only holds txs submitted through Blockfrost.
+func (c *Client) MempoolHasTx(txHash string) (bool, error) {
+	_, err := c.do(context.Background(), http.MethodGet, "/mempool/"+txHash, "", nil)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("fail to query mempool: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,217 @@
+package blockfrost
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/minswap/pab-go/ledger"
+	"github.com/minswap/pab-go/provider"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testTxID   = "5ca53b0eb10f317a5f1bf1bda679a04a8dd01c156643deee1d406ec2cc15e9e3"
+	testPolicy = "29d222ce763455e3d7a09a665ce554f00ac89d2e99a1a83d267170c6"
+	testAddr   = "addr_test1vz3ppzmmzuz0nlsjeyrqjm4pvdxl3cyfe8x06eg6htj2gwgv02qjt"
+)
+
+// replay serves the recorded response in testdata for each path.
+func replay(t *testing.T, routes map[string]string) *Client {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("project_id") != "preprodTest" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		file, ok := routes[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"status_code": 404, "error": "Not Found", "message": "The requested component has not been found."}`)
+			return
+		}
+		b, err := os.ReadFile("testdata/" + file)
+		if err != nil {
+			t.Error(err)
+		}
+		_, _ = w.Write(b)
+	}))
+	t.Cleanup(srv.Close)
+	return New(Options{BaseURL: srv.URL, ProjectID: "preprodTest"})
+}
+
+func TestGetTip(t *testing.T) {
+	c := replay(t, map[string]string{"/blocks/latest": "blocks_latest.json"})
+	tip, err := c.GetTip()
+	if assert.NoError(t, err) {
+		assert.Equal(t, &provider.Tip{
+			Epoch:        171,
+			Hash:         "5ea2d0b4bbc2b5d8f3f7e8aeb1fd08f4a4f1e5ac2fd02d57d8f6f1d83bc0e0fd",
+			Slot:         74159190,
+			Block:        2934071,
+			SyncProgress: "100.00",
+		}, tip)
+	}
+}
+
+func TestGetProtocolParams(t *testing.T) {
+	assert := assert.New(t)
+	c := replay(t, map[string]string{"/epochs/latest/parameters": "epochs_latest_parameters.json"})
+	params, err := c.GetProtocolParams()
+	if assert.NoError(err) {
+		assert.Equal(int64(44), params.TxFeePerByte)
+		assert.Equal(int64(155381), params.TxFeeFixed)
+		assert.Equal(int64(16384), params.MaxTxSize)
+		assert.Equal(int64(5000), params.MaxValueSize)
+		assert.Equal(int64(4310), params.UtxoCostPerByte)
+		assert.Equal(int64(2_000_000), params.StakeAddressDeposit)
+		assert.Equal(int64(150), params.CollateralPercentage)
+		assert.Equal(3, params.MaxCollateralInputs)
+		assert.Equal("577/10000", params.ExecutionUnitPrices.PriceMemory.String())
+		assert.Equal("721/10000000", params.ExecutionUnitPrices.PriceSteps.String())
+		assert.Equal("15/1", params.MinFeeRefScriptCostPerByte.String())
+		assert.Equal([]int64{100788, 420}, params.CostModels[ledger.PlutusV3])
+	}
+}
+
+func TestGetUtxosByTxIns(t *testing.T) {
+	assert := assert.New(t)
+	c := replay(t, map[string]string{"/txs/" + testTxID + "/utxos": "txs_utxos.json"})
+	utxos, err := c.GetUtxosByTxIns(
+		ledger.OutRef{TxID: testTxID, TxIndex: 0},
+		// spent
+		ledger.OutRef{TxID: testTxID, TxIndex: 1},
+		ledger.OutRef{TxID: testTxID, TxIndex: 2},
+		// unknown tx
+		ledger.OutRef{TxID: "81e8dc4d3cbd3ea6c2afb8e5f9c9e06bb1cc2b3a8c6c4b6a73e2fd0b2dce7d66", TxIndex: 0},
+	)
+	if assert.NoError(err) && assert.Len(utxos, 1) {
+		u := utxos[0]
+		assert.Equal(ledger.OutRef{TxID: testTxID, TxIndex: 0}, u.OutRef())
+		assert.Equal(testAddr, u.Address)
+		assert.Equal(big.NewInt(2_000_000), u.Value[ledger.ADA])
+		amount, _ := new(big.Int).SetString("18446744073709551616", 10)
+		assert.Equal(amount, u.Value[ledger.NewAsset(testPolicy, "4d494e")])
+		if assert.NotNil(u.DatumHash) {
+			assert.Equal("923918e403bf43c34b4ef6b48eb2ee04babed17320d8d1b9ff9ad086e86f44ec", *u.DatumHash)
+		}
+	}
+}
+
+func TestGetUtxosByAddressesPagination(t *testing.T) {
+	assert := assert.New(t)
+	var pages []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/addresses/"+testAddr+"/utxos" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+		n := PageSize
+		if page == "2" {
+			n = 1
+		}
+		utxos := make([]map[string]interface{}, n)
+		for i := range utxos {
+			utxos[i] = map[string]interface{}{
+				"address":      testAddr,
+				"tx_hash":      testTxID,
+				"output_index": len(pages)*PageSize + i,
+				"amount":       []amount{{Unit: "lovelace", Quantity: "1000000"}},
+			}
+		}
+		_ = json.NewEncoder(w).Encode(utxos)
+	}))
+	defer srv.Close()
+
+	c := New(Options{BaseURL: srv.URL})
+	utxos, err := c.GetUtxosByAddresses(testAddr, "addr_test1unused")
+	if assert.NoError(err) {
+		assert.Len(utxos, PageSize+1)
+		assert.Equal([]string{"1", "2"}, pages)
+	}
+}
+
+func TestRateLimitBackoff(t *testing.T) {
+	assert := assert.New(t)
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests <= 2 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"status_code": 429, "error": "Project Over Limit", "message": "Usage is over limit."}`)
+			return
+		}
+		fmt.Fprint(w, `{"cbor": "d87980"}`)
+	}))
+	defer srv.Close()
+
+	c := New(Options{BaseURL: srv.URL, MaxRetries: 2, Backoff: 1})
+	datum, err := c.GetDatum("923918e403bf43c34b4ef6b48eb2ee04babed17320d8d1b9ff9ad086e86f44ec")
+	if assert.NoError(err) {
+		assert.Equal([]byte{0xd8, 0x79, 0x80}, datum)
+		assert.Equal(3, requests)
+	}
+
+	requests = 0
+	c.MaxRetries = 1
+	_, err = c.GetDatum("923918e403bf43c34b4ef6b48eb2ee04babed17320d8d1b9ff9ad086e86f44ec")
+	var apiErr *APIError
+	if assert.True(errors.As(err, &apiErr)) {
+		assert.Equal(http.StatusTooManyRequests, apiErr.StatusCode)
+		assert.Equal("Project Over Limit", apiErr.Err)
+	}
+
+	// retries are on by default
+	requests = 0
+	assert.Equal(3, New(Options{BaseURL: srv.URL}).MaxRetries)
+	c = New(Options{BaseURL: srv.URL, MaxRetries: -1, Backoff: 1})
+	_, err = c.GetDatum("923918e403bf43c34b4ef6b48eb2ee04babed17320d8d1b9ff9ad086e86f44ec")
+	assert.Error(err)
+	assert.Equal(1, requests)
+}
+
+func TestSubmitTx(t *testing.T) {
+	assert := assert.New(t)
+	var body []byte
+	var contentType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		contentType = r.Header.Get("Content-Type")
+		if len(body) > 2 {
+			b, _ := os.ReadFile("testdata/tx_submit_error.json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write(b)
+			return
+		}
+		fmt.Fprintf(w, "%q", testTxID)
+	}))
+	defer srv.Close()
+
+	c := New(Options{BaseURL: srv.URL})
+	assert.NoError(c.SubmitTx(&provider.Tx{TxBody: "84a0"}))
+	assert.Equal([]byte{0x84, 0xa0}, body)
+	assert.Equal("application/cbor", contentType)
+
+	err := c.SubmitTx(&provider.Tx{TxBody: "84a0f5f6"})
+	var submitErr *provider.SubmitError
+	if assert.True(errors.As(err, &submitErr)) {
+		assert.Equal(provider.SubmitErrorFeeTooSmall, submitErr.Kind)
+	}
+}
+
+func TestMempoolHasTx(t *testing.T) {
+	c := replay(t, map[string]string{"/mempool/" + testTxID: "mempool_tx.json"})
+	exists, err := c.MempoolHasTx(testTxID)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = c.MempoolHasTx(testPolicy)
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
@@ -0,0 +1,108 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/minswap/pab-go/ledger"
+)
+
+const (
+	DefaultChunkSize   = 100
+	DefaultConcurrency = 4
+)
+
+// ChunkOptions split a query of many addresses or out-refs into chunks of
+// ChunkSize items, run by at most Concurrency workers.
+type ChunkOptions struct {
+	ChunkSize   int
+	Concurrency int
+}
+
+// ChunkFailure is the error of the chunk of items [Start, End).
+type ChunkFailure struct {
+	Start int
+	End   int
+	Err   error
+}
+
+// ChunkError reports the failed chunks of a chunked query; the UTxOs of the
+// other chunks are still returned.
+type ChunkError struct {
+	Failures []ChunkFailure
+}
+
+func (e *ChunkError) Error() string {
+	msgs := make([]string, len(e.Failures))
+	for i, f := range e.Failures {
+		msgs[i] = fmt.Sprintf("items [%d, %d): %v", f.Start, f.End, f.Err)
+	}
+	return fmt.Sprintf("%d chunk(s) failed: %s", len(e.Failures), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the error of the first failed chunk.
+func (e *ChunkError) Unwrap() error {
+	return e.Failures[0].Err
+}
+
+// QueryChunked queries n items by chunks, calling query with the bounds of
+// each chunk, and merges the results without duplicates in chunk order.
+func QueryChunked(n int, options ChunkOptions, query func(start, end int) ([]ledger.Utxo, error)) ([]ledger.Utxo, error) {
+	size := options.ChunkSize
+	if size <= 0 {
+		size = DefaultChunkSize
+	}
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	chunks := (n + size - 1) / size
+	results := make([][]ledger.Utxo, chunks)
+	errs := make([]error, chunks)
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < chunks; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				end := (i + 1) * size
+				if end > n {
+					end = n
+				}
+				results[i], errs[i] = query(i*size, end)
+			}
+		}()
+	}
+	for i := 0; i < chunks; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	utxos := make([]ledger.Utxo, 0)
+	seen := make(map[ledger.OutRef]bool)
+	var chunkErr ChunkError
+	for i, res := range results {
+		if errs[i] != nil {
+			end := (i + 1) * size
+			if end > n {
+				end = n
+			}
+			chunkErr.Failures = append(chunkErr.Failures, ChunkFailure{Start: i * size, End: end, Err: errs[i]})
+			continue
+		}
+		for _, u := range res {
+			if !seen[u.OutRef()] {
+				seen[u.OutRef()] = true
+				utxos = append(utxos, u)
+			}
+		}
+	}
+	if len(chunkErr.Failures) > 0 {
+		return utxos, &chunkErr
+	}
+	return utxos, nil
+}
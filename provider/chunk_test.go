@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"testing"
+
+	"github.com/minswap/pab-go/ledger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryChunked(t *testing.T) {
+	assert := assert.New(t)
+	var running, maxRunning int32
+	var chunks [][2]int
+	results := make(chan [2]int, 100)
+	query := func(start, end int) ([]ledger.Utxo, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+				break
+			}
+		}
+		results <- [2]int{start, end}
+		if start == 20 {
+			return nil, errors.New("argument list too long")
+		}
+		var utxos []ledger.Utxo
+		for i := start; i < end; i++ {
+			// consecutive items share a utxo across chunk bounds
+			utxos = append(utxos, ledger.Utxo{TxID: testTxID, TxIndex: i / 2, Value: ledger.NewValue().Add(ledger.ADA, big.NewInt(1))})
+		}
+		return utxos, nil
+	}
+
+	utxos, err := QueryChunked(45, ChunkOptions{ChunkSize: 10, Concurrency: 2}, query)
+	close(results)
+	for c := range results {
+		chunks = append(chunks, c)
+	}
+	assert.ElementsMatch([][2]int{{0, 10}, {10, 20}, {20, 30}, {30, 40}, {40, 45}}, chunks)
+	assert.LessOrEqual(maxRunning, int32(2))
+
+	var chunkErr *ChunkError
+	if assert.True(errors.As(err, &chunkErr)) && assert.Len(chunkErr.Failures, 1) {
+		assert.Equal(20, chunkErr.Failures[0].Start)
+		assert.Equal(30, chunkErr.Failures[0].End)
+		assert.Equal("1 chunk(s) failed: items [20, 30): argument list too long", err.Error())
+	}
+	// items 0-19 and 30-44 give utxos 0-9 and 15-22 once each
+	if assert.Len(utxos, 18) {
+		assert.Equal(0, utxos[0].TxIndex)
+		assert.Equal(9, utxos[9].TxIndex)
+		assert.Equal(15, utxos[10].TxIndex)
+	}
+
+	utxos, err = QueryChunked(0, ChunkOptions{}, query)
+	assert.NoError(err)
+	assert.Empty(utxos)
+}
@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+)
+
+type SubmitErrorKind int
+
+const (
+	SubmitErrorUnknown SubmitErrorKind = iota
+	// SubmitErrorInputsSpent means an input does not exist or is already spent.
+	SubmitErrorInputsSpent
+	SubmitErrorValueNotConserved
+	SubmitErrorFeeTooSmall
+	SubmitErrorOutsideValidityInterval
+	SubmitErrorScriptFailure
+	SubmitErrorTxTooLarge
+	SubmitErrorInsufficientCollateral
+	SubmitErrorMissingWitnesses
+	SubmitErrorOutputTooSmall
+)
+
+func (k SubmitErrorKind) String() string {
+	switch k {
+	case SubmitErrorInputsSpent:
+		return "inputs spent"
+	case SubmitErrorValueNotConserved:
+		return "value not conserved"
+	case SubmitErrorFeeTooSmall:
+		return "fee too small"
+	case SubmitErrorOutsideValidityInterval:
+		return "outside validity interval"
+	case SubmitErrorScriptFailure:
+		return "script failure"
+	case SubmitErrorTxTooLarge:
+		return "tx too large"
+	case SubmitErrorInsufficientCollateral:
+		return "insufficient collateral"
+	case SubmitErrorMissingWitnesses:
+		return "missing witnesses"
+	case SubmitErrorOutputTooSmall:
+		return "output too small"
+	default:
+		return "unknown"
+	}
+}
+
+// SubmitError is a transaction rejected by the node. Message is the raw
+// rejection reason and Err the underlying error, if any.
+type SubmitError struct {
+	Kind    SubmitErrorKind
+	Message string
+	Err     error
+}
+
+func (e *SubmitError) Error() string {
+	return fmt.Sprintf("tx rejected (%s): %s", e.Kind, e.Message)
+}
+
+func (e *SubmitError) Unwrap() error {
+	return e.Err
+}
+
+// ledgerErrorKinds maps constructors of ledger predicate failures, as
+// printed by cardano-cli, to error kinds. Earlier entries win.
+var ledgerErrorKinds = []struct {
+	constructor string
+	kind        SubmitErrorKind
+}{
+	{"BadInputsUTxO", SubmitErrorInputsSpent},
+	{"ValueNotConservedUTxO", SubmitErrorValueNotConserved},
+	{"FeeTooSmallUTxO", SubmitErrorFeeTooSmall},
+	{"OutsideValidityIntervalUTxO", SubmitErrorOutsideValidityInterval},
+	{"ValidationTagMismatch", SubmitErrorScriptFailure},
+	{"CollectErrors", SubmitErrorScriptFailure},
+	{"ScriptWitnessNotValidatingUTXOW", SubmitErrorScriptFailure},
+	{"MaxTxSizeUTxO", SubmitErrorTxTooLarge},
+	{"InsufficientCollateral", SubmitErrorInsufficientCollateral},
+	{"NoCollateralInputs", SubmitErrorInsufficientCollateral},
+	{"MissingVKeyWitnessesUTXOW", SubmitErrorMissingWitnesses},
+	{"MissingScriptWitnessesUTXOW", SubmitErrorMissingWitnesses},
+	{"OutputTooSmallUTxO", SubmitErrorOutputTooSmall},
+	{"BabbageOutputTooSmallUTxO", SubmitErrorOutputTooSmall},
+}
+
+// ClassifyLedgerError returns the kind of a rejection printed by the node.
+func ClassifyLedgerError(message string) SubmitErrorKind {
+	for _, e := range ledgerErrorKinds {
+		if strings.Contains(message, e.constructor) {
+			return e.kind
+		}
+	}
+	return SubmitErrorUnknown
+}
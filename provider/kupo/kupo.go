@@ -0,0 +1,117 @@
+// Package kupo queries unspent outputs by pattern from a Kupo indexer.
+package kupo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/minswap/pab-go/ledger"
+	"github.com/minswap/pab-go/provider"
+)
+
+var _ provider.PatternQuerier = (*Client)(nil)
+
+type Client struct {
+	// BaseURL is the URL of Kupo, e.g. http://localhost:1442.
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+func New(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/"), HTTPClient: http.DefaultClient}
+}
+
+type match struct {
+	TransactionID string `json:"transaction_id"`
+	OutputIndex   int    `json:"output_index"`
+	Address       string `json:"address"`
+	Value         struct {
+		Coins  json.Number            `json:"coins"`
+		Assets map[string]json.Number `json:"assets"`
+	} `json:"value"`
+	DatumHash *string `json:"datum_hash"`
+	DatumType string  `json:"datum_type"`
+}
+
+func (m match) ledgerUtxo() (ledger.Utxo, error) {
+	val := ledger.NewValue()
+	coins, ok := new(big.Int).SetString(m.Value.Coins.String(), 10)
+	if !ok {
+		return ledger.Utxo{}, fmt.Errorf("invalid coins: %s", m.Value.Coins)
+	}
+	val.Add(ledger.ADA, coins)
+	for unit, amount := range m.Value.Assets {
+		// assets with an empty name are keyed by their policy only
+		asset, err := ledger.AssetFromString(unit)
+		if err != nil {
+			return ledger.Utxo{}, fmt.Errorf("invalid asset %s: %w", unit, err)
+		}
+		n, ok := new(big.Int).SetString(amount.String(), 10)
+		if !ok {
+			return ledger.Utxo{}, fmt.Errorf("invalid amount of %s: %s", unit, amount)
+		}
+		val.Add(asset, n)
+	}
+	utxo := ledger.Utxo{
+		TxID:    m.TransactionID,
+		TxIndex: m.OutputIndex,
+		Address: m.Address,
+		Value:   val,
+	}
+	// the datum hash of an inline datum does not go in the output
+	if m.DatumType == "hash" {
+		utxo.DatumHash = m.DatumHash
+	}
+	return utxo, nil
+}
+
+func (c *Client) matches(pattern string) ([]ledger.Utxo, error) {
+	resp, err := c.HTTPClient.Get(c.BaseURL + "/matches/" + pattern + "?unspent")
+	if err != nil {
+		return nil, fmt.Errorf("fail to query kupo: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read kupo response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("kupo error %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var result []match
+	if err := decoder.Decode(&result); err != nil {
+		return nil, fmt.Errorf("fail to decode kupo matches: %w", err)
+	}
+	utxos := make([]ledger.Utxo, 0, len(result))
+	for _, m := range result {
+		u, err := m.ledgerUtxo()
+		if err != nil {
+			return nil, fmt.Errorf("fail to parse match %s#%d: %w", m.TransactionID, m.OutputIndex, err)
+		}
+		utxos = append(utxos, u)
+	}
+	return utxos, nil
+}
+
+func (c *Client) GetUtxosByPattern(pattern provider.Pattern) ([]ledger.Utxo, error) {
+	return c.matches(pattern.String())
+}
+
+func (c *Client) GetUtxosByAddresses(addresses ...string) ([]ledger.Utxo, error) {
+	var utxos []ledger.Utxo
+	for _, addr := range addresses {
+		matches, err := c.matches(addr)
+		if err != nil {
+			return nil, err
+		}
+		utxos = append(utxos, matches...)
+	}
+	return utxos, nil
+}
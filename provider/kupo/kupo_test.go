@@ -0,0 +1,87 @@
+package kupo
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/minswap/pab-go/ledger"
+	"github.com/minswap/pab-go/provider"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testTxID   = "5ca53b0eb10f317a5f1bf1bda679a04a8dd01c156643deee1d406ec2cc15e9e3"
+	testPolicy = "29d222ce763455e3d7a09a665ce554f00ac89d2e99a1a83d267170c6"
+	testAddr   = "addr_test1vz3ppzmmzuz0nlsjeyrqjm4pvdxl3cyfe8x06eg6htj2gwgv02qjt"
+	testDatum  = "923918e403bf43c34b4ef6b48eb2ee04babed17320d8d1b9ff9ad086e86f44ec"
+)
+
+const testMatches = `[
+  {
+    "transaction_index": 3,
+    "transaction_id": "` + testTxID + `",
+    "output_index": 0,
+    "address": "` + testAddr + `",
+    "value": {"coins": 2000000, "assets": {"` + testPolicy + `.4d494e": 18446744073709551616, "` + testPolicy + `": 1}},
+    "datum_hash": "` + testDatum + `",
+    "datum_type": "hash",
+    "script_hash": null,
+    "created_at": {"slot_no": 74159190, "header_hash": "5ea2d0b4bbc2b5d8f3f7e8aeb1fd08f4a4f1e5ac2fd02d57d8f6f1d83bc0e0fd"},
+    "spent_at": null
+  },
+  {
+    "transaction_index": 3,
+    "transaction_id": "` + testTxID + `",
+    "output_index": 1,
+    "address": "` + testAddr + `",
+    "value": {"coins": 5000000, "assets": {}},
+    "datum_hash": "` + testDatum + `",
+    "datum_type": "inline",
+    "script_hash": null,
+    "created_at": {"slot_no": 74159190, "header_hash": "5ea2d0b4bbc2b5d8f3f7e8aeb1fd08f4a4f1e5ac2fd02d57d8f6f1d83bc0e0fd"},
+    "spent_at": null
+  }
+]`
+
+func TestGetUtxosByPattern(t *testing.T) {
+	assert := assert.New(t)
+	var requested string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.RequestURI()
+		if r.URL.Path == "/matches/bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"hint": "Invalid pattern!"}`)
+			return
+		}
+		fmt.Fprint(w, testMatches)
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL + "/")
+	utxos, err := c.GetUtxosByPattern(provider.PolicyPattern(testPolicy))
+	assert.Equal("/matches/"+testPolicy+".*?unspent", requested)
+	if assert.NoError(err) && assert.Len(utxos, 2) {
+		amount, _ := new(big.Int).SetString("18446744073709551616", 10)
+		assert.Equal(ledger.NewValue().
+			Add(ledger.ADA, big.NewInt(2_000_000)).
+			Add(ledger.NewAsset(testPolicy, "4d494e"), amount).
+			Add(ledger.NewAsset(testPolicy, ""), big.NewInt(1)), utxos[0].Value)
+		if assert.NotNil(utxos[0].DatumHash) {
+			assert.Equal(testDatum, *utxos[0].DatumHash)
+		}
+		assert.Equal(ledger.OutRef{TxID: testTxID, TxIndex: 1}, utxos[1].OutRef())
+		assert.Nil(utxos[1].DatumHash)
+	}
+
+	_, err = c.GetUtxosByAddresses(testAddr)
+	assert.NoError(err)
+	assert.Equal("/matches/"+testAddr+"?unspent", requested)
+
+	_, err = c.matches("bad")
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "Invalid pattern!")
+	}
+}
@@ -0,0 +1,422 @@
+// Package n2c is a ChainProvider speaking the Ouroboros node-to-client
+// mini-protocols over the local socket of cardano-node, without spawning
+// cardano-cli.
+package n2c
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/minswap/pab-go/address"
+	"github.com/minswap/pab-go/cbor"
+	"github.com/minswap/pab-go/ledger"
+	"github.com/minswap/pab-go/provider"
+)
+
+var (
+	_ provider.ChainProvider  = (*Client)(nil)
+	_ provider.MempoolQuerier = (*Client)(nil)
+)
+
+// Versions are the node-to-client versions proposed in the handshake, V16
+// to V19, all of which carry [network magic, query] parameters.
+var Versions = []uint64{32784, 32785, 32786, 32787}
+
+// EraNames are the eras in hard fork order.
+var EraNames = []string{"Byron", "Shelley", "Allegra", "Mary", "Alonzo", "Babbage", "Conway"}
+
+// DefaultTimeout bounds each exchange with the node.
+const DefaultTimeout = 30 * time.Second
+
+// AwaitTxInterval is how often AwaitTx queries the node.
+var AwaitTxInterval = 2 * time.Second
+
+var ErrHandshakeRefused = errors.New("n2c: handshake refused")
+
+type Client struct {
+	mu      sync.Mutex
+	conn    net.Conn
+	mux     *mux
+	Version uint64
+	Timeout time.Duration
+	// err is the protocol error after which the connection is unusable.
+	err error
+}
+
+// Dial connects to the node socket, e.g. /opt/cardano/ipc/node.socket.
+func Dial(socketPath string, networkMagic uint32) (*Client, error) {
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("fail to connect to node socket: %w", err)
+	}
+	c, err := NewClient(conn, networkMagic)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewClient runs the handshake on conn.
+func NewClient(conn net.Conn, networkMagic uint32) (*Client, error) {
+	c := &Client{conn: conn, mux: newMux(conn), Timeout: DefaultTimeout}
+	versions := make(cbor.Map, len(Versions))
+	for i, v := range Versions {
+		versions[i] = cbor.MapEntry{Key: v, Value: []interface{}{uint64(networkMagic), false}}
+	}
+	err := c.exchange(func() error {
+		if err := c.mux.send(protocolHandshake, []interface{}{uint64(0), versions}); err != nil {
+			return err
+		}
+		tag, msg, err := c.mux.receive(protocolHandshake)
+		if err != nil {
+			return err
+		}
+		switch {
+		case tag == 1 && len(msg) == 3:
+			c.Version, _ = msg[1].(uint64)
+			return nil
+		case tag == 2 && len(msg) == 2:
+			return fmt.Errorf("%w: %v", ErrHandshakeRefused, msg[1])
+		default:
+			return fmt.Errorf("unexpected handshake message: %v", msg)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fail to handshake: %w", err)
+	}
+	return c, nil
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// exchange runs fn alone on the connection within the timeout. A failed
+// exchange leaves a mini-protocol in an unknown state, so the connection is
+// closed.
+func (c *Client) exchange(fn func() error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	_ = c.conn.SetDeadline(time.Now().Add(c.Timeout))
+	if err := fn(); err != nil {
+		c.err = fmt.Errorf("n2c: connection closed after error: %w", err)
+		c.conn.Close()
+		return err
+	}
+	return nil
+}
+
+// LocalStateQuery messages.
+const (
+	msgAcquired           uint64 = 1
+	msgFailure            uint64 = 2
+	msgQuery              uint64 = 3
+	msgResult             uint64 = 4
+	msgRelease            uint64 = 5
+	msgAcquireVolatileTip uint64 = 8
+)
+
+// stateQuery acquires the volatile tip, then calls fn with a function
+// running queries on that state.
+func (c *Client) stateQuery(fn func(query func(q []interface{}) (interface{}, error)) error) error {
+	return c.exchange(func() error {
+		if err := c.mux.send(protocolLocalStateQuery, []interface{}{msgAcquireVolatileTip}); err != nil {
+			return err
+		}
+		tag, msg, err := c.mux.receive(protocolLocalStateQuery)
+		if err != nil {
+			return err
+		}
+		if tag == msgFailure {
+			return fmt.Errorf("fail to acquire state: %v", msg[1:])
+		}
+		if tag != msgAcquired {
+			return fmt.Errorf("unexpected state query message: %v", msg)
+		}
+		err = fn(func(q []interface{}) (interface{}, error) {
+			if err := c.mux.send(protocolLocalStateQuery, []interface{}{msgQuery, q}); err != nil {
+				return nil, err
+			}
+			tag, msg, err := c.mux.receive(protocolLocalStateQuery)
+			if err != nil {
+				return nil, err
+			}
+			if tag != msgResult || len(msg) != 2 {
+				return nil, fmt.Errorf("unexpected state query message: %v", msg)
+			}
+			return msg[1], nil
+		})
+		if err != nil {
+			return err
+		}
+		return c.mux.send(protocolLocalStateQuery, []interface{}{msgRelease})
+	})
+}
+
+func blockQuery(q interface{}) []interface{} {
+	return []interface{}{uint64(0), q}
+}
+
+func hardForkQuery(q ...interface{}) []interface{} {
+	return blockQuery([]interface{}{uint64(2), q})
+}
+
+// eraQuery is a query of the current era, whose result is wrapped in a
+// one-item list, or a two-item era mismatch.
+func eraQuery(era uint64, q ...interface{}) []interface{} {
+	return blockQuery([]interface{}{uint64(0), []interface{}{era, q}})
+}
+
+var (
+	queryCurrentEra   = hardForkQuery(uint64(1))
+	queryEraHistory   = hardForkQuery(uint64(0))
+	queryChainBlockNo = []interface{}{uint64(2)}
+	queryChainPoint   = []interface{}{uint64(3)}
+)
+
+// Shelley-based era queries.
+const (
+	queryEpochNo        uint64 = 1
+	queryCurrentPParams uint64 = 3
+	queryUtxoByAddress  uint64 = 6
+	queryUtxoByTxIn     uint64 = 15
+)
+
+func currentEra(query func([]interface{}) (interface{}, error)) (uint64, error) {
+	res, err := query(queryCurrentEra)
+	if err != nil {
+		return 0, fmt.Errorf("fail to query era: %w", err)
+	}
+	era, ok := res.(uint64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected era: %v", res)
+	}
+	return era, nil
+}
+
+func (c *Client) currentEra() (uint64, error) {
+	var era uint64
+	err := c.stateQuery(func(query func([]interface{}) (interface{}, error)) error {
+		var err error
+		era, err = currentEra(query)
+		return err
+	})
+	return era, err
+}
+
+// inEra runs a query of the current era and unwraps its result.
+func inEra(query func([]interface{}) (interface{}, error), q ...interface{}) (interface{}, error) {
+	era, err := currentEra(query)
+	if err != nil {
+		return nil, err
+	}
+	res, err := query(eraQuery(era, q...))
+	if err != nil {
+		return nil, err
+	}
+	wrapped, ok := res.([]interface{})
+	if !ok || len(wrapped) != 1 {
+		return nil, fmt.Errorf("era mismatch: %v", res)
+	}
+	return wrapped[0], nil
+}
+
+func (c *Client) GetTip() (*provider.Tip, error) {
+	tip := &provider.Tip{}
+	err := c.stateQuery(func(query func([]interface{}) (interface{}, error)) error {
+		era, err := currentEra(query)
+		if err != nil {
+			return err
+		}
+		if int(era) < len(EraNames) {
+			tip.Era = EraNames[era]
+		}
+		res, err := query(queryChainPoint)
+		if err != nil {
+			return fmt.Errorf("fail to query chain point: %w", err)
+		}
+		// the point is [] at origin
+		if point, ok := res.([]interface{}); ok && len(point) == 2 {
+			slot, err := toInt64(point[0])
+			if err != nil {
+				return fmt.Errorf("invalid tip slot: %w", err)
+			}
+			hash, err := toBytes(point[1])
+			if err != nil {
+				return fmt.Errorf("invalid tip hash: %w", err)
+			}
+			tip.Slot, tip.Hash = int(slot), hex.EncodeToString(hash)
+		}
+		res, err = query(queryChainBlockNo)
+		if err != nil {
+			return fmt.Errorf("fail to query block number: %w", err)
+		}
+		// the block number is [0] at origin and [1, n] after
+		if blockNo, ok := res.([]interface{}); ok && len(blockNo) == 2 {
+			n, err := toInt64(blockNo[1])
+			if err != nil {
+				return fmt.Errorf("invalid block number: %w", err)
+			}
+			tip.Block = int(n)
+		}
+		if era == 0 {
+			return nil
+		}
+		res, err = query(eraQuery(era, queryEpochNo))
+		if err != nil {
+			return fmt.Errorf("fail to query epoch: %w", err)
+		}
+		if wrapped, ok := res.([]interface{}); ok && len(wrapped) == 1 {
+			epoch, err := toInt64(wrapped[0])
+			if err != nil {
+				return fmt.Errorf("invalid epoch: %w", err)
+			}
+			tip.Epoch = int(epoch)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fail to query tip: %w", err)
+	}
+	return tip, nil
+}
+
+func (c *Client) GetProtocolParams() (*ledger.ProtocolParams, error) {
+	var params *ledger.ProtocolParams
+	err := c.stateQuery(func(query func([]interface{}) (interface{}, error)) error {
+		res, err := inEra(query, queryCurrentPParams)
+		if err != nil {
+			return err
+		}
+		params, err = decodeProtocolParams(res)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fail to query protocol parameters: %w", err)
+	}
+	return params, nil
+}
+
+// GetEraHistory returns the era summaries, to convert slots to time.
+func (c *Client) GetEraHistory() ([]EraSummary, error) {
+	var summaries []EraSummary
+	err := c.stateQuery(func(query func([]interface{}) (interface{}, error)) error {
+		res, err := query(queryEraHistory)
+		if err != nil {
+			return err
+		}
+		summaries, err = decodeEraHistory(res)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fail to query era history: %w", err)
+	}
+	return summaries, nil
+}
+
+func (c *Client) queryUtxo(q ...interface{}) ([]ledger.Utxo, error) {
+	var utxos []ledger.Utxo
+	err := c.stateQuery(func(query func([]interface{}) (interface{}, error)) error {
+		res, err := inEra(query, q...)
+		if err != nil {
+			return err
+		}
+		utxos, err = decodeUtxo(res)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fail to query utxo: %w", err)
+	}
+	return utxos, nil
+}
+
+func (c *Client) GetUtxosByAddresses(addresses ...string) ([]ledger.Utxo, error) {
+	addrs := make([]interface{}, len(addresses))
+	for i, s := range addresses {
+		addr, err := address.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %s: %w", s, err)
+		}
+		addrs[i] = addr.Bytes()
+	}
+	return c.queryUtxo(queryUtxoByAddress, addrs)
+}
+
+func (c *Client) GetUtxosByTxIns(txIns ...ledger.OutRef) ([]ledger.Utxo, error) {
+	ins := make([]interface{}, len(txIns))
+	for i, in := range txIns {
+		txID, err := hex.DecodeString(in.TxID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tx id %s: %w", in.TxID, err)
+		}
+		ins[i] = []interface{}{txID, uint64(in.TxIndex)}
+	}
+	return c.queryUtxo(queryUtxoByTxIn, ins)
+}
+
+// LocalTxSubmission messages.
+const (
+	msgSubmitTx uint64 = 0
+	msgAcceptTx uint64 = 1
+	msgRejectTx uint64 = 2
+)
+
+// SubmitTx submits a tx of the current era. A rejection is a SubmitError
+// classified by the ledger failures of its reason, which keeps the hex CBOR.
+func (c *Client) SubmitTx(tx *provider.Tx) error {
+	b, err := hex.DecodeString(tx.TxBody)
+	if err != nil {
+		return fmt.Errorf("fail to decode tx: %w", err)
+	}
+	era, err := c.currentEra()
+	if err != nil {
+		return fmt.Errorf("fail to submit tx: %w", err)
+	}
+	var rejection *provider.SubmitError
+	err = c.exchange(func() error {
+		msg := []interface{}{msgSubmitTx, []interface{}{era, cbor.Tag{Number: cbor.TagEncodedCBOR, Content: b}}}
+		if err := c.mux.send(protocolLocalTxSubmit, msg); err != nil {
+			return err
+		}
+		tag, reply, err := c.mux.receive(protocolLocalTxSubmit)
+		if err != nil {
+			return err
+		}
+		switch {
+		case tag == msgAcceptTx:
+			return nil
+		case tag == msgRejectTx && len(reply) == 2:
+			rejection, err = submitError(reply[1])
+			return err
+		default:
+			return fmt.Errorf("unexpected tx submission message: %v", reply)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("fail to submit tx: %w", err)
+	}
+	if rejection != nil {
+		return fmt.Errorf("fail to submit tx: %w", rejection)
+	}
+	return nil
+}
+
+// AwaitTx waits for the first output of the tx to appear in the UTxO set.
+func (c *Client) AwaitTx(ctx context.Context, txHash string) error {
+	return provider.PollTx(ctx, AwaitTxInterval, func() (bool, error) {
+		utxos, err := c.GetUtxosByTxIns(ledger.OutRef{TxID: txHash, TxIndex: 0})
+		if err != nil {
+			return false, err
+		}
+		return len(utxos) > 0, nil
+	})
+}
@@ -0,0 +1,390 @@
+package n2c
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minswap/pab-go/address"
+	"github.com/minswap/pab-go/cbor"
+	"github.com/minswap/pab-go/ledger"
+	"github.com/minswap/pab-go/provider"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testTxID   = "5ca53b0eb10f317a5f1bf1bda679a04a8dd01c156643deee1d406ec2cc15e9e3"
+	testPolicy = "29d222ce763455e3d7a09a665ce554f00ac89d2e99a1a83d267170c6"
+	testHash   = "76b0d16f5d09ac02dd1786981066f6fedf7ac165a08b3b6f0fb33f03"
+	testDatum  = "923918e403bf43c34b4ef6b48eb2ee04babed17320d8d1b9ff9ad086e86f44ec"
+)
+
+// Requests as the node receives them, in hex, and the messages it replies.
+const (
+	reqPropose      = "8200a41980108201f41980118201f41980128201f41980138201f4"
+	reqAcquire      = "8108"
+	reqCurrentEra   = "8203820082028101"
+	reqChainPoint   = "82038103"
+	reqBlockNo      = "82038102"
+	reqEpoch        = "82038200820082068101"
+	reqPParams      = "82038200820082068103"
+	reqEraHistory   = "8203820082028100"
+	reqMonitorAcq   = "8101"
+	reqMonitorSizes = "8109"
+	reqMonitorNext  = "8105"
+)
+
+// node replays scripted replies to the requests of each mini-protocol,
+// splitting them in small segments like a busy multiplexer.
+type node struct {
+	t       *testing.T
+	replies map[uint16]map[string]interface{}
+}
+
+func hexBytes(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func (n *node) serve(conn net.Conn) {
+	defer conn.Close()
+	pending := make(map[uint16][]byte)
+	for {
+		var header [8]byte
+		if _, err := io.ReadFull(conn, header[:]); err != nil {
+			return
+		}
+		protocol := binary.BigEndian.Uint16(header[4:6])
+		payload := make([]byte, binary.BigEndian.Uint16(header[6:8]))
+		if _, err := io.ReadFull(conn, payload); err != nil {
+			return
+		}
+		pending[protocol] = append(pending[protocol], payload...)
+		for {
+			raw, rest, err := cbor.DecodeRaw(pending[protocol])
+			if err != nil {
+				break
+			}
+			pending[protocol] = rest
+			req := hex.EncodeToString(raw)
+			reply, ok := n.replies[protocol][req]
+			if !ok {
+				n.t.Errorf("unexpected request on protocol %d: %s", protocol, req)
+				return
+			}
+			if reply == nil {
+				continue
+			}
+			b, err := cbor.Marshal(reply)
+			if err != nil {
+				n.t.Error(err)
+				return
+			}
+			for len(b) > 0 {
+				size := len(b)
+				if size > 16 {
+					size = 16
+				}
+				segment := make([]byte, 8)
+				binary.BigEndian.PutUint16(segment[4:6], protocol|responderFlag)
+				binary.BigEndian.PutUint16(segment[6:8], uint16(size))
+				if _, err := conn.Write(append(segment, b[:size]...)); err != nil {
+					return
+				}
+				b = b[size:]
+			}
+		}
+	}
+}
+
+func dialNode(t *testing.T, replies map[uint16]map[string]interface{}) (*Client, error) {
+	socket := filepath.Join(t.TempDir(), "node.socket")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	n := &node{t: t, replies: replies}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go n.serve(conn)
+		}
+	}()
+	c, err := Dial(socket, 1)
+	if err == nil {
+		c.Timeout = 5 * time.Second
+		t.Cleanup(func() { c.Close() })
+	}
+	return c, err
+}
+
+func u(n uint64) uint64 { return n }
+
+func stateQuery(queries map[string]interface{}) map[string]interface{} {
+	replies := map[string]interface{}{
+		reqAcquire:    []interface{}{u(1)},
+		"8105":        nil,
+		reqCurrentEra: []interface{}{u(4), u(6)},
+	}
+	for req, result := range queries {
+		replies[req] = []interface{}{u(4), result}
+	}
+	return replies
+}
+
+func handshake(queries map[string]interface{}) map[uint16]map[string]interface{} {
+	return map[uint16]map[string]interface{}{
+		protocolHandshake:       {reqPropose: []interface{}{u(1), u(32787), []interface{}{u(1), false}}},
+		protocolLocalStateQuery: stateQuery(queries),
+	}
+}
+
+func rational(num, den uint64) cbor.Tag {
+	return cbor.Tag{Number: tagRational, Content: []interface{}{num, den}}
+}
+
+func TestHandshake(t *testing.T) {
+	c, err := dialNode(t, handshake(nil))
+	if assert.NoError(t, err) {
+		assert.Equal(t, uint64(32787), c.Version)
+	}
+
+	_, err = dialNode(t, map[uint16]map[string]interface{}{
+		protocolHandshake: {reqPropose: []interface{}{u(2), []interface{}{u(0), []interface{}{u(32783)}}}},
+	})
+	assert.True(t, errors.Is(err, ErrHandshakeRefused))
+}
+
+func TestGetTip(t *testing.T) {
+	hash := hexBytes(testDatum)
+	c, err := dialNode(t, handshake(map[string]interface{}{
+		reqChainPoint: []interface{}{u(74159190), hash},
+		reqBlockNo:    []interface{}{u(1), u(2934071)},
+		reqEpoch:      []interface{}{u(171)},
+	}))
+	if !assert.NoError(t, err) {
+		return
+	}
+	tip, err := c.GetTip()
+	if assert.NoError(t, err) {
+		assert.Equal(t, &provider.Tip{
+			Epoch: 171,
+			Hash:  testDatum,
+			Slot:  74159190,
+			Block: 2934071,
+			Era:   "Conway",
+		}, tip)
+	}
+}
+
+func TestGetUtxos(t *testing.T) {
+	assert := assert.New(t)
+	addr, err := address.NewEnterpriseAddress(address.Testnet, address.NewKeyCredential(testHash))
+	assert.NoError(err)
+	amount, _ := new(big.Int).SetString("18446744073709551616", 10)
+	utxo := cbor.Map{
+		{Key: []interface{}{hexBytes(testTxID), u(0)}, Value: cbor.Map{
+			{Key: u(0), Value: addr.Bytes()},
+			{Key: u(1), Value: []interface{}{u(2_000_000), cbor.Map{
+				{Key: hexBytes(testPolicy), Value: cbor.Map{{Key: hexBytes("4d494e"), Value: amount}}},
+			}}},
+			{Key: u(2), Value: []interface{}{u(0), hexBytes(testDatum)}},
+		}},
+		// a legacy output
+		{Key: []interface{}{hexBytes(testTxID), u(1)}, Value: []interface{}{addr.Bytes(), u(5_000_000)}},
+	}
+	byTxIn := "8203820082008206820f81825820" + testTxID + "00"
+	byAddress := "8203820082008206820681581d60" + testHash
+	c, err := dialNode(t, handshake(map[string]interface{}{
+		byTxIn:    []interface{}{utxo},
+		byAddress: []interface{}{utxo},
+	}))
+	if !assert.NoError(err) {
+		return
+	}
+
+	utxos, err := c.GetUtxosByTxIns(ledger.OutRef{TxID: testTxID, TxIndex: 0})
+	if assert.NoError(err) && assert.Len(utxos, 2) {
+		assert.Equal(ledger.OutRef{TxID: testTxID, TxIndex: 0}, utxos[0].OutRef())
+		assert.Equal(addr.String(), utxos[0].Address)
+		assert.Equal(ledger.NewValue().Add(ledger.ADA, big.NewInt(2_000_000)).Add(ledger.NewAsset(testPolicy, "4d494e"), amount), utxos[0].Value)
+		if assert.NotNil(utxos[0].DatumHash) {
+			assert.Equal(testDatum, *utxos[0].DatumHash)
+		}
+		assert.Equal(big.NewInt(5_000_000), utxos[1].Value[ledger.ADA])
+		assert.Nil(utxos[1].DatumHash)
+	}
+	utxos, err = c.GetUtxosByAddresses(addr.String())
+	assert.NoError(err)
+	assert.Len(utxos, 2)
+}
+
+func TestGetProtocolParams(t *testing.T) {
+	assert := assert.New(t)
+	pp := make([]interface{}, 31)
+	for i := range pp {
+		pp[i] = u(0)
+	}
+	pp[0], pp[1], pp[3], pp[5], pp[14] = u(44), u(155381), u(16384), u(2_000_000), u(4310)
+	pp[15] = cbor.Map{{Key: u(0), Value: []interface{}{u(100788), int64(-1)}}, {Key: u(2), Value: []interface{}{u(3)}}}
+	pp[16] = []interface{}{rational(577, 10000), rational(721, 10000000)}
+	pp[19], pp[20], pp[21] = u(5000), u(150), u(3)
+	pp[30] = rational(15, 1)
+	c, err := dialNode(t, handshake(map[string]interface{}{reqPParams: []interface{}{pp}}))
+	if !assert.NoError(err) {
+		return
+	}
+	params, err := c.GetProtocolParams()
+	if assert.NoError(err) {
+		assert.Equal(int64(44), params.TxFeePerByte)
+		assert.Equal(int64(155381), params.TxFeeFixed)
+		assert.Equal(int64(16384), params.MaxTxSize)
+		assert.Equal(int64(2_000_000), params.StakeAddressDeposit)
+		assert.Equal(int64(4310), params.UtxoCostPerByte)
+		assert.Equal(int64(5000), params.MaxValueSize)
+		assert.Equal(int64(150), params.CollateralPercentage)
+		assert.Equal(3, params.MaxCollateralInputs)
+		assert.Equal(ledger.CostModels{ledger.PlutusV1: {100788, -1}, ledger.PlutusV3: {3}}, params.CostModels)
+		assert.Equal("577/10000", params.ExecutionUnitPrices.PriceMemory.String())
+		assert.Equal("721/10000000", params.ExecutionUnitPrices.PriceSteps.String())
+		assert.Equal("15/1", params.MinFeeRefScriptCostPerByte.String())
+	}
+}
+
+func TestGetEraHistory(t *testing.T) {
+	ps := func(seconds uint64) *big.Int {
+		return new(big.Int).Mul(new(big.Int).SetUint64(seconds), big.NewInt(1_000_000_000_000))
+	}
+	c, err := dialNode(t, handshake(map[string]interface{}{
+		reqEraHistory: cbor.IndefiniteArray{
+			[]interface{}{
+				[]interface{}{u(0), u(0), u(0)},
+				[]interface{}{ps(1_728_000), u(86400), u(4)},
+				[]interface{}{u(21600), u(20000), []interface{}{u(0), u(4320), []interface{}{u(0)}}, u(4320)},
+			},
+			[]interface{}{
+				[]interface{}{ps(1_728_000), u(86400), u(4)},
+				nil,
+				[]interface{}{u(432000), u(1000), []interface{}{u(0), u(129600), []interface{}{u(0)}}, u(129600)},
+			},
+		},
+	}))
+	if !assert.NoError(t, err) {
+		return
+	}
+	eras, err := c.GetEraHistory()
+	if assert.NoError(t, err) && assert.Len(t, eras, 2) {
+		assert.Equal(t, EraSummary{
+			Start:      EraBound{},
+			End:        &EraBound{Time: 20 * 24 * time.Hour, Slot: 86400, Epoch: 4},
+			EpochSize:  21600,
+			SlotLength: 20 * time.Second,
+		}, eras[0])
+		assert.Nil(t, eras[1].End)
+		assert.Equal(t, time.Second, eras[1].SlotLength)
+	}
+}
+
+func TestSubmitTx(t *testing.T) {
+	assert := assert.New(t)
+	replies := handshake(nil)
+	replies[protocolLocalTxSubmit] = map[string]interface{}{
+		// [0, [6, 24(h'84a0f5f6')]]
+		"82008206d8184484a0f5f6": []interface{}{u(1)},
+		"82008206d8184484a1f5f6": []interface{}{u(2), []interface{}{u(6), []interface{}{u(1), u(2)}}},
+		// ConwayUtxowFailure (UtxoFailure (BadInputsUTxO [input]))
+		"82008206d8184484a2f5f6": []interface{}{u(2), []interface{}{u(6), []interface{}{
+			[]interface{}{u(1), []interface{}{u(0), []interface{}{u(1), []interface{}{[]interface{}{make([]byte, 32), u(0)}}}}},
+		}}},
+	}
+	c, err := dialNode(t, replies)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.NoError(c.SubmitTx(&provider.Tx{TxBody: "84a0f5f6"}))
+
+	err = c.SubmitTx(&provider.Tx{TxBody: "84a1f5f6"})
+	var submitErr *provider.SubmitError
+	if assert.True(errors.As(err, &submitErr)) {
+		assert.Equal(provider.SubmitErrorUnknown, submitErr.Kind)
+		assert.Equal("8206820102", submitErr.Message)
+	}
+	err = c.SubmitTx(&provider.Tx{TxBody: "84a2f5f6"})
+	if assert.True(errors.As(err, &submitErr)) {
+		assert.Equal(provider.SubmitErrorInputsSpent, submitErr.Kind)
+		assert.True(strings.HasPrefix(submitErr.Message, "ConwayUtxowFailure (UtxoFailure (BadInputsUTxO)): 8206818201820082018182"))
+	}
+	// a rejection leaves the connection usable
+	assert.NoError(c.SubmitTx(&provider.Tx{TxBody: "84a0f5f6"}))
+}
+
+// Frames captured from real nodes: the cardano-cli 11.0.0.0 query
+// stake-snapshot --all-stake-pools MsgQuery sent to cardano-node 11.0.1, and
+// the ouroboros-consensus golden ApplyTxErr_WrongEraByron rejection.
+const (
+	capturedStakeSnapshotQuery = "82038200820082068209821480"
+	capturedWrongEraByron      = "828201675368656c6c65798200654279726f6e"
+)
+
+func TestCapturedFrames(t *testing.T) {
+	assert := assert.New(t)
+	// GetCBOR (GetStakeSnapshots Nothing) in Conway
+	q := eraQuery(conwayEra, u(9), []interface{}{u(20), []interface{}{}})
+	b, err := cbor.Marshal([]interface{}{msgQuery, q})
+	if assert.NoError(err) {
+		assert.Equal(capturedStakeSnapshotQuery, hex.EncodeToString(b))
+	}
+
+	reason, err := cbor.Unmarshal(hexBytes(capturedWrongEraByron))
+	if !assert.NoError(err) {
+		return
+	}
+	submitErr, err := submitError(reason)
+	if assert.NoError(err) {
+		assert.Equal(provider.SubmitErrorUnknown, submitErr.Kind)
+		assert.Equal(capturedWrongEraByron, submitErr.Message)
+	}
+}
+
+func TestMempool(t *testing.T) {
+	assert := assert.New(t)
+	replies := handshake(nil)
+	replies[protocolLocalTxMonitor] = map[string]interface{}{
+		reqMonitorAcq:   []interface{}{u(2), u(74159190)},
+		"8103":          nil,
+		reqMonitorSizes: []interface{}{u(10), []interface{}{u(180224), u(1024), u(2)}},
+		// [7, [6, h'<tx id>']]
+		"820782065820" + testTxID: []interface{}{u(8), true},
+	}
+	c, err := dialNode(t, replies)
+	if !assert.NoError(err) {
+		return
+	}
+
+	sizes, err := c.MempoolSizes()
+	if assert.NoError(err) {
+		assert.Equal(&MempoolSizes{Capacity: 180224, Size: 1024, NumberOfTxs: 2}, sizes)
+	}
+	has, err := c.MempoolHasTx(testTxID)
+	assert.NoError(err)
+	assert.True(has)
+
+	replies[protocolLocalTxMonitor][reqMonitorNext] = []interface{}{u(6)}
+	txs, err := c.MempoolTxs()
+	assert.NoError(err)
+	assert.Empty(txs)
+}
@@ -0,0 +1,350 @@
+package n2c
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/minswap/pab-go/address"
+	"github.com/minswap/pab-go/cbor"
+	"github.com/minswap/pab-go/ledger"
+)
+
+const tagRational uint64 = 30
+
+func toBigInt(v interface{}) (*big.Int, error) {
+	switch n := v.(type) {
+	case uint64:
+		return new(big.Int).SetUint64(n), nil
+	case int64:
+		return big.NewInt(n), nil
+	case *big.Int:
+		return n, nil
+	default:
+		return nil, fmt.Errorf("expect integer, got %T", v)
+	}
+}
+
+func toInt64(v interface{}) (int64, error) {
+	n, err := toBigInt(v)
+	if err != nil {
+		return 0, err
+	}
+	if !n.IsInt64() {
+		return 0, fmt.Errorf("integer out of range: %s", n)
+	}
+	return n.Int64(), nil
+}
+
+func toArray(v interface{}, minLen int) ([]interface{}, error) {
+	arr, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expect array, got %T", v)
+	}
+	if len(arr) < minLen {
+		return nil, fmt.Errorf("expect at least %d items, got %d", minLen, len(arr))
+	}
+	return arr, nil
+}
+
+func toBytes(v interface{}) ([]byte, error) {
+	b, ok := v.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("expect bytes, got %T", v)
+	}
+	return b, nil
+}
+
+// decodeValue decodes a coin or a [coin, multiasset] pair.
+func decodeValue(v interface{}) (ledger.Value, error) {
+	val := ledger.NewValue()
+	if coin, err := toBigInt(v); err == nil {
+		return val.Add(ledger.ADA, coin), nil
+	}
+	pair, err := toArray(v, 2)
+	if err != nil {
+		return nil, err
+	}
+	coin, err := toBigInt(pair[0])
+	if err != nil {
+		return nil, err
+	}
+	val.Add(ledger.ADA, coin)
+	policies, ok := pair[1].(cbor.Map)
+	if !ok {
+		return nil, fmt.Errorf("expect multiasset map, got %T", pair[1])
+	}
+	for _, p := range policies {
+		policy, err := toBytes(p.Key)
+		if err != nil {
+			return nil, err
+		}
+		assets, ok := p.Value.(cbor.Map)
+		if !ok {
+			return nil, fmt.Errorf("expect asset map, got %T", p.Value)
+		}
+		for _, a := range assets {
+			name, err := toBytes(a.Key)
+			if err != nil {
+				return nil, err
+			}
+			amount, err := toBigInt(a.Value)
+			if err != nil {
+				return nil, err
+			}
+			val.Add(ledger.NewAsset(hex.EncodeToString(policy), hex.EncodeToString(name)), amount)
+		}
+	}
+	return val, nil
+}
+
+// decodeTxOut decodes a legacy [address, value, ?datum hash] output or a
+// Babbage {0: address, 1: value, 2: datum option} output. Only datum hashes
+// are kept, not inline datums.
+func decodeTxOut(v interface{}) (addr string, val ledger.Value, datumHash *string, err error) {
+	var rawAddr, rawValue, rawDatum interface{}
+	switch out := v.(type) {
+	case []interface{}:
+		if len(out) < 2 {
+			return "", nil, nil, fmt.Errorf("expect at least 2 items in output, got %d", len(out))
+		}
+		rawAddr, rawValue = out[0], out[1]
+		if len(out) > 2 {
+			rawDatum = []interface{}{uint64(0), out[2]}
+		}
+	case cbor.Map:
+		rawAddr, _ = out.Get(0)
+		rawValue, _ = out.Get(1)
+		rawDatum, _ = out.Get(2)
+	default:
+		return "", nil, nil, fmt.Errorf("unexpected output: %T", v)
+	}
+	addrBytes, err := toBytes(rawAddr)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("invalid address: %w", err)
+	}
+	a, err := address.FromBytes(addrBytes)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if val, err = decodeValue(rawValue); err != nil {
+		return "", nil, nil, fmt.Errorf("invalid value: %w", err)
+	}
+	if datum, ok := rawDatum.([]interface{}); ok && len(datum) == 2 && datum[0] == uint64(0) {
+		hash, err := toBytes(datum[1])
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("invalid datum hash: %w", err)
+		}
+		s := hex.EncodeToString(hash)
+		datumHash = &s
+	}
+	return a.String(), val, datumHash, nil
+}
+
+// decodeUtxo decodes the result of the UTxO queries, a map of [tx id, index]
+// to outputs.
+func decodeUtxo(v interface{}) ([]ledger.Utxo, error) {
+	m, ok := v.(cbor.Map)
+	if !ok {
+		return nil, fmt.Errorf("expect utxo map, got %T", v)
+	}
+	utxos := make([]ledger.Utxo, 0, len(m))
+	for _, e := range m {
+		in, err := toArray(e.Key, 2)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tx in: %w", err)
+		}
+		txID, err := toBytes(in[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid tx id: %w", err)
+		}
+		index, err := toInt64(in[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid tx index: %w", err)
+		}
+		addr, val, datumHash, err := decodeTxOut(e.Value)
+		if err != nil {
+			return nil, fmt.Errorf("fail to decode output %x#%d: %w", txID, index, err)
+		}
+		utxos = append(utxos, ledger.Utxo{
+			TxID:      hex.EncodeToString(txID),
+			TxIndex:   int(index),
+			Address:   addr,
+			Value:     val,
+			DatumHash: datumHash,
+		})
+	}
+	return utxos, nil
+}
+
+func decodeRational(v interface{}) (*ledger.Rational, error) {
+	tag, ok := v.(cbor.Tag)
+	if !ok || tag.Number != tagRational {
+		return nil, fmt.Errorf("expect rational, got %v", v)
+	}
+	frac, err := toArray(tag.Content, 2)
+	if err != nil {
+		return nil, err
+	}
+	num, err := toBigInt(frac[0])
+	if err != nil {
+		return nil, err
+	}
+	den, err := toBigInt(frac[1])
+	if err != nil {
+		return nil, err
+	}
+	if den.Sign() == 0 {
+		return nil, fmt.Errorf("zero denominator")
+	}
+	r := &ledger.Rational{}
+	r.SetFrac(num, den)
+	return r, nil
+}
+
+// decodeProtocolParams decodes the Babbage (22 fields) or Conway (31 fields)
+// protocol parameters array.
+func decodeProtocolParams(v interface{}) (*ledger.ProtocolParams, error) {
+	pp, err := toArray(v, 22)
+	if err != nil {
+		return nil, err
+	}
+	ints := make(map[int]int64)
+	for _, i := range []int{0, 1, 3, 5, 14, 19, 20, 21} {
+		if ints[i], err = toInt64(pp[i]); err != nil {
+			return nil, fmt.Errorf("invalid protocol parameter %d: %w", i, err)
+		}
+	}
+	params := &ledger.ProtocolParams{
+		TxFeePerByte:         ints[0],
+		TxFeeFixed:           ints[1],
+		MaxTxSize:            ints[3],
+		StakeAddressDeposit:  ints[5],
+		UtxoCostPerByte:      ints[14],
+		MaxValueSize:         ints[19],
+		CollateralPercentage: ints[20],
+		MaxCollateralInputs:  int(ints[21]),
+		CostModels:           make(ledger.CostModels),
+	}
+
+	costModels, ok := pp[15].(cbor.Map)
+	if !ok {
+		return nil, fmt.Errorf("invalid cost models: %T", pp[15])
+	}
+	for _, e := range costModels {
+		lang, err := toInt64(e.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cost model language: %w", err)
+		}
+		model, err := toArray(e.Value, 0)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cost model %d: %w", lang, err)
+		}
+		costs := make([]int64, len(model))
+		for i, c := range model {
+			if costs[i], err = toInt64(c); err != nil {
+				return nil, fmt.Errorf("invalid cost model %d: %w", lang, err)
+			}
+		}
+		params.CostModels[ledger.PlutusV1+ledger.ScriptLanguage(lang)] = costs
+	}
+
+	prices, err := toArray(pp[16], 2)
+	if err != nil {
+		return nil, fmt.Errorf("invalid execution prices: %w", err)
+	}
+	if params.ExecutionUnitPrices.PriceMemory, err = decodeRational(prices[0]); err != nil {
+		return nil, fmt.Errorf("invalid memory price: %w", err)
+	}
+	if params.ExecutionUnitPrices.PriceSteps, err = decodeRational(prices[1]); err != nil {
+		return nil, fmt.Errorf("invalid steps price: %w", err)
+	}
+	if len(pp) > 30 {
+		if params.MinFeeRefScriptCostPerByte, err = decodeRational(pp[30]); err != nil {
+			return nil, fmt.Errorf("invalid reference script cost: %w", err)
+		}
+	}
+	return params, nil
+}
+
+// EraBound is the start or end of an era, with Time relative to the system
+// start.
+type EraBound struct {
+	Time  time.Duration
+	Slot  uint64
+	Epoch uint64
+}
+
+// EraSummary is an era of the era history. End is nil for the current era
+// when its end is not yet known.
+type EraSummary struct {
+	Start      EraBound
+	End        *EraBound
+	EpochSize  uint64
+	SlotLength time.Duration
+}
+
+// decodeBound decodes a [time in picoseconds, slot, epoch] bound.
+func decodeBound(v interface{}) (EraBound, error) {
+	bound, err := toArray(v, 3)
+	if err != nil {
+		return EraBound{}, err
+	}
+	ps, err := toBigInt(bound[0])
+	if err != nil {
+		return EraBound{}, err
+	}
+	slot, err := toInt64(bound[1])
+	if err != nil {
+		return EraBound{}, err
+	}
+	epoch, err := toInt64(bound[2])
+	if err != nil {
+		return EraBound{}, err
+	}
+	ns := new(big.Int).Quo(ps, big.NewInt(1000))
+	return EraBound{Time: time.Duration(ns.Int64()), Slot: uint64(slot), Epoch: uint64(epoch)}, nil
+}
+
+// decodeEraHistory decodes the result of GetInterpreter, a list of
+// [start, end, [epoch size, slot length in milliseconds, safe zone, ...]].
+func decodeEraHistory(v interface{}) ([]EraSummary, error) {
+	eras, err := toArray(v, 0)
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]EraSummary, len(eras))
+	for i, e := range eras {
+		era, err := toArray(e, 3)
+		if err != nil {
+			return nil, fmt.Errorf("invalid era %d: %w", i, err)
+		}
+		s := &summaries[i]
+		if s.Start, err = decodeBound(era[0]); err != nil {
+			return nil, fmt.Errorf("invalid start of era %d: %w", i, err)
+		}
+		if era[1] != nil {
+			end, err := decodeBound(era[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid end of era %d: %w", i, err)
+			}
+			s.End = &end
+		}
+		params, err := toArray(era[2], 2)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parameters of era %d: %w", i, err)
+		}
+		epochSize, err := toInt64(params[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid epoch size of era %d: %w", i, err)
+		}
+		slotLength, err := toInt64(params[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid slot length of era %d: %w", i, err)
+		}
+		s.EpochSize = uint64(epochSize)
+		s.SlotLength = time.Duration(slotLength) * time.Millisecond
+	}
+	return summaries, nil
+}
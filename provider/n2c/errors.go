@@ -0,0 +1,157 @@
+package n2c
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/minswap/pab-go/cbor"
+	"github.com/minswap/pab-go/provider"
+)
+
+// Constructor names of Conway ledger predicate failures by CBOR tag, as
+// encoded by cardano-ledger. Below the LEDGER rule, only the UTXOW, UTXO and
+// UTXOS failures are named.
+var (
+	conwayLedgerFailures = map[int64]string{
+		1: "ConwayUtxowFailure",
+		2: "ConwayCertsFailure",
+		3: "ConwayGovFailure",
+		4: "ConwayWdrlNotDelegatedToDRep",
+		5: "ConwayTreasuryValueMismatch",
+		6: "ConwayTxRefScriptsSizeTooBig",
+		7: "ConwayMempoolFailure",
+		9: "ConwayIncompleteWithdrawals",
+	}
+	conwayUtxowFailures = map[int64]string{
+		0:  "UtxoFailure",
+		1:  "InvalidWitnessesUTXOW",
+		2:  "MissingVKeyWitnessesUTXOW",
+		3:  "MissingScriptWitnessesUTXOW",
+		4:  "ScriptWitnessNotValidatingUTXOW",
+		5:  "MissingTxBodyMetadataHash",
+		6:  "MissingTxMetadata",
+		7:  "ConflictingMetadataHash",
+		8:  "InvalidMetadata",
+		9:  "ExtraneousScriptWitnessesUTXOW",
+		10: "MissingRedeemers",
+		11: "MissingRequiredDatums",
+		12: "NotAllowedSupplementalDatums",
+		13: "PPViewHashesDontMatch",
+		14: "UnspendableUTxONoDatumHash",
+		15: "ExtraRedeemers",
+		16: "MalformedScriptWitnesses",
+		17: "MalformedReferenceScripts",
+		18: "ScriptIntegrityHashMismatch",
+	}
+	conwayUtxoFailures = map[int64]string{
+		0:  "UtxosFailure",
+		1:  "BadInputsUTxO",
+		2:  "OutsideValidityIntervalUTxO",
+		3:  "MaxTxSizeUTxO",
+		4:  "InputSetEmptyUTxO",
+		5:  "FeeTooSmallUTxO",
+		6:  "ValueNotConservedUTxO",
+		7:  "WrongNetwork",
+		8:  "WrongNetworkWithdrawal",
+		9:  "OutputTooSmallUTxO",
+		10: "OutputBootAddrAttrsTooBig",
+		11: "OutputTooBigUTxO",
+		12: "InsufficientCollateral",
+		13: "ScriptsNotPaidUTxO",
+		14: "ExUnitsTooBigUTxO",
+		15: "CollateralContainsNonADA",
+		16: "WrongNetworkInTxBody",
+		17: "OutsideForecast",
+		18: "TooManyCollateralInputs",
+		19: "NoCollateralInputs",
+		20: "IncorrectTotalCollateralField",
+		21: "BabbageOutputTooSmallUTxO",
+		22: "BabbageNonDisjointRefInputs",
+	}
+	conwayUtxosFailures = map[int64]string{
+		0: "ValidationTagMismatch",
+		1: "CollectErrors",
+	}
+)
+
+const conwayEra = 6
+
+// failureRule names the failures of a ledger rule. The failure tagged nested
+// wraps a failure of the next rule.
+type failureRule struct {
+	names  map[int64]string
+	nested int64
+}
+
+var conwayRules = []failureRule{
+	{conwayLedgerFailures, 1},
+	{conwayUtxowFailures, 0},
+	{conwayUtxoFailures, 0},
+	{conwayUtxosFailures, -1},
+}
+
+// failureName returns the constructor path of a [tag, ...] failure of
+// rules[0], e.g. "ConwayUtxowFailure (UtxoFailure (BadInputsUTxO))".
+func failureName(v interface{}, rules []failureRule) (string, bool) {
+	arr, err := toArray(v, 1)
+	if err != nil {
+		return "", false
+	}
+	tag, err := toInt64(arr[0])
+	if err != nil {
+		return "", false
+	}
+	name, ok := rules[0].names[tag]
+	if !ok {
+		return "", false
+	}
+	if len(rules) > 1 && tag == rules[0].nested && len(arr) > 1 {
+		if inner, ok := failureName(arr[1], rules[1:]); ok {
+			return name + " (" + inner + ")", true
+		}
+	}
+	return name, true
+}
+
+// renderRejection renders the failures of a Conway [era, [failure]] rejection
+// reason with ledger constructor names.
+func renderRejection(reason interface{}) (string, bool) {
+	arr, err := toArray(reason, 2)
+	if err != nil {
+		return "", false
+	}
+	if era, err := toInt64(arr[0]); err != nil || era != conwayEra {
+		return "", false
+	}
+	failures, err := toArray(arr[1], 1)
+	if err != nil {
+		return "", false
+	}
+	names := make([]string, len(failures))
+	for i, f := range failures {
+		name, ok := failureName(f, conwayRules)
+		if !ok {
+			return "", false
+		}
+		names[i] = name
+	}
+	return strings.Join(names, "; "), true
+}
+
+// submitError classifies a rejection reason by the constructor names of its
+// failures. The message keeps the raw reason, which names only summarize.
+func submitError(reason interface{}) (*provider.SubmitError, error) {
+	raw, err := cbor.Marshal(reason)
+	if err != nil {
+		return nil, err
+	}
+	message := hex.EncodeToString(raw)
+	if names, ok := renderRejection(reason); ok {
+		message = fmt.Sprintf("%s: %s", names, message)
+	}
+	return &provider.SubmitError{
+		Kind:    provider.ClassifyLedgerError(message),
+		Message: message,
+	}, nil
+}
@@ -0,0 +1,158 @@
+package n2c
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/minswap/pab-go/cbor"
+	"github.com/minswap/pab-go/provider"
+)
+
+var _ provider.MempoolQuerier = (*Client)(nil)
+
+// LocalTxMonitor messages.
+const (
+	msgMonitorAcquire  uint64 = 1
+	msgMonitorAcquired uint64 = 2
+	msgMonitorRelease  uint64 = 3
+	msgNextTx          uint64 = 5
+	msgReplyNextTx     uint64 = 6
+	msgHasTx           uint64 = 7
+	msgReplyHasTx      uint64 = 8
+	msgGetSizes        uint64 = 9
+	msgReplyGetSizes   uint64 = 10
+)
+
+// MempoolSizes are the capacity and size of the mempool in bytes, and its
+// number of transactions.
+type MempoolSizes struct {
+	Capacity    uint64
+	Size        uint64
+	NumberOfTxs uint64
+}
+
+// monitor acquires a mempool snapshot, then calls fn with a function sending
+// a request and returning the reply.
+func (c *Client) monitor(fn func(request func(msg ...interface{}) ([]interface{}, error)) error) error {
+	return c.exchange(func() error {
+		if err := c.mux.send(protocolLocalTxMonitor, []interface{}{msgMonitorAcquire}); err != nil {
+			return err
+		}
+		tag, msg, err := c.mux.receive(protocolLocalTxMonitor)
+		if err != nil {
+			return err
+		}
+		if tag != msgMonitorAcquired {
+			return fmt.Errorf("unexpected tx monitor message: %v", msg)
+		}
+		err = fn(func(req ...interface{}) ([]interface{}, error) {
+			if err := c.mux.send(protocolLocalTxMonitor, req); err != nil {
+				return nil, err
+			}
+			tag, reply, err := c.mux.receive(protocolLocalTxMonitor)
+			if err != nil {
+				return nil, err
+			}
+			// replies are tagged one above their request
+			if tag != req[0].(uint64)+1 {
+				return nil, fmt.Errorf("unexpected tx monitor message: %v", reply)
+			}
+			return reply, nil
+		})
+		if err != nil {
+			return err
+		}
+		return c.mux.send(protocolLocalTxMonitor, []interface{}{msgMonitorRelease})
+	})
+}
+
+func (c *Client) MempoolSizes() (*MempoolSizes, error) {
+	var sizes MempoolSizes
+	err := c.monitor(func(request func(...interface{}) ([]interface{}, error)) error {
+		reply, err := request(msgGetSizes)
+		if err != nil {
+			return err
+		}
+		if len(reply) != 2 {
+			return fmt.Errorf("unexpected sizes: %v", reply)
+		}
+		fields, err := toArray(reply[1], 3)
+		if err != nil {
+			return fmt.Errorf("unexpected sizes: %w", err)
+		}
+		for i, dst := range []*uint64{&sizes.Capacity, &sizes.Size, &sizes.NumberOfTxs} {
+			n, err := toInt64(fields[i])
+			if err != nil {
+				return fmt.Errorf("unexpected sizes: %w", err)
+			}
+			*dst = uint64(n)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fail to query mempool sizes: %w", err)
+	}
+	return &sizes, nil
+}
+
+// MempoolHasTx reports whether the tx is in the mempool.
+func (c *Client) MempoolHasTx(txHash string) (bool, error) {
+	txID, err := hex.DecodeString(txHash)
+	if err != nil {
+		return false, fmt.Errorf("invalid tx hash %s: %w", txHash, err)
+	}
+	// tx ids are wrapped with their era like txs
+	era, err := c.currentEra()
+	if err != nil {
+		return false, fmt.Errorf("fail to query mempool: %w", err)
+	}
+	var has bool
+	err = c.monitor(func(request func(...interface{}) ([]interface{}, error)) error {
+		reply, err := request(msgHasTx, []interface{}{era, txID})
+		if err != nil {
+			return err
+		}
+		if len(reply) != 2 {
+			return fmt.Errorf("unexpected reply: %v", reply)
+		}
+		has, _ = reply[1].(bool)
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("fail to query mempool: %w", err)
+	}
+	return has, nil
+}
+
+// MempoolTxs returns the hex CBOR of the transactions in the mempool.
+func (c *Client) MempoolTxs() ([]string, error) {
+	var txs []string
+	err := c.monitor(func(request func(...interface{}) ([]interface{}, error)) error {
+		for {
+			reply, err := request(msgNextTx)
+			if err != nil {
+				return err
+			}
+			if len(reply) == 1 {
+				return nil
+			}
+			tx, err := toArray(reply[1], 2)
+			if err != nil {
+				return fmt.Errorf("unexpected tx: %w", err)
+			}
+			wrapped, ok := tx[1].(cbor.Tag)
+			if !ok || wrapped.Number != cbor.TagEncodedCBOR {
+				return fmt.Errorf("unexpected tx: %v", tx[1])
+			}
+			b, err := toBytes(wrapped.Content)
+			if err != nil {
+				return fmt.Errorf("unexpected tx: %w", err)
+			}
+			txs = append(txs, hex.EncodeToString(b))
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fail to query mempool txs: %w", err)
+	}
+	return txs, nil
+}
@@ -0,0 +1,108 @@
+package n2c
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/minswap/pab-go/cbor"
+)
+
+// Mini-protocol numbers of the node-to-client protocol.
+const (
+	protocolHandshake       uint16 = 0
+	protocolLocalTxSubmit   uint16 = 6
+	protocolLocalStateQuery uint16 = 7
+	protocolLocalTxMonitor  uint16 = 9
+)
+
+const (
+	sduHeaderSize = 8
+	// maxSDUPayload is the largest payload of a segment sent to the node.
+	maxSDUPayload = 12288
+	// responderFlag is set in the protocol number of segments sent by the node.
+	responderFlag uint16 = 0x8000
+)
+
+// mux frames mini-protocol messages into segments of the multiplexer: a
+// 4-byte timestamp, the 2-byte protocol number with the responder flag and
+// the 2-byte payload length, then the payload. A message may span several
+// segments, so received payloads are buffered per protocol until they hold a
+// whole CBOR item.
+type mux struct {
+	conn    net.Conn
+	start   time.Time
+	pending map[uint16][]byte
+}
+
+func newMux(conn net.Conn) *mux {
+	return &mux{conn: conn, start: time.Now(), pending: make(map[uint16][]byte)}
+}
+
+func (m *mux) send(protocol uint16, msg []interface{}) error {
+	payload, err := cbor.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("fail to encode message: %w", err)
+	}
+	for len(payload) > 0 {
+		n := len(payload)
+		if n > maxSDUPayload {
+			n = maxSDUPayload
+		}
+		segment := make([]byte, sduHeaderSize, sduHeaderSize+n)
+		binary.BigEndian.PutUint32(segment[0:4], uint32(time.Since(m.start).Microseconds()))
+		binary.BigEndian.PutUint16(segment[4:6], protocol)
+		binary.BigEndian.PutUint16(segment[6:8], uint16(n))
+		segment = append(segment, payload[:n]...)
+		if _, err := m.conn.Write(segment); err != nil {
+			return fmt.Errorf("fail to write to node socket: %w", err)
+		}
+		payload = payload[n:]
+	}
+	return nil
+}
+
+// receive returns the next message of protocol, which must be an array
+// starting with the message tag.
+func (m *mux) receive(protocol uint16) (tag uint64, msg []interface{}, err error) {
+	for {
+		if buf := m.pending[protocol]; len(buf) > 0 {
+			v, rest, err := cbor.Decode(buf)
+			if err == nil {
+				m.pending[protocol] = rest
+				msg, ok := v.([]interface{})
+				if !ok || len(msg) == 0 {
+					return 0, nil, fmt.Errorf("unexpected message: %v", v)
+				}
+				tag, ok := msg[0].(uint64)
+				if !ok {
+					return 0, nil, fmt.Errorf("unexpected message tag: %v", msg[0])
+				}
+				return tag, msg, nil
+			}
+			if !errors.Is(err, cbor.ErrUnexpectedEOF) {
+				return 0, nil, fmt.Errorf("fail to decode message: %w", err)
+			}
+		}
+		if err := m.readSegment(); err != nil {
+			return 0, nil, err
+		}
+	}
+}
+
+func (m *mux) readSegment() error {
+	var header [sduHeaderSize]byte
+	if _, err := io.ReadFull(m.conn, header[:]); err != nil {
+		return fmt.Errorf("fail to read from node socket: %w", err)
+	}
+	protocol := binary.BigEndian.Uint16(header[4:6]) &^ responderFlag
+	payload := make([]byte, binary.BigEndian.Uint16(header[6:8]))
+	if _, err := io.ReadFull(m.conn, payload); err != nil {
+		return fmt.Errorf("fail to read from node socket: %w", err)
+	}
+	m.pending[protocol] = append(m.pending[protocol], payload...)
+	return nil
+}
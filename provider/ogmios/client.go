@@ -0,0 +1,135 @@
+// Package ogmios is a ChainProvider over the Ogmios v6 WebSocket JSON-RPC API.
+package ogmios
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// DefaultTimeout bounds requests of ChainProvider methods, which take no context.
+const DefaultTimeout = 30 * time.Second
+
+// RPCError is a JSON-RPC error returned by Ogmios.
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("ogmios error %d: %s", e.Code, e.Message)
+}
+
+type request struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+	ID      uint64      `json:"id"`
+}
+
+type response struct {
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+	ID     uint64          `json:"id"`
+}
+
+// ErrClosed is returned by calls on a closed Client.
+var ErrClosed = errors.New("ogmios: client is closed")
+
+// Client sends one request at a time over a WebSocket connection. A
+// connection failing to send or read, e.g. after a timeout, cannot be used
+// again: it is dropped and the next call dials a new one.
+type Client struct {
+	url     string
+	conn    *websocket.Conn
+	closed  bool
+	mu      sync.Mutex
+	nextID  uint64
+	Timeout time.Duration
+}
+
+// Dial connects to Ogmios, e.g. ws://localhost:1337.
+func Dial(ctx context.Context, url string) (*Client, error) {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fail to connect to ogmios: %w", err)
+	}
+	return &Client{url: url, conn: conn, Timeout: DefaultTimeout}, nil
+}
+
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
+
+func (c *Client) drop() {
+	_ = c.conn.Close()
+	c.conn = nil
+}
+
+// Call sends a request and decodes its result into result.
+func (c *Client) Call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return ErrClosed
+	}
+	if c.conn == nil {
+		conn, _, err := websocket.DefaultDialer.DialContext(ctx, c.url, nil)
+		if err != nil {
+			return fmt.Errorf("fail to reconnect to ogmios: %w", err)
+		}
+		c.conn = conn
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(c.Timeout)
+	}
+	c.nextID++
+	id := c.nextID
+	_ = c.conn.SetWriteDeadline(deadline)
+	if err := c.conn.WriteJSON(request{JSONRPC: "2.0", Method: method, Params: params, ID: id}); err != nil {
+		c.drop()
+		return fmt.Errorf("fail to send %s: %w", method, err)
+	}
+	_ = c.conn.SetReadDeadline(deadline)
+	var resp response
+	if err := c.conn.ReadJSON(&resp); err != nil {
+		c.drop()
+		return fmt.Errorf("fail to read %s response: %w", method, err)
+	}
+	if resp.ID != id {
+		c.drop()
+		return fmt.Errorf("fail to read %s response: got id %d, want %d", method, resp.ID, id)
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Result, result); err != nil {
+		return fmt.Errorf("fail to decode %s result: %w", method, err)
+	}
+	return nil
+}
+
+func (c *Client) call(method string, params interface{}, result interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+	return c.Call(ctx, method, params, result)
+}
@@ -0,0 +1,28 @@
+package ogmios
+
+import "github.com/minswap/pab-go/provider"
+
+// submitErrorKinds maps Ogmios v6 submitTransaction error codes to kinds.
+var submitErrorKinds = map[int]provider.SubmitErrorKind{
+	3010: provider.SubmitErrorScriptFailure,
+	3011: provider.SubmitErrorScriptFailure,
+	3012: provider.SubmitErrorScriptFailure,
+	3100: provider.SubmitErrorMissingWitnesses,
+	3101: provider.SubmitErrorMissingWitnesses,
+	3102: provider.SubmitErrorMissingWitnesses,
+	3117: provider.SubmitErrorInputsSpent,
+	3118: provider.SubmitErrorOutsideValidityInterval,
+	3119: provider.SubmitErrorTxTooLarge,
+	3122: provider.SubmitErrorFeeTooSmall,
+	3123: provider.SubmitErrorValueNotConserved,
+	3125: provider.SubmitErrorOutputTooSmall,
+	3128: provider.SubmitErrorInsufficientCollateral,
+	3136: provider.SubmitErrorInsufficientCollateral,
+}
+
+func submitErrorKind(err *RPCError) provider.SubmitErrorKind {
+	if kind, ok := submitErrorKinds[err.Code]; ok {
+		return kind
+	}
+	return provider.ClassifyLedgerError(err.Message)
+}
@@ -0,0 +1,313 @@
+package ogmios
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/minswap/pab-go/ledger"
+	"github.com/minswap/pab-go/provider"
+)
+
+var (
+	_ provider.ChainProvider  = (*Client)(nil)
+	_ provider.MempoolQuerier = (*Client)(nil)
+)
+
+// AwaitTxInterval is how often AwaitTx queries Ogmios.
+var AwaitTxInterval = 2 * time.Second
+
+// eraNames are the eras in the order of era summaries.
+var eraNames = []string{"Byron", "Shelley", "Allegra", "Mary", "Alonzo", "Babbage", "Conway"}
+
+type point struct {
+	Slot int    `json:"slot"`
+	ID   string `json:"id"`
+}
+
+func (c *Client) GetTip() (*provider.Tip, error) {
+	var raw json.RawMessage
+	if err := c.call("queryNetwork/tip", nil, &raw); err != nil {
+		return nil, fmt.Errorf("fail to query tip: %w", err)
+	}
+	tip := &provider.Tip{}
+	var p point
+	// the tip is the string "origin" before the first block
+	if err := json.Unmarshal(raw, &p); err == nil {
+		tip.Slot, tip.Hash = p.Slot, p.ID
+	}
+	if err := c.call("queryNetwork/blockHeight", nil, &raw); err != nil {
+		return nil, fmt.Errorf("fail to query block height: %w", err)
+	}
+	_ = json.Unmarshal(raw, &tip.Block)
+	if err := c.call("queryLedgerState/epoch", nil, &tip.Epoch); err != nil {
+		return nil, fmt.Errorf("fail to query epoch: %w", err)
+	}
+	summaries, err := c.GetEraSummaries()
+	if err != nil {
+		return nil, err
+	}
+	if n := len(summaries); n > 0 && n <= len(eraNames) {
+		tip.Era = eraNames[n-1]
+	}
+	return tip, nil
+}
+
+type EraBound struct {
+	Time struct {
+		Seconds int64 `json:"seconds"`
+	} `json:"time"`
+	Slot  int64 `json:"slot"`
+	Epoch int64 `json:"epoch"`
+}
+
+type EraSummary struct {
+	Start      EraBound  `json:"start"`
+	End        *EraBound `json:"end"`
+	Parameters struct {
+		EpochLength int64 `json:"epochLength"`
+		SlotLength  struct {
+			Milliseconds int64 `json:"milliseconds"`
+		} `json:"slotLength"`
+		SafeZone *int64 `json:"safeZone"`
+	} `json:"parameters"`
+}
+
+// GetEraSummaries returns the era history, to convert slots to time.
+func (c *Client) GetEraSummaries() ([]EraSummary, error) {
+	var summaries []EraSummary
+	if err := c.call("queryLedgerState/eraSummaries", nil, &summaries); err != nil {
+		return nil, fmt.Errorf("fail to query era summaries: %w", err)
+	}
+	return summaries, nil
+}
+
+type lovelace struct {
+	ADA struct {
+		Lovelace int64 `json:"lovelace"`
+	} `json:"ada"`
+}
+
+type bytesSize struct {
+	Bytes int64 `json:"bytes"`
+}
+
+type protocolParameters struct {
+	MinFeeCoefficient         int64                   `json:"minFeeCoefficient"`
+	MinFeeConstant            lovelace                `json:"minFeeConstant"`
+	MaxTransactionSize        bytesSize               `json:"maxTransactionSize"`
+	MaxValueSize              bytesSize               `json:"maxValueSize"`
+	MinUtxoDepositCoefficient int64                   `json:"minUtxoDepositCoefficient"`
+	StakeCredentialDeposit    lovelace                `json:"stakeCredentialDeposit"`
+	CollateralPercentage      int64                   `json:"collateralPercentage"`
+	MaxCollateralInputs       int                     `json:"maxCollateralInputs"`
+	PlutusCostModels          map[string][]int64      `json:"plutusCostModels"`
+	ScriptExecutionPrices     *scriptExecutionPrices  `json:"scriptExecutionPrices"`
+	MinFeeReferenceScripts    *minFeeReferenceScripts `json:"minFeeReferenceScripts"`
+}
+
+type scriptExecutionPrices struct {
+	Memory *ledger.Rational `json:"memory"`
+	CPU    *ledger.Rational `json:"cpu"`
+}
+
+type minFeeReferenceScripts struct {
+	Base *ledger.Rational `json:"base"`
+}
+
+var costModelLanguages = map[string]ledger.ScriptLanguage{
+	"plutus:v1": ledger.PlutusV1,
+	"plutus:v2": ledger.PlutusV2,
+	"plutus:v3": ledger.PlutusV3,
+}
+
+func (c *Client) GetProtocolParams() (*ledger.ProtocolParams, error) {
+	var pp protocolParameters
+	if err := c.call("queryLedgerState/protocolParameters", nil, &pp); err != nil {
+		return nil, fmt.Errorf("fail to query protocol parameters: %w", err)
+	}
+	params := &ledger.ProtocolParams{
+		UtxoCostPerByte:      pp.MinUtxoDepositCoefficient,
+		MaxValueSize:         pp.MaxValueSize.Bytes,
+		TxFeePerByte:         pp.MinFeeCoefficient,
+		TxFeeFixed:           pp.MinFeeConstant.ADA.Lovelace,
+		MaxTxSize:            pp.MaxTransactionSize.Bytes,
+		CollateralPercentage: pp.CollateralPercentage,
+		MaxCollateralInputs:  pp.MaxCollateralInputs,
+		StakeAddressDeposit:  pp.StakeCredentialDeposit.ADA.Lovelace,
+		CostModels:           make(ledger.CostModels),
+	}
+	if pp.ScriptExecutionPrices != nil {
+		params.ExecutionUnitPrices = ledger.ExecutionUnitPrices{
+			PriceMemory: pp.ScriptExecutionPrices.Memory,
+			PriceSteps:  pp.ScriptExecutionPrices.CPU,
+		}
+	}
+	if pp.MinFeeReferenceScripts != nil {
+		params.MinFeeRefScriptCostPerByte = pp.MinFeeReferenceScripts.Base
+	}
+	for name, model := range pp.PlutusCostModels {
+		lang, ok := costModelLanguages[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown cost model language: %s", name)
+		}
+		params.CostModels[lang] = model
+	}
+	return params, nil
+}
+
+type outputReference struct {
+	Transaction struct {
+		ID string `json:"id"`
+	} `json:"transaction"`
+	Index int `json:"index"`
+}
+
+type utxo struct {
+	outputReference
+	Address   string                     `json:"address"`
+	Value     map[string]json.RawMessage `json:"value"`
+	DatumHash *string                    `json:"datumHash"`
+}
+
+func (u utxo) ledgerUtxo() (ledger.Utxo, error) {
+	val := ledger.NewValue()
+	for policy, raw := range u.Value {
+		var assets map[string]json.Number
+		if err := json.Unmarshal(raw, &assets); err != nil {
+			return ledger.Utxo{}, fmt.Errorf("fail to decode value of %s: %w", policy, err)
+		}
+		for name, amount := range assets {
+			n, ok := new(big.Int).SetString(amount.String(), 10)
+			if !ok {
+				return ledger.Utxo{}, fmt.Errorf("invalid amount of %s.%s: %s", policy, name, amount)
+			}
+			if policy == "ada" {
+				val.Add(ledger.ADA, n)
+			} else {
+				val.Add(ledger.NewAsset(policy, name), n)
+			}
+		}
+	}
+	return ledger.Utxo{
+		TxID:      u.Transaction.ID,
+		TxIndex:   u.Index,
+		Address:   u.Address,
+		Value:     val,
+		DatumHash: u.DatumHash,
+	}, nil
+}
+
+func (c *Client) queryUtxos(params interface{}) ([]ledger.Utxo, error) {
+	var result []utxo
+	if err := c.call("queryLedgerState/utxo", params, &result); err != nil {
+		return nil, fmt.Errorf("fail to query utxo: %w", err)
+	}
+	utxos := make([]ledger.Utxo, 0, len(result))
+	for _, u := range result {
+		lu, err := u.ledgerUtxo()
+		if err != nil {
+			return nil, fmt.Errorf("fail to parse utxos: %w", err)
+		}
+		utxos = append(utxos, lu)
+	}
+	return utxos, nil
+}
+
+func (c *Client) GetUtxosByAddresses(addresses ...string) ([]ledger.Utxo, error) {
+	return c.queryUtxos(map[string]interface{}{"addresses": addresses})
+}
+
+func (c *Client) GetUtxosByTxIns(txIns ...ledger.OutRef) ([]ledger.Utxo, error) {
+	refs := make([]outputReference, len(txIns))
+	for i, in := range txIns {
+		refs[i].Transaction.ID = in.TxID
+		refs[i].Index = in.TxIndex
+	}
+	return c.queryUtxos(map[string]interface{}{"outputReferences": refs})
+}
+
+type transactionParams struct {
+	Transaction struct {
+		CBOR string `json:"cbor"`
+	} `json:"transaction"`
+}
+
+func newTransactionParams(txCBORHex string) transactionParams {
+	var p transactionParams
+	p.Transaction.CBOR = txCBORHex
+	return p
+}
+
+func (c *Client) SubmitTx(tx *provider.Tx) error {
+	var result struct {
+		Transaction struct {
+			ID string `json:"id"`
+		} `json:"transaction"`
+	}
+	if err := c.call("submitTransaction", newTransactionParams(tx.TxBody), &result); err != nil {
+		var rpcErr *RPCError
+		if errors.As(err, &rpcErr) {
+			err = &provider.SubmitError{
+				Kind:    submitErrorKind(rpcErr),
+				Message: rpcErr.Message,
+				Err:     rpcErr,
+			}
+		}
+		return fmt.Errorf("fail to submit tx: %w", err)
+	}
+	return nil
+}
+
+// AwaitTx waits for the first output of the tx to appear in the UTxO set, so
+// it does not return if that output is spent before it is seen.
+func (c *Client) AwaitTx(ctx context.Context, txHash string) error {
+	return provider.PollTx(ctx, AwaitTxInterval, func() (bool, error) {
+		utxos, err := c.GetUtxosByTxIns(ledger.OutRef{TxID: txHash, TxIndex: 0})
+		if err != nil {
+			return false, err
+		}
+		return len(utxos) > 0, nil
+	})
+}
+
+// Evaluation is the execution budget of a redeemer.
+type Evaluation struct {
+	Validator struct {
+		Purpose string `json:"purpose"`
+		Index   int    `json:"index"`
+	} `json:"validator"`
+	Budget struct {
+		Memory int64 `json:"memory"`
+		CPU    int64 `json:"cpu"`
+	} `json:"budget"`
+}
+
+// EvaluateTx returns the execution units of the redeemers of a tx.
+func (c *Client) EvaluateTx(txCBORHex string) ([]Evaluation, error) {
+	var result []Evaluation
+	if err := c.call("evaluateTransaction", newTransactionParams(txCBORHex), &result); err != nil {
+		return nil, fmt.Errorf("fail to evaluate tx: %w", err)
+	}
+	return result, nil
+}
+
+// MempoolHasTx reports whether the tx is in a snapshot of the mempool of the
+// node, released right after.
+func (c *Client) MempoolHasTx(txHash string) (bool, error) {
+	if err := c.call("acquireMempool", nil, nil); err != nil {
+		return false, fmt.Errorf("fail to acquire mempool: %w", err)
+	}
+	var exists bool
+	err := c.call("hasTransaction", map[string]string{"id": txHash}, &exists)
+	if releaseErr := c.call("releaseMempool", nil, nil); err == nil && releaseErr != nil {
+		err = fmt.Errorf("fail to release mempool: %w", releaseErr)
+	}
+	if err != nil {
+		return false, fmt.Errorf("fail to query mempool: %w", err)
+	}
+	return exists, nil
+}
@@ -0,0 +1,274 @@
+package ogmios
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/minswap/pab-go/ledger"
+	"github.com/minswap/pab-go/provider"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubServer answers each JSON-RPC method with a canned result or error.
+func stubServer(t *testing.T, handlers map[string]func(params json.RawMessage) (interface{}, *RPCError)) *Client {
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			var req struct {
+				Method string          `json:"method"`
+				Params json.RawMessage `json:"params"`
+				ID     uint64          `json:"id"`
+			}
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+			handler, ok := handlers[req.Method]
+			if !ok {
+				resp["error"] = RPCError{Code: -32601, Message: "method not found"}
+			} else if result, rpcErr := handler(req.Params); rpcErr != nil {
+				resp["error"] = rpcErr
+			} else {
+				resp["result"] = result
+			}
+			if err := conn.WriteJSON(resp); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	c, err := Dial(context.Background(), "ws"+strings.TrimPrefix(srv.URL, "http"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func raw(s string) func(json.RawMessage) (interface{}, *RPCError) {
+	return func(json.RawMessage) (interface{}, *RPCError) { return json.RawMessage(s), nil }
+}
+
+const (
+	testTxID   = "5ca53b0eb10f317a5f1bf1bda679a04a8dd01c156643deee1d406ec2cc15e9e3"
+	testPolicy = "29d222ce763455e3d7a09a665ce554f00ac89d2e99a1a83d267170c6"
+	testAddr   = "addr_test1vz3ppzmmzuz0nlsjeyrqjm4pvdxl3cyfe8x06eg6htj2gwgv02qjt"
+)
+
+func TestGetUtxos(t *testing.T) {
+	assert := assert.New(t)
+	var gotParams string
+	c := stubServer(t, map[string]func(json.RawMessage) (interface{}, *RPCError){
+		"queryLedgerState/utxo": func(params json.RawMessage) (interface{}, *RPCError) {
+			gotParams = string(params)
+			return json.RawMessage(`[{
+				"transaction": {"id": "` + testTxID + `"},
+				"index": 1,
+				"address": "` + testAddr + `",
+				"value": {"ada": {"lovelace": 2000000}, "` + testPolicy + `": {"4d494e": 18446744073709551616}},
+				"datumHash": "923918e403bf43c34b4ef6b48eb2ee04babed17320d8d1b9ff9ad086e86f44ec"
+			}]`), nil
+		},
+	})
+
+	utxos, err := c.GetUtxosByTxIns(ledger.OutRef{TxID: testTxID, TxIndex: 1})
+	if assert.NoError(err) && assert.Len(utxos, 1) {
+		u := utxos[0]
+		assert.Equal(testTxID, u.TxID)
+		assert.Equal(1, u.TxIndex)
+		assert.Equal(testAddr, u.Address)
+		assert.Equal(big.NewInt(2_000_000), u.Value[ledger.ADA])
+		amount, _ := new(big.Int).SetString("18446744073709551616", 10)
+		assert.Equal(amount, u.Value[ledger.NewAsset(testPolicy, "4d494e")])
+		if assert.NotNil(u.DatumHash) {
+			assert.Equal("923918e403bf43c34b4ef6b48eb2ee04babed17320d8d1b9ff9ad086e86f44ec", *u.DatumHash)
+		}
+	}
+	assert.JSONEq(`{"outputReferences": [{"transaction": {"id": "`+testTxID+`"}, "index": 1}]}`, gotParams)
+
+	_, err = c.GetUtxosByAddresses(testAddr)
+	assert.NoError(err)
+	assert.JSONEq(`{"addresses": ["`+testAddr+`"]}`, gotParams)
+}
+
+func TestGetTip(t *testing.T) {
+	c := stubServer(t, map[string]func(json.RawMessage) (interface{}, *RPCError){
+		"queryNetwork/tip":              raw(`{"slot": 61234567, "id": "` + testTxID + `"}`),
+		"queryNetwork/blockHeight":      raw(`2345678`),
+		"queryLedgerState/epoch":        raw(`150`),
+		"queryLedgerState/eraSummaries": raw(`[{}, {}, {}, {}, {}, {}, {"start": {"time": {"seconds": 1}, "slot": 2, "epoch": 3}, "end": null}]`),
+	})
+	tip, err := c.GetTip()
+	if assert.NoError(t, err) {
+		assert.Equal(t, &provider.Tip{
+			Epoch: 150,
+			Hash:  testTxID,
+			Slot:  61234567,
+			Block: 2345678,
+			Era:   "Conway",
+		}, tip)
+	}
+}
+
+func TestGetProtocolParams(t *testing.T) {
+	assert := assert.New(t)
+	c := stubServer(t, map[string]func(json.RawMessage) (interface{}, *RPCError){
+		"queryLedgerState/protocolParameters": raw(`{
+			"minFeeCoefficient": 44,
+			"minFeeConstant": {"ada": {"lovelace": 155381}},
+			"maxTransactionSize": {"bytes": 16384},
+			"maxValueSize": {"bytes": 5000},
+			"minUtxoDepositCoefficient": 4310,
+			"stakeCredentialDeposit": {"ada": {"lovelace": 2000000}},
+			"collateralPercentage": 150,
+			"maxCollateralInputs": 3,
+			"scriptExecutionPrices": {"memory": "577/10000", "cpu": "721/10000000"},
+			"minFeeReferenceScripts": {"range": 25600, "base": 15, "multiplier": 1.2},
+			"plutusCostModels": {"plutus:v1": [1, 2], "plutus:v3": [3]}
+		}`),
+	})
+	params, err := c.GetProtocolParams()
+	if assert.NoError(err) {
+		assert.Equal(int64(44), params.TxFeePerByte)
+		assert.Equal(int64(155381), params.TxFeeFixed)
+		assert.Equal(int64(16384), params.MaxTxSize)
+		assert.Equal(int64(5000), params.MaxValueSize)
+		assert.Equal(int64(4310), params.UtxoCostPerByte)
+		assert.Equal(int64(2_000_000), params.StakeAddressDeposit)
+		assert.Equal(int64(150), params.CollateralPercentage)
+		assert.Equal(3, params.MaxCollateralInputs)
+		assert.Equal("577/10000", params.ExecutionUnitPrices.PriceMemory.String())
+		assert.Equal("721/10000000", params.ExecutionUnitPrices.PriceSteps.String())
+		assert.Equal("15/1", params.MinFeeRefScriptCostPerByte.String())
+		assert.Equal(ledger.CostModels{ledger.PlutusV1: {1, 2}, ledger.PlutusV3: {3}}, params.CostModels)
+	}
+}
+
+func TestSubmitTx(t *testing.T) {
+	assert := assert.New(t)
+	var gotParams string
+	c := stubServer(t, map[string]func(json.RawMessage) (interface{}, *RPCError){
+		"submitTransaction": func(params json.RawMessage) (interface{}, *RPCError) {
+			gotParams = string(params)
+			if strings.Contains(gotParams, "bad") {
+				return nil, &RPCError{Code: 3117, Message: "The transaction contains unknown UTxO references as inputs."}
+			}
+			if strings.Contains(gotParams, "fee") {
+				return nil, &RPCError{Code: 3999, Message: "FeeTooSmallUTxO"}
+			}
+			return json.RawMessage(`{"transaction": {"id": "` + testTxID + `"}}`), nil
+		},
+	})
+
+	assert.NoError(c.SubmitTx(&provider.Tx{TxBody: "84a0"}))
+	assert.JSONEq(`{"transaction": {"cbor": "84a0"}}`, gotParams)
+
+	err := c.SubmitTx(&provider.Tx{TxBody: "bad"})
+	var submitErr *provider.SubmitError
+	if assert.True(errors.As(err, &submitErr)) {
+		assert.Equal(provider.SubmitErrorInputsSpent, submitErr.Kind)
+	}
+	var rpcErr *RPCError
+	if assert.True(errors.As(err, &rpcErr)) {
+		assert.Equal(3117, rpcErr.Code)
+	}
+
+	// unknown codes fall back to the ledger error message
+	err = c.SubmitTx(&provider.Tx{TxBody: "fee"})
+	if assert.True(errors.As(err, &submitErr)) {
+		assert.Equal(provider.SubmitErrorFeeTooSmall, submitErr.Kind)
+	}
+}
+
+func TestEvaluateTx(t *testing.T) {
+	c := stubServer(t, map[string]func(json.RawMessage) (interface{}, *RPCError){
+		"evaluateTransaction": raw(`[{"validator": {"purpose": "spend", "index": 1}, "budget": {"memory": 1700, "cpu": 476468}}]`),
+	})
+	evals, err := c.EvaluateTx("84a0")
+	if assert.NoError(t, err) && assert.Len(t, evals, 1) {
+		assert.Equal(t, "spend", evals[0].Validator.Purpose)
+		assert.Equal(t, 1, evals[0].Validator.Index)
+		assert.Equal(t, int64(1700), evals[0].Budget.Memory)
+		assert.Equal(t, int64(476468), evals[0].Budget.CPU)
+	}
+
+	_, err = c.GetEraSummaries()
+	var rpcErr *RPCError
+	if assert.True(t, errors.As(err, &rpcErr)) {
+		assert.Equal(t, -32601, rpcErr.Code)
+	}
+}
+
+func TestCallRedials(t *testing.T) {
+	assert := assert.New(t)
+	upgrader := websocket.Upgrader{}
+	var conns int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		n := atomic.AddInt32(&conns, 1)
+		var req struct {
+			ID uint64 `json:"id"`
+		}
+		if err := conn.ReadJSON(&req); err != nil {
+			return
+		}
+		if n == 1 {
+			// drop the first connection without answering
+			return
+		}
+		_ = conn.WriteJSON(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": 42})
+		_ = conn.ReadJSON(&req)
+	}))
+	defer srv.Close()
+	c, err := Dial(context.Background(), "ws"+strings.TrimPrefix(srv.URL, "http"))
+	if !assert.NoError(err) {
+		return
+	}
+	var epoch int
+	assert.Error(c.call("queryLedgerState/epoch", nil, &epoch))
+	assert.NoError(c.call("queryLedgerState/epoch", nil, &epoch))
+	assert.Equal(42, epoch)
+	assert.Equal(int32(2), atomic.LoadInt32(&conns))
+
+	assert.NoError(c.Close())
+	assert.ErrorIs(c.call("queryLedgerState/epoch", nil, &epoch), ErrClosed)
+}
+
+func TestMempoolHasTx(t *testing.T) {
+	assert := assert.New(t)
+	var released bool
+	c := stubServer(t, map[string]func(json.RawMessage) (interface{}, *RPCError){
+		"acquireMempool": raw(`{"acquired": "mempool", "slot": 74159190}`),
+		"hasTransaction": func(params json.RawMessage) (interface{}, *RPCError) {
+			return strings.Contains(string(params), testTxID), nil
+		},
+		"releaseMempool": func(json.RawMessage) (interface{}, *RPCError) {
+			released = true
+			return json.RawMessage(`{"released": "mempool"}`), nil
+		},
+	})
+	exists, err := c.MempoolHasTx(testTxID)
+	assert.NoError(err)
+	assert.True(exists)
+	assert.True(released)
+	exists, err = c.MempoolHasTx(testPolicy)
+	assert.NoError(err)
+	assert.False(exists)
+}
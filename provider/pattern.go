@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/minswap/pab-go/address"
+	"github.com/minswap/pab-go/ledger"
+)
+
+type PatternKind int
+
+const (
+	PatternAsset PatternKind = iota
+	PatternPolicy
+	PatternPaymentCredential
+	PatternOutRef
+)
+
+// Pattern selects UTxOs at any address: those holding an asset or any asset
+// of a policy, those whose address has a payment credential whatever its
+// stake part, or the outputs of a transaction.
+type Pattern struct {
+	Kind PatternKind
+	// Asset is the asset of PatternAsset, or the policy of PatternPolicy.
+	Asset      ledger.Asset
+	Credential address.Credential
+	TxID       string
+	// TxIndex selects a single output of TxID when not nil.
+	TxIndex *int
+}
+
+func AssetPattern(asset ledger.Asset) Pattern {
+	return Pattern{Kind: PatternAsset, Asset: asset}
+}
+
+func PolicyPattern(currencySymbol string) Pattern {
+	return Pattern{Kind: PatternPolicy, Asset: ledger.NewAsset(currencySymbol, "")}
+}
+
+func PaymentCredentialPattern(credential address.Credential) Pattern {
+	return Pattern{Kind: PatternPaymentCredential, Credential: credential}
+}
+
+// TxPattern selects every output of a transaction.
+func TxPattern(txID string) Pattern {
+	return Pattern{Kind: PatternOutRef, TxID: txID}
+}
+
+func OutRefPattern(ref ledger.OutRef) Pattern {
+	index := ref.TxIndex
+	return Pattern{Kind: PatternOutRef, TxID: ref.TxID, TxIndex: &index}
+}
+
+// String returns the pattern in Kupo syntax.
+func (p Pattern) String() string {
+	switch p.Kind {
+	case PatternAsset:
+		return p.Asset.CurrencySymbol + "." + p.Asset.TokenName
+	case PatternPolicy:
+		return p.Asset.CurrencySymbol + ".*"
+	case PatternPaymentCredential:
+		return p.Credential.Hash + "/*"
+	case PatternOutRef:
+		if p.TxIndex == nil {
+			return "*@" + p.TxID
+		}
+		return strconv.Itoa(*p.TxIndex) + "@" + p.TxID
+	default:
+		return fmt.Sprintf("Pattern(%d)", p.Kind)
+	}
+}
+
+func (p Pattern) Match(u ledger.Utxo) bool {
+	switch p.Kind {
+	case PatternAsset:
+		return u.Value.Contains(p.Asset)
+	case PatternPolicy:
+		for asset := range u.Value {
+			if asset.CurrencySymbol == p.Asset.CurrencySymbol {
+				return true
+			}
+		}
+		return false
+	case PatternPaymentCredential:
+		addr, err := address.Parse(u.Address)
+		return err == nil && addr.Payment != nil && *addr.Payment == p.Credential
+	case PatternOutRef:
+		return u.TxID == p.TxID && (p.TxIndex == nil || u.TxIndex == *p.TxIndex)
+	default:
+		return false
+	}
+}
+
+// PatternQuerier queries unspent outputs by pattern.
+type PatternQuerier interface {
+	GetUtxosByPattern(pattern Pattern) ([]ledger.Utxo, error)
+}
+
+// WholeUtxoQuerier queries the whole UTxO set, as cardano-cli does with
+// query utxo --whole-utxo.
+type WholeUtxoQuerier interface {
+	GetAllUtxos() ([]ledger.Utxo, error)
+}
+
+// FilterAllUtxos is a PatternQuerier for providers without pattern queries:
+// it fetches the whole UTxO set and filters it, which is slow on mainnet.
+type FilterAllUtxos struct {
+	Querier WholeUtxoQuerier
+}
+
+var _ PatternQuerier = FilterAllUtxos{}
+
+func (f FilterAllUtxos) GetUtxosByPattern(pattern Pattern) ([]ledger.Utxo, error) {
+	utxos, err := f.Querier.GetAllUtxos()
+	if err != nil {
+		return nil, err
+	}
+	return FilterUtxos(utxos, pattern), nil
+}
+
+func FilterUtxos(utxos []ledger.Utxo, pattern Pattern) []ledger.Utxo {
+	var matches []ledger.Utxo
+	for _, u := range utxos {
+		if pattern.Match(u) {
+			matches = append(matches, u)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/minswap/pab-go/address"
+	"github.com/minswap/pab-go/ledger"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testTxID   = "5ca53b0eb10f317a5f1bf1bda679a04a8dd01c156643deee1d406ec2cc15e9e3"
+	testPolicy = "29d222ce763455e3d7a09a665ce554f00ac89d2e99a1a83d267170c6"
+	testHash   = "76b0d16f5d09ac02dd1786981066f6fedf7ac165a08b3b6f0fb33f03"
+)
+
+type wholeUtxo []ledger.Utxo
+
+func (w wholeUtxo) GetAllUtxos() ([]ledger.Utxo, error) {
+	if w == nil {
+		return nil, errors.New("node not running")
+	}
+	return w, nil
+}
+
+func TestPattern(t *testing.T) {
+	assert := assert.New(t)
+	payment := address.NewKeyCredential(testHash)
+	base, err := address.NewBaseAddress(address.Testnet, payment, address.NewScriptCredential(testPolicy))
+	assert.NoError(err)
+	enterprise, err := address.NewEnterpriseAddress(address.Testnet, payment)
+	assert.NoError(err)
+	other, err := address.NewEnterpriseAddress(address.Testnet, address.NewScriptCredential(testHash))
+	assert.NoError(err)
+
+	token := ledger.NewAsset(testPolicy, "4d494e")
+	utxos := []ledger.Utxo{
+		{TxID: testTxID, TxIndex: 0, Address: base.String(), Value: ledger.NewValue().Add(ledger.ADA, big.NewInt(2_000_000)).Add(token, big.NewInt(1))},
+		{TxID: testTxID, TxIndex: 1, Address: enterprise.String(), Value: ledger.NewValue().Add(ledger.ADA, big.NewInt(2_000_000)).Add(ledger.NewAsset(testPolicy, ""), big.NewInt(1))},
+		{TxID: testHash, TxIndex: 1, Address: other.String(), Value: ledger.NewValue().Add(ledger.ADA, big.NewInt(2_000_000))},
+	}
+
+	tests := []struct {
+		pattern Pattern
+		kupo    string
+		matches []ledger.Utxo
+	}{
+		{AssetPattern(token), testPolicy + ".4d494e", utxos[:1]},
+		{PolicyPattern(testPolicy), testPolicy + ".*", utxos[:2]},
+		{PaymentCredentialPattern(payment), testHash + "/*", utxos[:2]},
+		{TxPattern(testTxID), "*@" + testTxID, utxos[:2]},
+		{OutRefPattern(ledger.OutRef{TxID: testTxID, TxIndex: 1}), "1@" + testTxID, utxos[1:2]},
+	}
+	for _, test := range tests {
+		assert.Equal(test.kupo, test.pattern.String())
+		assert.Equal(test.matches, FilterUtxos(utxos, test.pattern), test.kupo)
+	}
+
+	res, err := FilterAllUtxos{Querier: wholeUtxo(utxos)}.GetUtxosByPattern(PolicyPattern(testPolicy))
+	if assert.NoError(err) {
+		assert.Equal(utxos[:2], res)
+	}
+	_, err = FilterAllUtxos{Querier: wholeUtxo(nil)}.GetUtxosByPattern(PolicyPattern(testPolicy))
+	assert.Error(err)
+}
@@ -0,0 +1,63 @@
+// Package provider abstracts chain queries and transaction submission, so
+// that transaction logic does not depend on cardano-cli and a local node.
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/minswap/pab-go/ledger"
+)
+
+// Tip is the chain tip. SyncProgress is the percentage reported by
+// cardano-cli, empty when a provider does not report it.
+type Tip struct {
+	Epoch        int    `json:"epoch"`
+	Hash         string `json:"hash"`
+	Slot         int    `json:"slot"`
+	Block        int    `json:"block"`
+	Era          string `json:"era"`
+	SyncProgress string `json:"syncProgress"`
+}
+
+// Tx is a built transaction. TxBody is the hex-encoded CBOR of the whole
+// transaction, signed or not.
+type Tx struct {
+	TxHash string `json:"txHash"`
+	TxBody string `json:"txBody"`
+}
+
+// ChainProvider queries the chain and submits transactions.
+type ChainProvider interface {
+	GetTip() (*Tip, error)
+	GetProtocolParams() (*ledger.ProtocolParams, error)
+	GetUtxosByAddresses(addresses ...string) ([]ledger.Utxo, error)
+	GetUtxosByTxIns(txIns ...ledger.OutRef) ([]ledger.Utxo, error)
+	// SubmitTx submits a signed transaction, failing with a *SubmitError
+	// when the node rejects it.
+	SubmitTx(tx *Tx) error
+	// AwaitTx blocks until the transaction is on chain or ctx is done. See
+	// TrackTx to also follow it in the mempool.
+	AwaitTx(ctx context.Context, txHash string) error
+}
+
+// PollTx calls isOnChain every interval until it returns true, an error, or
+// ctx is done.
+func PollTx(ctx context.Context, interval time.Duration, isOnChain func() (bool, error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		ok, err := isOnChain()
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyLedgerError(t *testing.T) {
+	msg := `Command failed: transaction submit  Error: Error while submitting tx: ShelleyTxValidationError ShelleyBasedEraConway (ApplyTxError (ConwayUtxowFailure (UtxoFailure (BadInputsUTxO (fromList [TxIn (TxId {unTxId = SafeHash "5ca5"}) (TxIx 0)]))) :| []))`
+	assert.Equal(t, SubmitErrorInputsSpent, ClassifyLedgerError(msg))
+	assert.Equal(t, SubmitErrorFeeTooSmall, ClassifyLedgerError("(FeeTooSmallUTxO (Coin 170000) (Coin 160000))"))
+	assert.Equal(t, SubmitErrorUnknown, ClassifyLedgerError("connection refused"))
+
+	cause := errors.New("exit status 1")
+	var err error = &SubmitError{Kind: SubmitErrorTxTooLarge, Message: "MaxTxSizeUTxO 17000 16384", Err: cause}
+	assert.ErrorIs(t, err, cause)
+	assert.Equal(t, "tx rejected (tx too large): MaxTxSizeUTxO 17000 16384", err.Error())
+}
+
+func TestPollTx(t *testing.T) {
+	calls := 0
+	err := PollTx(context.Background(), time.Millisecond, func() (bool, error) {
+		calls++
+		return calls == 3, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	err = PollTx(ctx, time.Millisecond, func() (bool, error) { return false, nil })
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
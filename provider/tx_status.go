@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/minswap/pab-go/ledger"
+)
+
+type TxStatus int
+
+const (
+	// TxStatusUnknown means the tx is neither in the mempool nor on chain
+	// with its first output unspent: it was evicted, never submitted, or that
+	// output is already spent.
+	TxStatusUnknown TxStatus = iota
+	TxStatusInMempool
+	TxStatusOnChain
+)
+
+func (s TxStatus) String() string {
+	switch s {
+	case TxStatusInMempool:
+		return "in mempool"
+	case TxStatusOnChain:
+		return "on chain"
+	default:
+		return "unknown"
+	}
+}
+
+// MempoolQuerier reports whether a tx is in the mempool of the node.
+type MempoolQuerier interface {
+	MempoolHasTx(txHash string) (bool, error)
+}
+
+// GetTxStatus tells whether a submitted tx is on chain or still pending. The
+// mempool is only checked when p is a MempoolQuerier. It is checked before
+// the chain: a tx leaving the mempool between the two queries is then seen
+// on chain rather than missed by both.
+func GetTxStatus(p ChainProvider, txHash string) (TxStatus, error) {
+	inMempool := false
+	if mempool, ok := p.(MempoolQuerier); ok {
+		var err error
+		if inMempool, err = mempool.MempoolHasTx(txHash); err != nil {
+			return TxStatusUnknown, fmt.Errorf("fail to query mempool: %w", err)
+		}
+	}
+	utxos, err := p.GetUtxosByTxIns(ledger.OutRef{TxID: txHash, TxIndex: 0})
+	if err != nil {
+		return TxStatusUnknown, fmt.Errorf("fail to query tx outputs: %w", err)
+	}
+	if len(utxos) > 0 {
+		return TxStatusOnChain, nil
+	}
+	if inMempool {
+		return TxStatusInMempool, nil
+	}
+	return TxStatusUnknown, nil
+}
+
+// TrackTx polls the status of a submitted tx every interval until it is on
+// chain or ctx is done, calling onStatus, if not nil, with the first status
+// and every change, e.g. TxStatusInMempool then TxStatusOnChain.
+func TrackTx(ctx context.Context, p ChainProvider, txHash string, interval time.Duration, onStatus func(TxStatus)) error {
+	last := TxStatus(-1)
+	return PollTx(ctx, interval, func() (bool, error) {
+		status, err := GetTxStatus(p, txHash)
+		if err != nil {
+			return false, err
+		}
+		if status != last && onStatus != nil {
+			onStatus(status)
+		}
+		last = status
+		return status == TxStatusOnChain, nil
+	})
+}
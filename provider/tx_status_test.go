@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/minswap/pab-go/ledger"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeProvider struct {
+	ChainProvider
+	onChain []ledger.Utxo
+}
+
+func (p fakeProvider) GetUtxosByTxIns(txIns ...ledger.OutRef) ([]ledger.Utxo, error) {
+	return FilterUtxos(p.onChain, OutRefPattern(txIns[0])), nil
+}
+
+type fakeMempoolProvider struct {
+	fakeProvider
+	mempool []string
+}
+
+func (p fakeMempoolProvider) MempoolHasTx(txHash string) (bool, error) {
+	for _, h := range p.mempool {
+		if h == txHash {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// movingProvider reports the tx unknown, then in the mempool, then on chain,
+// one status per poll.
+type movingProvider struct {
+	ChainProvider
+	polls int
+}
+
+func (p *movingProvider) MempoolHasTx(txHash string) (bool, error) {
+	return p.polls == 1, nil
+}
+
+func (p *movingProvider) GetUtxosByTxIns(txIns ...ledger.OutRef) ([]ledger.Utxo, error) {
+	p.polls++
+	if p.polls < 3 {
+		return nil, nil
+	}
+	return []ledger.Utxo{{TxID: testTxID}}, nil
+}
+
+func TestGetTxStatus(t *testing.T) {
+	assert := assert.New(t)
+	chain := fakeProvider{onChain: []ledger.Utxo{{TxID: testTxID, Value: ledger.NewValue().Add(ledger.ADA, big.NewInt(1))}}}
+
+	status, err := GetTxStatus(chain, testTxID)
+	assert.NoError(err)
+	assert.Equal(TxStatusOnChain, status)
+	status, err = GetTxStatus(chain, testHash)
+	assert.NoError(err)
+	assert.Equal(TxStatusUnknown, status)
+
+	withMempool := fakeMempoolProvider{fakeProvider: chain, mempool: []string{testHash}}
+	status, err = GetTxStatus(withMempool, testHash)
+	assert.NoError(err)
+	assert.Equal(TxStatusInMempool, status)
+	assert.Equal("in mempool", status.String())
+
+	// a tx in both, being included, is on chain
+	withMempool.mempool = append(withMempool.mempool, testTxID)
+	status, err = GetTxStatus(withMempool, testTxID)
+	assert.NoError(err)
+	assert.Equal(TxStatusOnChain, status)
+}
+
+func TestTrackTx(t *testing.T) {
+	var statuses []TxStatus
+	p := &movingProvider{}
+	err := TrackTx(context.Background(), p, testTxID, time.Millisecond, func(s TxStatus) {
+		statuses = append(statuses, s)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []TxStatus{TxStatusUnknown, TxStatusInMempool, TxStatusOnChain}, statuses)
+}
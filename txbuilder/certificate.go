@@ -0,0 +1,391 @@
+package txbuilder
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/minswap/pab-go/address"
+	"github.com/minswap/pab-go/cbor"
+)
+
+type CertificateKind byte
+
+const (
+	StakeRegistration CertificateKind = iota
+	StakeDeregistration
+	StakeDelegation
+	// Conway governance certificates
+	VoteDelegation
+	DRepRegistration
+	DRepDeregistration
+	DRepUpdate
+)
+
+// Certificate is a stake or governance certificate. ScriptFilePath is set when
+// the stake or DRep credential is a script, RedeemerValue and execution units
+// when that script is a Plutus script.
+type Certificate struct {
+	Kind            CertificateKind
+	StakeCredential address.Credential
+	PoolKeyHash     string
+	DRep            DRep
+	DRepCredential  address.Credential
+	Deposit         int64
+	Anchor          *Anchor
+	ScriptFilePath  string
+	RedeemerValue   string
+	ExMem           int64
+	ExCPU           int64
+}
+
+func encodeCredential(cred address.Credential) ([]interface{}, error) {
+	if err := cred.Validate(); err != nil {
+		return nil, err
+	}
+	hash, _ := hex.DecodeString(cred.Hash)
+	return []interface{}{uint64(cred.Type), hash}, nil
+}
+
+// IsConway reports whether the certificate only exists since the Conway era.
+func (c Certificate) IsConway() bool {
+	return c.Kind >= VoteDelegation
+}
+
+func (c Certificate) MarshalCBOR() ([]byte, error) {
+	switch c.Kind {
+	case DRepRegistration, DRepDeregistration, DRepUpdate:
+		return c.marshalDRepCertificate()
+	}
+	cred, err := encodeCredential(c.StakeCredential)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stake credential: %w", err)
+	}
+	switch c.Kind {
+	case StakeRegistration:
+		return cbor.Marshal([]interface{}{uint64(0), cred})
+	case StakeDeregistration:
+		return cbor.Marshal([]interface{}{uint64(1), cred})
+	case StakeDelegation:
+		pool, err := hex.DecodeString(c.PoolKeyHash)
+		if err != nil || len(pool) != address.HashLength {
+			return nil, fmt.Errorf("invalid pool key hash: %s", c.PoolKeyHash)
+		}
+		return cbor.Marshal([]interface{}{uint64(2), cred, pool})
+	case VoteDelegation:
+		drep, err := c.DRep.encode()
+		if err != nil {
+			return nil, err
+		}
+		return cbor.Marshal([]interface{}{uint64(9), cred, drep})
+	default:
+		return nil, fmt.Errorf("unknown certificate kind %d", c.Kind)
+	}
+}
+
+func (c Certificate) marshalDRepCertificate() ([]byte, error) {
+	cred, err := encodeCredential(c.DRepCredential)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DRep credential: %w", err)
+	}
+	anchor, err := encodeAnchor(c.Anchor)
+	if err != nil {
+		return nil, err
+	}
+	if c.Deposit < 0 {
+		return nil, fmt.Errorf("invalid DRep deposit %d", c.Deposit)
+	}
+	switch c.Kind {
+	case DRepRegistration:
+		return cbor.Marshal([]interface{}{uint64(16), cred, uint64(c.Deposit), anchor})
+	case DRepDeregistration:
+		return cbor.Marshal([]interface{}{uint64(17), cred, uint64(c.Deposit)})
+	default:
+		return cbor.Marshal([]interface{}{uint64(18), cred, anchor})
+	}
+}
+
+// Withdrawal withdraws Amount lovelace from a reward address. ScriptFilePath,
+// RedeemerValue and execution units are set for script reward addresses.
+type Withdrawal struct {
+	RewardAddress  string
+	Amount         int64
+	ScriptFilePath string
+	RedeemerValue  string
+	ExMem          int64
+	ExCPU          int64
+}
+
+func RegisterStake(cred address.Credential) Option {
+	return func(b *TxBuilder) {
+		b.Certificates = append(b.Certificates, Certificate{
+			Kind:            StakeRegistration,
+			StakeCredential: cred,
+		})
+	}
+}
+
+func DeregisterStake(cred address.Credential) Option {
+	return func(b *TxBuilder) {
+		b.Certificates = append(b.Certificates, Certificate{
+			Kind:            StakeDeregistration,
+			StakeCredential: cred,
+		})
+	}
+}
+
+// DeregisterStakeScript deregisters a script stake credential. Leave redeemer
+// empty for native scripts.
+func DeregisterStakeScript(cred address.Credential, scriptFilePath, redeemer string) Option {
+	return func(b *TxBuilder) {
+		b.Certificates = append(b.Certificates, Certificate{
+			Kind:            StakeDeregistration,
+			StakeCredential: cred,
+			ScriptFilePath:  scriptFilePath,
+			RedeemerValue:   redeemer,
+		})
+	}
+}
+
+func DeregisterStakeScriptRaw(cred address.Credential, scriptFilePath, redeemer string, exMem, exCPU int64) Option {
+	return func(b *TxBuilder) {
+		b.Certificates = append(b.Certificates, Certificate{
+			Kind:            StakeDeregistration,
+			StakeCredential: cred,
+			ScriptFilePath:  scriptFilePath,
+			RedeemerValue:   redeemer,
+			ExMem:           exMem,
+			ExCPU:           exCPU,
+		})
+	}
+}
+
+func DelegateStake(cred address.Credential, poolKeyHash string) Option {
+	return func(b *TxBuilder) {
+		b.Certificates = append(b.Certificates, Certificate{
+			Kind:            StakeDelegation,
+			StakeCredential: cred,
+			PoolKeyHash:     poolKeyHash,
+		})
+	}
+}
+
+// DelegateStakeScript delegates a script stake credential. Leave redeemer
+// empty for native scripts.
+func DelegateStakeScript(cred address.Credential, poolKeyHash, scriptFilePath, redeemer string) Option {
+	return func(b *TxBuilder) {
+		b.Certificates = append(b.Certificates, Certificate{
+			Kind:            StakeDelegation,
+			StakeCredential: cred,
+			PoolKeyHash:     poolKeyHash,
+			ScriptFilePath:  scriptFilePath,
+			RedeemerValue:   redeemer,
+		})
+	}
+}
+
+func DelegateStakeScriptRaw(cred address.Credential, poolKeyHash, scriptFilePath, redeemer string, exMem, exCPU int64) Option {
+	return func(b *TxBuilder) {
+		b.Certificates = append(b.Certificates, Certificate{
+			Kind:            StakeDelegation,
+			StakeCredential: cred,
+			PoolKeyHash:     poolKeyHash,
+			ScriptFilePath:  scriptFilePath,
+			RedeemerValue:   redeemer,
+			ExMem:           exMem,
+			ExCPU:           exCPU,
+		})
+	}
+}
+
+func WithdrawRewards(rewardAddr string, amount int64) Option {
+	return func(b *TxBuilder) {
+		b.Withdrawals = append(b.Withdrawals, Withdrawal{
+			RewardAddress: rewardAddr,
+			Amount:        amount,
+		})
+	}
+}
+
+// WithdrawRewardsScript withdraws from a script reward address. Withdrawing 0
+// lovelace runs the staking validator without moving funds, which lets one
+// script execution validate many inputs.
+func WithdrawRewardsScript(rewardAddr string, amount int64, scriptFilePath, redeemer string) Option {
+	return func(b *TxBuilder) {
+		b.Withdrawals = append(b.Withdrawals, Withdrawal{
+			RewardAddress:  rewardAddr,
+			Amount:         amount,
+			ScriptFilePath: scriptFilePath,
+			RedeemerValue:  redeemer,
+		})
+	}
+}
+
+func WithdrawRewardsScriptRaw(rewardAddr string, amount int64, scriptFilePath, redeemer string, exMem, exCPU int64) Option {
+	return func(b *TxBuilder) {
+		b.Withdrawals = append(b.Withdrawals, Withdrawal{
+			RewardAddress:  rewardAddr,
+			Amount:         amount,
+			ScriptFilePath: scriptFilePath,
+			RedeemerValue:  redeemer,
+			ExMem:          exMem,
+			ExCPU:          exCPU,
+		})
+	}
+}
+
+// AddCertificate adds any certificate, for combinations the other options do
+// not cover.
+func AddCertificate(cert Certificate) Option {
+	return func(b *TxBuilder) {
+		b.Certificates = append(b.Certificates, cert)
+	}
+}
+
+func DelegateVote(cred address.Credential, drep DRep) Option {
+	return func(b *TxBuilder) {
+		b.Certificates = append(b.Certificates, Certificate{
+			Kind:            VoteDelegation,
+			StakeCredential: cred,
+			DRep:            drep,
+		})
+	}
+}
+
+func DelegateVoteScript(cred address.Credential, drep DRep, scriptFilePath, redeemer string) Option {
+	return func(b *TxBuilder) {
+		b.Certificates = append(b.Certificates, Certificate{
+			Kind:            VoteDelegation,
+			StakeCredential: cred,
+			DRep:            drep,
+			ScriptFilePath:  scriptFilePath,
+			RedeemerValue:   redeemer,
+		})
+	}
+}
+
+func DelegateVoteScriptRaw(cred address.Credential, drep DRep, scriptFilePath, redeemer string, exMem, exCPU int64) Option {
+	return func(b *TxBuilder) {
+		b.Certificates = append(b.Certificates, Certificate{
+			Kind:            VoteDelegation,
+			StakeCredential: cred,
+			DRep:            drep,
+			ScriptFilePath:  scriptFilePath,
+			RedeemerValue:   redeemer,
+			ExMem:           exMem,
+			ExCPU:           exCPU,
+		})
+	}
+}
+
+func RegisterDRep(cred address.Credential, deposit int64, anchor *Anchor) Option {
+	return func(b *TxBuilder) {
+		b.Certificates = append(b.Certificates, Certificate{
+			Kind:           DRepRegistration,
+			DRepCredential: cred,
+			Deposit:        deposit,
+			Anchor:         anchor,
+		})
+	}
+}
+
+// RegisterDRepScript registers a script DRep. Leave redeemer empty for native scripts.
+func RegisterDRepScript(cred address.Credential, deposit int64, anchor *Anchor, scriptFilePath, redeemer string) Option {
+	return func(b *TxBuilder) {
+		b.Certificates = append(b.Certificates, Certificate{
+			Kind:           DRepRegistration,
+			DRepCredential: cred,
+			Deposit:        deposit,
+			Anchor:         anchor,
+			ScriptFilePath: scriptFilePath,
+			RedeemerValue:  redeemer,
+		})
+	}
+}
+
+func RegisterDRepScriptRaw(cred address.Credential, deposit int64, anchor *Anchor, scriptFilePath, redeemer string, exMem, exCPU int64) Option {
+	return func(b *TxBuilder) {
+		b.Certificates = append(b.Certificates, Certificate{
+			Kind:           DRepRegistration,
+			DRepCredential: cred,
+			Deposit:        deposit,
+			Anchor:         anchor,
+			ScriptFilePath: scriptFilePath,
+			RedeemerValue:  redeemer,
+			ExMem:          exMem,
+			ExCPU:          exCPU,
+		})
+	}
+}
+
+// DeregisterDRep retires a DRep, deposit must equal the deposit paid at registration.
+func DeregisterDRep(cred address.Credential, deposit int64) Option {
+	return func(b *TxBuilder) {
+		b.Certificates = append(b.Certificates, Certificate{
+			Kind:           DRepDeregistration,
+			DRepCredential: cred,
+			Deposit:        deposit,
+		})
+	}
+}
+
+func DeregisterDRepScript(cred address.Credential, deposit int64, scriptFilePath, redeemer string) Option {
+	return func(b *TxBuilder) {
+		b.Certificates = append(b.Certificates, Certificate{
+			Kind:           DRepDeregistration,
+			DRepCredential: cred,
+			Deposit:        deposit,
+			ScriptFilePath: scriptFilePath,
+			RedeemerValue:  redeemer,
+		})
+	}
+}
+
+func DeregisterDRepScriptRaw(cred address.Credential, deposit int64, scriptFilePath, redeemer string, exMem, exCPU int64) Option {
+	return func(b *TxBuilder) {
+		b.Certificates = append(b.Certificates, Certificate{
+			Kind:           DRepDeregistration,
+			DRepCredential: cred,
+			Deposit:        deposit,
+			ScriptFilePath: scriptFilePath,
+			RedeemerValue:  redeemer,
+			ExMem:          exMem,
+			ExCPU:          exCPU,
+		})
+	}
+}
+
+func UpdateDRep(cred address.Credential, anchor *Anchor) Option {
+	return func(b *TxBuilder) {
+		b.Certificates = append(b.Certificates, Certificate{
+			Kind:           DRepUpdate,
+			DRepCredential: cred,
+			Anchor:         anchor,
+		})
+	}
+}
+
+func UpdateDRepScript(cred address.Credential, anchor *Anchor, scriptFilePath, redeemer string) Option {
+	return func(b *TxBuilder) {
+		b.Certificates = append(b.Certificates, Certificate{
+			Kind:           DRepUpdate,
+			DRepCredential: cred,
+			Anchor:         anchor,
+			ScriptFilePath: scriptFilePath,
+			RedeemerValue:  redeemer,
+		})
+	}
+}
+
+func UpdateDRepScriptRaw(cred address.Credential, anchor *Anchor, scriptFilePath, redeemer string, exMem, exCPU int64) Option {
+	return func(b *TxBuilder) {
+		b.Certificates = append(b.Certificates, Certificate{
+			Kind:           DRepUpdate,
+			DRepCredential: cred,
+			Anchor:         anchor,
+			ScriptFilePath: scriptFilePath,
+			RedeemerValue:  redeemer,
+			ExMem:          exMem,
+			ExCPU:          exCPU,
+		})
+	}
+}
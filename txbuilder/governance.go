@@ -0,0 +1,440 @@
+package txbuilder
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/minswap/pab-go/address"
+	"github.com/minswap/pab-go/cbor"
+	"github.com/minswap/pab-go/ledger"
+)
+
+// Anchor points to off-chain metadata and its blake2b-256 hash.
+type Anchor struct {
+	URL      string
+	DataHash string
+}
+
+func encodeAnchor(a *Anchor) (interface{}, error) {
+	if a == nil {
+		return nil, nil
+	}
+	hash, err := hex.DecodeString(a.DataHash)
+	if err != nil || len(hash) != 32 {
+		return nil, fmt.Errorf("invalid anchor data hash: %s", a.DataHash)
+	}
+	return []interface{}{a.URL, hash}, nil
+}
+
+type DRepKind byte
+
+const (
+	DRepKeyHash DRepKind = iota
+	DRepScriptHash
+	DRepAlwaysAbstain
+	DRepAlwaysNoConfidence
+)
+
+// DRep is the target of a vote delegation. Hash is only set for key hash and
+// script hash DReps.
+type DRep struct {
+	Kind DRepKind
+	Hash string
+}
+
+func (d DRep) encode() ([]interface{}, error) {
+	switch d.Kind {
+	case DRepKeyHash, DRepScriptHash:
+		hash, err := hex.DecodeString(d.Hash)
+		if err != nil || len(hash) != address.HashLength {
+			return nil, fmt.Errorf("invalid DRep hash: %s", d.Hash)
+		}
+		return []interface{}{uint64(d.Kind), hash}, nil
+	case DRepAlwaysAbstain, DRepAlwaysNoConfidence:
+		return []interface{}{uint64(d.Kind)}, nil
+	default:
+		return nil, fmt.Errorf("unknown DRep kind %d", d.Kind)
+	}
+}
+
+type VoterKind byte
+
+const (
+	VoterCommitteeHotKey VoterKind = iota
+	VoterCommitteeHotScript
+	VoterDRepKey
+	VoterDRepScript
+	VoterStakePool
+)
+
+type Voter struct {
+	Kind VoterKind
+	Hash string
+}
+
+type GovActionID struct {
+	TxID  string
+	Index int
+}
+
+func (id GovActionID) encode() ([]interface{}, error) {
+	txID, err := hex.DecodeString(id.TxID)
+	if err != nil || len(txID) != 32 {
+		return nil, fmt.Errorf("invalid governance action tx id: %s", id.TxID)
+	}
+	return []interface{}{txID, uint64(id.Index)}, nil
+}
+
+func encodePrevActionID(id *GovActionID) (interface{}, error) {
+	if id == nil {
+		return nil, nil
+	}
+	return id.encode()
+}
+
+type VoteChoice byte
+
+const (
+	VoteNo VoteChoice = iota
+	VoteYes
+	VoteAbstain
+)
+
+// Vote is a single voting procedure. ScriptFilePath, RedeemerValue and
+// execution units are set for script voters.
+type Vote struct {
+	Voter          Voter
+	ActionID       GovActionID
+	Choice         VoteChoice
+	Anchor         *Anchor
+	ScriptFilePath string
+	RedeemerValue  string
+	ExMem          int64
+	ExCPU          int64
+}
+
+// MarshalCBOR encodes the vote as voting procedures with a single entry.
+func (v Vote) MarshalCBOR() ([]byte, error) {
+	if v.Voter.Kind > VoterStakePool {
+		return nil, fmt.Errorf("unknown voter kind %d", v.Voter.Kind)
+	}
+	voterHash, err := hex.DecodeString(v.Voter.Hash)
+	if err != nil || len(voterHash) != address.HashLength {
+		return nil, fmt.Errorf("invalid voter hash: %s", v.Voter.Hash)
+	}
+	actionID, err := v.ActionID.encode()
+	if err != nil {
+		return nil, err
+	}
+	anchor, err := encodeAnchor(v.Anchor)
+	if err != nil {
+		return nil, err
+	}
+	return cbor.Marshal(cbor.Map{{
+		Key: []interface{}{uint64(v.Voter.Kind), voterHash},
+		Value: cbor.Map{{
+			Key:   actionID,
+			Value: []interface{}{uint64(v.Choice), anchor},
+		}},
+	}})
+}
+
+// GovAction is the action of a governance proposal.
+type GovAction interface {
+	encodeGovAction() (interface{}, error)
+}
+
+type ParameterChangeAction struct {
+	PrevActionID *GovActionID
+	// UpdateCBORHex is the CBOR-encoded protocol parameter update map.
+	UpdateCBORHex       string
+	GuardrailScriptHash string
+}
+
+func encodeGuardrail(hash string) (interface{}, error) {
+	if hash == "" {
+		return nil, nil
+	}
+	b, err := hex.DecodeString(hash)
+	if err != nil || len(b) != address.HashLength {
+		return nil, fmt.Errorf("invalid guardrail script hash: %s", hash)
+	}
+	return b, nil
+}
+
+func (a ParameterChangeAction) encodeGovAction() (interface{}, error) {
+	prev, err := encodePrevActionID(a.PrevActionID)
+	if err != nil {
+		return nil, err
+	}
+	update, err := hex.DecodeString(a.UpdateCBORHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid protocol parameter update: %w", err)
+	}
+	guardrail, err := encodeGuardrail(a.GuardrailScriptHash)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{uint64(0), prev, cbor.RawMessage(update), guardrail}, nil
+}
+
+// TreasuryWithdrawalsAction withdraws lovelace from the treasury to reward addresses.
+type TreasuryWithdrawalsAction struct {
+	Withdrawals         map[string]int64
+	GuardrailScriptHash string
+}
+
+func (a TreasuryWithdrawalsAction) encodeGovAction() (interface{}, error) {
+	withdrawals := make(cbor.Map, 0, len(a.Withdrawals))
+	for rewardAddr, amount := range a.Withdrawals {
+		addr, err := address.Parse(rewardAddr)
+		if err != nil {
+			return nil, err
+		}
+		if addr.Kind != address.KindReward {
+			return nil, fmt.Errorf("%s is not a reward address", rewardAddr)
+		}
+		if amount <= 0 {
+			return nil, fmt.Errorf("invalid treasury withdrawal of %d lovelace to %s", amount, rewardAddr)
+		}
+		withdrawals = append(withdrawals, cbor.MapEntry{Key: addr.Bytes(), Value: uint64(amount)})
+	}
+	guardrail, err := encodeGuardrail(a.GuardrailScriptHash)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{uint64(2), withdrawals, guardrail}, nil
+}
+
+// HardForkInitiationAction moves the protocol to version Major.Minor.
+type HardForkInitiationAction struct {
+	PrevActionID *GovActionID
+	Major        uint64
+	Minor        uint64
+}
+
+func (a HardForkInitiationAction) encodeGovAction() (interface{}, error) {
+	prev, err := encodePrevActionID(a.PrevActionID)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{uint64(1), prev, []interface{}{a.Major, a.Minor}}, nil
+}
+
+type NoConfidenceAction struct {
+	PrevActionID *GovActionID
+}
+
+func (a NoConfidenceAction) encodeGovAction() (interface{}, error) {
+	prev, err := encodePrevActionID(a.PrevActionID)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{uint64(3), prev}, nil
+}
+
+// UpdateCommitteeAction removes committee members, adds members with the
+// epoch their term expires at, and sets the quorum.
+type UpdateCommitteeAction struct {
+	PrevActionID *GovActionID
+	Remove       []address.Credential
+	Add          map[address.Credential]uint64
+	Quorum       *ledger.Rational
+}
+
+func (a UpdateCommitteeAction) encodeGovAction() (interface{}, error) {
+	prev, err := encodePrevActionID(a.PrevActionID)
+	if err != nil {
+		return nil, err
+	}
+	remove := sortedCredentials(a.Remove)
+	removed := make([]interface{}, len(remove))
+	for i, cred := range remove {
+		if removed[i], err = encodeCredential(cred); err != nil {
+			return nil, fmt.Errorf("invalid committee credential: %w", err)
+		}
+	}
+	add := make([]address.Credential, 0, len(a.Add))
+	for cred := range a.Add {
+		add = append(add, cred)
+	}
+	added := make(cbor.Map, 0, len(add))
+	for _, cred := range sortedCredentials(add) {
+		c, err := encodeCredential(cred)
+		if err != nil {
+			return nil, fmt.Errorf("invalid committee credential: %w", err)
+		}
+		added = append(added, cbor.MapEntry{Key: c, Value: a.Add[cred]})
+	}
+	addedMap, err := encodeOrderedMap(added)
+	if err != nil {
+		return nil, err
+	}
+	if a.Quorum == nil || a.Quorum.Sign() < 0 || a.Quorum.Cmp(big.NewRat(1, 1)) > 0 {
+		return nil, fmt.Errorf("invalid committee quorum: %v", a.Quorum)
+	}
+	quorum := cbor.Tag{Number: cbor.TagRational, Content: []interface{}{a.Quorum.Num(), a.Quorum.Denom()}}
+	return []interface{}{uint64(4), prev, cbor.Tag{Number: cbor.TagSet, Content: removed}, addedMap, quorum}, nil
+}
+
+// sortedCredentials returns creds in the ledger order.
+func sortedCredentials(creds []address.Credential) []address.Credential {
+	sorted := append([]address.Credential{}, creds...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return credentialLess(sorted[i].IsScript(), sorted[i].Hash, sorted[j].IsScript(), sorted[j].Hash)
+	})
+	return sorted
+}
+
+// NewConstitutionAction replaces the constitution with the document at
+// Anchor and its guardrail script, if any.
+type NewConstitutionAction struct {
+	PrevActionID        *GovActionID
+	Anchor              Anchor
+	GuardrailScriptHash string
+}
+
+func (a NewConstitutionAction) encodeGovAction() (interface{}, error) {
+	prev, err := encodePrevActionID(a.PrevActionID)
+	if err != nil {
+		return nil, err
+	}
+	anchor, err := encodeAnchor(&a.Anchor)
+	if err != nil {
+		return nil, err
+	}
+	guardrail, err := encodeGuardrail(a.GuardrailScriptHash)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{uint64(5), prev, []interface{}{anchor, guardrail}}, nil
+}
+
+type InfoAction struct{}
+
+func (InfoAction) encodeGovAction() (interface{}, error) {
+	return []interface{}{uint64(6)}, nil
+}
+
+// Proposal is a governance proposal procedure. ScriptFilePath, RedeemerValue
+// and execution units are set when the action is checked by the constitution
+// guardrail script.
+type Proposal struct {
+	Deposit        int64
+	ReturnAddress  string
+	Action         GovAction
+	Anchor         Anchor
+	ScriptFilePath string
+	RedeemerValue  string
+	ExMem          int64
+	ExCPU          int64
+}
+
+func (p Proposal) MarshalCBOR() ([]byte, error) {
+	returnAddr, err := address.Parse(p.ReturnAddress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid return address: %w", err)
+	}
+	if returnAddr.Kind != address.KindReward {
+		return nil, fmt.Errorf("return address %s is not a reward address", p.ReturnAddress)
+	}
+	if p.Action == nil {
+		return nil, fmt.Errorf("missing governance action")
+	}
+	if p.Deposit < 0 {
+		return nil, fmt.Errorf("invalid proposal deposit %d", p.Deposit)
+	}
+	action, err := p.Action.encodeGovAction()
+	if err != nil {
+		return nil, err
+	}
+	anchor, err := encodeAnchor(&p.Anchor)
+	if err != nil {
+		return nil, err
+	}
+	return cbor.Marshal([]interface{}{uint64(p.Deposit), returnAddr.Bytes(), action, anchor})
+}
+
+func CastVote(voter Voter, actionID GovActionID, choice VoteChoice, anchor *Anchor) Option {
+	return func(b *TxBuilder) {
+		b.Votes = append(b.Votes, Vote{
+			Voter:    voter,
+			ActionID: actionID,
+			Choice:   choice,
+			Anchor:   anchor,
+		})
+	}
+}
+
+// CastVoteScript casts a vote of a script DRep or committee member. Leave
+// redeemer empty for native scripts.
+func CastVoteScript(voter Voter, actionID GovActionID, choice VoteChoice, anchor *Anchor, scriptFilePath, redeemer string) Option {
+	return func(b *TxBuilder) {
+		b.Votes = append(b.Votes, Vote{
+			Voter:          voter,
+			ActionID:       actionID,
+			Choice:         choice,
+			Anchor:         anchor,
+			ScriptFilePath: scriptFilePath,
+			RedeemerValue:  redeemer,
+		})
+	}
+}
+
+func CastVoteScriptRaw(voter Voter, actionID GovActionID, choice VoteChoice, anchor *Anchor, scriptFilePath, redeemer string, exMem, exCPU int64) Option {
+	return func(b *TxBuilder) {
+		b.Votes = append(b.Votes, Vote{
+			Voter:          voter,
+			ActionID:       actionID,
+			Choice:         choice,
+			Anchor:         anchor,
+			ScriptFilePath: scriptFilePath,
+			RedeemerValue:  redeemer,
+			ExMem:          exMem,
+			ExCPU:          exCPU,
+		})
+	}
+}
+
+func Propose(deposit int64, returnAddr string, action GovAction, anchor Anchor) Option {
+	return func(b *TxBuilder) {
+		b.Proposals = append(b.Proposals, Proposal{
+			Deposit:       deposit,
+			ReturnAddress: returnAddr,
+			Action:        action,
+			Anchor:        anchor,
+		})
+	}
+}
+
+// ProposeWithGuardrail submits a proposal checked by the constitution
+// guardrail script at scriptFilePath.
+func ProposeWithGuardrail(deposit int64, returnAddr string, action GovAction, anchor Anchor, scriptFilePath, redeemer string) Option {
+	return func(b *TxBuilder) {
+		b.Proposals = append(b.Proposals, Proposal{
+			Deposit:        deposit,
+			ReturnAddress:  returnAddr,
+			Action:         action,
+			Anchor:         anchor,
+			ScriptFilePath: scriptFilePath,
+			RedeemerValue:  redeemer,
+		})
+	}
+}
+
+func ProposeWithGuardrailRaw(deposit int64, returnAddr string, action GovAction, anchor Anchor, scriptFilePath, redeemer string, exMem, exCPU int64) Option {
+	return func(b *TxBuilder) {
+		b.Proposals = append(b.Proposals, Proposal{
+			Deposit:        deposit,
+			ReturnAddress:  returnAddr,
+			Action:         action,
+			Anchor:         anchor,
+			ScriptFilePath: scriptFilePath,
+			RedeemerValue:  redeemer,
+			ExMem:          exMem,
+			ExCPU:          exCPU,
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package txbuilder
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/minswap/pab-go/address"
+	"github.com/minswap/pab-go/cbor"
+	"github.com/minswap/pab-go/ledger"
+	"github.com/stretchr/testify/assert"
+)
+
+func encodeAction(t *testing.T, action GovAction) string {
+	v, err := action.encodeGovAction()
+	if !assert.NoError(t, err) {
+		return ""
+	}
+	b, err := cbor.Marshal(v)
+	assert.NoError(t, err)
+	return hex.EncodeToString(b)
+}
+
+func TestGovActions(t *testing.T) {
+	keyHash := strings.Repeat("00", 28)
+	scriptHash := strings.Repeat("ff", 28)
+
+	assert.Equal(t, "8301f6820a00", encodeAction(t, HardForkInitiationAction{Major: 10}))
+	assert.Equal(t,
+		"8504f6d90102818200581c"+keyHash+"a18201581c"+scriptHash+"1901f4d81e820203",
+		encodeAction(t, UpdateCommitteeAction{
+			Remove: []address.Credential{address.NewKeyCredential(keyHash)},
+			Add:    map[address.Credential]uint64{address.NewScriptCredential(scriptHash): 500},
+			Quorum: ledger.NewRational(2, 3),
+		}))
+	assert.Equal(t,
+		"8305f68282617558200000000000000000000000000000000000000000000000000000000000000000f6",
+		encodeAction(t, NewConstitutionAction{Anchor: Anchor{URL: "u", DataHash: strings.Repeat("00", 32)}}))
+
+	_, err := UpdateCommitteeAction{}.encodeGovAction()
+	assert.Error(t, err, "missing quorum")
+}
+
+func TestRejectNegativeAmounts(t *testing.T) {
+	rewardAddr := "stake_test17rphkx6acpnf78fuvxn0mkew3l0fd058hzquvz7w36x4gtcljw6kf"
+	_, err := TreasuryWithdrawalsAction{Withdrawals: map[string]int64{rewardAddr: -1}}.encodeGovAction()
+	assert.Error(t, err)
+
+	_, err = Proposal{Deposit: -1, ReturnAddress: rewardAddr, Action: InfoAction{}}.MarshalCBOR()
+	assert.Error(t, err)
+
+	cert := Certificate{Kind: DRepRegistration, DRepCredential: address.NewKeyCredential(strings.Repeat("00", 28)), Deposit: -1}
+	_, err = cert.MarshalCBOR()
+	assert.Error(t, err)
+}
@@ -0,0 +1,96 @@
+package txbuilder
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/minswap/pab-go/cbor"
+)
+
+// encodeJSONMetadata encodes metadata written in cardano-cli's no-schema JSON
+// format: integer labels at the top level, "0x"-prefixed hex strings as bytes,
+// numeric and "0x" object keys as integers and bytes.
+func encodeJSONMetadata(s string) ([]byte, error) {
+	d := json.NewDecoder(bytes.NewReader([]byte(s)))
+	d.UseNumber()
+	var top map[string]interface{}
+	if err := d.Decode(&top); err != nil {
+		return nil, fmt.Errorf("fail to decode json metadata: %w", err)
+	}
+	labels := make(cbor.Map, 0, len(top))
+	for k, v := range top {
+		label, ok := new(big.Int).SetString(k, 10)
+		if !ok || label.Sign() < 0 || !label.IsUint64() {
+			return nil, fmt.Errorf("metadata label must be an unsigned integer, got %s", k)
+		}
+		value, err := metadatum(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metadata %s: %w", k, err)
+		}
+		labels = append(labels, cbor.MapEntry{Key: label.Uint64(), Value: value})
+	}
+	return cbor.Marshal(labels)
+}
+
+func metadataText(s string) (interface{}, error) {
+	if strings.HasPrefix(s, "0x") && strings.ToLower(s) == s {
+		if b, err := hex.DecodeString(s[2:]); err == nil {
+			if len(b) > 64 {
+				return nil, fmt.Errorf("metadata bytes longer than 64 bytes")
+			}
+			return b, nil
+		}
+	}
+	if len(s) > 64 {
+		return nil, fmt.Errorf("metadata text longer than 64 bytes: %s", s)
+	}
+	return s, nil
+}
+
+func metadatum(v interface{}) (interface{}, error) {
+	switch x := v.(type) {
+	case json.Number:
+		n, ok := new(big.Int).SetString(x.String(), 10)
+		if !ok {
+			return nil, fmt.Errorf("metadata number must be an integer, got %s", x)
+		}
+		return n, nil
+	case string:
+		return metadataText(x)
+	case []interface{}:
+		items := make([]interface{}, len(x))
+		for i, item := range x {
+			m, err := metadatum(item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = m
+		}
+		return items, nil
+	case map[string]interface{}:
+		m := make(cbor.Map, 0, len(x))
+		for k, item := range x {
+			var key interface{}
+			if n, ok := new(big.Int).SetString(k, 10); ok {
+				key = n
+			} else {
+				var err error
+				if key, err = metadataText(k); err != nil {
+					return nil, err
+				}
+			}
+			value, err := metadatum(item)
+			if err != nil {
+				return nil, err
+			}
+			m = append(m, cbor.MapEntry{Key: key, Value: value})
+		}
+		return m, nil
+	default:
+		return nil, fmt.Errorf("unsupported metadata value %v", v)
+	}
+}
@@ -0,0 +1,75 @@
+package txbuilder
+
+import (
+	"fmt"
+
+	"github.com/minswap/pab-go/ledger"
+)
+
+func (o TxOutput) ledgerOutput() ledger.Output {
+	return ledger.Output{
+		Address: o.Address,
+		Value:   o.Value,
+	}
+}
+
+func (o ScriptOutput) ledgerOutput() (ledger.Output, error) {
+	out := o.TxOutput.ledgerOutput()
+	switch datum := o.Datum.(type) {
+	case ScriptOutputDatumHash:
+		out.DatumHash = datum.DatumHash
+	case ScriptOutputDatumValue:
+		b, err := ledger.PlutusDataFromJSON(datum.DatumValue)
+		if err != nil {
+			return out, err
+		}
+		out.DatumHash = ledger.DatumHash(b)
+	case ScriptOutputInlineDatum:
+		b, err := ledger.PlutusDataFromJSON(datum.DatumValue)
+		if err != nil {
+			return out, err
+		}
+		out.InlineDatum = b
+	default:
+		return out, fmt.Errorf("unsupported datum type: %T", datum)
+	}
+	return out, nil
+}
+
+// EnsureMinimumADA makes builders raise the ADA of every output to the minimum
+// required by params when building.
+func EnsureMinimumADA(params ledger.ProtocolParams) Option {
+	return func(b *TxBuilder) {
+		b.MinimumADAParams = &params
+	}
+}
+
+// ApplyMinimumADA raises the ADA of every output to the minimum required by
+// params. Output values are replaced, not modified in place.
+func (b *TxBuilder) ApplyMinimumADA(params ledger.ProtocolParams) error {
+	outputs := make([]TxOutput, len(b.PubKeyOutputs))
+	for i, out := range b.PubKeyOutputs {
+		val, err := out.Value.Clone().AddMinimumADAForOutput(out.ledgerOutput(), params)
+		if err != nil {
+			return fmt.Errorf("fail to compute minimum ADA of pub key output %d: %w", i, err)
+		}
+		out.Value = val
+		outputs[i] = out
+	}
+	scriptOutputs := make([]ScriptOutput, len(b.ScriptOutputs))
+	for i, out := range b.ScriptOutputs {
+		ledgerOut, err := out.ledgerOutput()
+		if err != nil {
+			return fmt.Errorf("fail to encode datum of script output %d: %w", i, err)
+		}
+		val, err := out.Value.Clone().AddMinimumADAForOutput(ledgerOut, params)
+		if err != nil {
+			return fmt.Errorf("fail to compute minimum ADA of script output %d: %w", i, err)
+		}
+		out.Value = val
+		scriptOutputs[i] = out
+	}
+	b.PubKeyOutputs = outputs
+	b.ScriptOutputs = scriptOutputs
+	return nil
+}
@@ -0,0 +1,692 @@
+package txbuilder
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/minswap/pab-go/address"
+	"github.com/minswap/pab-go/cbor"
+	"github.com/minswap/pab-go/ledger"
+	"golang.org/x/crypto/blake2b"
+)
+
+type RedeemerTag byte
+
+const (
+	RedeemerSpend RedeemerTag = iota
+	RedeemerMint
+	RedeemerCert
+	RedeemerReward
+	RedeemerVote
+	RedeemerPropose
+)
+
+// Redeemer is a redeemer of the witness set. Data is CBOR-encoded Plutus data.
+type Redeemer struct {
+	Tag   RedeemerTag
+	Index int
+	Data  []byte
+	ExMem int64
+	ExCPU int64
+}
+
+// VkeyWitness is a signature of the transaction body hash.
+type VkeyWitness struct {
+	Vkey      []byte
+	Signature []byte
+}
+
+// Transaction is the ledger form of a TxBuilder: inputs are sorted, scripts
+// and datums are read and encoded, redeemers are indexed. It has no change
+// output, so it is balanced only when built with a fee by the caller.
+type Transaction struct {
+	Inputs         []TxInput
+	Outputs        []ledger.Output
+	Fee            int64
+	ValidRangeFrom *int64
+	ValidRangeTo   *int64
+	Certificates   []Certificate
+	Withdrawals    []Withdrawal
+	Mint           ledger.MintValue
+	Collaterals    []TxInput
+	// CollateralReturn is nil and TotalCollateral 0 without collateral return.
+	CollateralReturn *ledger.Output
+	TotalCollateral  int64
+	RequiredSigners  []string
+	Votes            []Vote
+	Proposals        []Proposal
+	// ScriptDataHash is hex-encoded, set by the caller once redeemers are final.
+	ScriptDataHash string
+	AuxiliaryData  []byte
+
+	Scripts   []ledger.Script
+	Datums    [][]byte
+	Redeemers []Redeemer
+}
+
+func sortTxInputs(inputs []TxInput) {
+	sort.SliceStable(inputs, func(i, j int) bool {
+		if inputs[i].TxID != inputs[j].TxID {
+			return inputs[i].TxID < inputs[j].TxID
+		}
+		return inputs[i].TxIndex < inputs[j].TxIndex
+	})
+}
+
+type scriptCollector struct {
+	byPath  map[string]ledger.Script
+	byHash  map[string]struct{}
+	scripts []ledger.Script
+}
+
+// add reads the script at path once and returns its hash.
+func (c *scriptCollector) add(path string) (string, error) {
+	script, ok := c.byPath[path]
+	if !ok {
+		var err error
+		if script, err = ledger.ReadScriptFile(path); err != nil {
+			return "", err
+		}
+		c.byPath[path] = script
+	}
+	hash := script.Hash()
+	if _, ok := c.byHash[hash]; !ok {
+		c.byHash[hash] = struct{}{}
+		c.scripts = append(c.scripts, script)
+	}
+	return hash, nil
+}
+
+type datumCollector struct {
+	seen   map[string]struct{}
+	datums [][]byte
+}
+
+func (c *datumCollector) add(datumJSON string) error {
+	b, err := ledger.PlutusDataFromJSON(datumJSON)
+	if err != nil {
+		return err
+	}
+	if _, ok := c.seen[string(b)]; !ok {
+		c.seen[string(b)] = struct{}{}
+		c.datums = append(c.datums, b)
+	}
+	return nil
+}
+
+// Transaction converts the builder to its ledger form, reading script files
+// and encoding datums, redeemers and metadata.
+func (b *TxBuilder) Transaction() (*Transaction, error) {
+	tx := &Transaction{
+		Fee:            b.Fee,
+		ValidRangeFrom: b.ValidRangeFrom,
+		ValidRangeTo:   b.ValidRangeTo,
+		Certificates:   b.Certificates,
+		Votes:          b.Votes,
+		Proposals:      b.Proposals,
+		Mint:           ledger.NewMintValue(),
+	}
+	scripts := &scriptCollector{byPath: map[string]ledger.Script{}, byHash: map[string]struct{}{}}
+	datums := &datumCollector{seen: map[string]struct{}{}}
+	addRedeemer := func(tag RedeemerTag, index int, redeemer string, exMem, exCPU int64) error {
+		data, err := ledger.PlutusDataFromJSON(redeemer)
+		if err != nil {
+			return fmt.Errorf("invalid redeemer: %w", err)
+		}
+		tx.Redeemers = append(tx.Redeemers, Redeemer{tag, index, data, exMem, exCPU})
+		return nil
+	}
+
+	// inputs
+	tx.Inputs = append(tx.Inputs, b.PubKeyInputs...)
+	for _, in := range b.ScriptInputs {
+		tx.Inputs = append(tx.Inputs, in.TxInput)
+	}
+	sortTxInputs(tx.Inputs)
+	for _, in := range b.ScriptInputs {
+		if _, err := scripts.add(in.ScriptFilePath); err != nil {
+			return nil, fmt.Errorf("invalid script of input %s#%d: %w", in.TxID, in.TxIndex, err)
+		}
+		if err := datums.add(in.DatumValue); err != nil {
+			return nil, fmt.Errorf("invalid datum of input %s#%d: %w", in.TxID, in.TxIndex, err)
+		}
+		index := sort.Search(len(tx.Inputs), func(i int) bool {
+			return tx.Inputs[i].TxID > in.TxID || (tx.Inputs[i].TxID == in.TxID && tx.Inputs[i].TxIndex >= in.TxIndex)
+		})
+		if err := addRedeemer(RedeemerSpend, index, in.RedeemerValue, in.ExMem, in.ExCPU); err != nil {
+			return nil, fmt.Errorf("input %s#%d: %w", in.TxID, in.TxIndex, err)
+		}
+	}
+	tx.Collaterals = append(tx.Collaterals, b.Collaterals...)
+	sortTxInputs(tx.Collaterals)
+	if err := b.ValidateAmounts(); err != nil {
+		return nil, err
+	}
+	if err := b.ValidateCollateral(); err != nil {
+		return nil, err
+	}
+	if b.CollateralReturn != nil {
+		ret := b.CollateralReturn.ledgerOutput()
+		tx.CollateralReturn = &ret
+		tx.TotalCollateral = b.TotalCollateral
+	}
+
+	// outputs
+	for _, out := range b.PubKeyOutputs {
+		tx.Outputs = append(tx.Outputs, out.ledgerOutput())
+	}
+	for i, out := range b.ScriptOutputs {
+		ledgerOut, err := out.ledgerOutput()
+		if err != nil {
+			return nil, fmt.Errorf("invalid datum of script output %d: %w", i, err)
+		}
+		if datum, ok := out.Datum.(ScriptOutputDatumValue); ok {
+			if err := datums.add(datum.DatumValue); err != nil {
+				return nil, err
+			}
+		}
+		tx.Outputs = append(tx.Outputs, ledgerOut)
+	}
+
+	// minting and burning
+	type mintRedeemer struct {
+		redeemer     string
+		exMem, exCPU int64
+	}
+	mintRedeemers := make(map[string]mintRedeemer)
+	addMint := func(val ledger.Value, scriptFilePath string, burn bool) (string, error) {
+		policy, err := scripts.add(scriptFilePath)
+		if err != nil {
+			return "", err
+		}
+		if burn {
+			tx.Mint.Burn(val)
+		} else {
+			tx.Mint.Mint(val)
+		}
+		return policy, nil
+	}
+	for _, m := range b.Minting {
+		policy, err := addMint(m.Value, m.ScriptFilePath, false)
+		if err != nil {
+			return nil, fmt.Errorf("invalid minting script: %w", err)
+		}
+		if _, ok := mintRedeemers[policy]; !ok {
+			mintRedeemers[policy] = mintRedeemer{m.RedeemerValue, m.ExMem, m.ExCPU}
+		}
+	}
+	for _, m := range b.Burning {
+		policy, err := addMint(m.Value, m.ScriptFilePath, true)
+		if err != nil {
+			return nil, fmt.Errorf("invalid burning script: %w", err)
+		}
+		if _, ok := mintRedeemers[policy]; !ok {
+			mintRedeemers[policy] = mintRedeemer{m.RedeemerValue, m.ExMem, m.ExCPU}
+		}
+	}
+	for _, m := range b.MintingNativeScript {
+		if _, err := addMint(m.Value, m.ScriptFilePath, false); err != nil {
+			return nil, fmt.Errorf("invalid minting native script: %w", err)
+		}
+	}
+	for _, m := range b.BurningNativeScript {
+		if _, err := addMint(m.Value, m.ScriptFilePath, true); err != nil {
+			return nil, fmt.Errorf("invalid burning native script: %w", err)
+		}
+	}
+	policies := tx.Mint.Policies()
+	for i, policy := range policies {
+		if r, ok := mintRedeemers[policy]; ok {
+			if err := addRedeemer(RedeemerMint, i, r.redeemer, r.exMem, r.exCPU); err != nil {
+				return nil, fmt.Errorf("policy %s: %w", policy, err)
+			}
+		}
+	}
+
+	// certificates
+	for i, cert := range b.Certificates {
+		if cert.ScriptFilePath == "" {
+			continue
+		}
+		if _, err := scripts.add(cert.ScriptFilePath); err != nil {
+			return nil, fmt.Errorf("invalid script of certificate %d: %w", i, err)
+		}
+		if cert.RedeemerValue != "" {
+			if err := addRedeemer(RedeemerCert, i, cert.RedeemerValue, cert.ExMem, cert.ExCPU); err != nil {
+				return nil, fmt.Errorf("certificate %d: %w", i, err)
+			}
+		}
+	}
+
+	// withdrawals are indexed in the ledger order of reward accounts
+	withdrawals := append([]Withdrawal{}, b.Withdrawals...)
+	rewardAddrs := make(map[string]address.Address)
+	for _, w := range withdrawals {
+		addr, err := address.Parse(w.RewardAddress)
+		if err != nil {
+			return nil, fmt.Errorf("invalid withdrawal address: %w", err)
+		}
+		if addr.Stake == nil {
+			return nil, fmt.Errorf("invalid withdrawal address: %s is not a reward address", w.RewardAddress)
+		}
+		rewardAddrs[w.RewardAddress] = addr
+	}
+	sort.SliceStable(withdrawals, func(i, j int) bool {
+		a, b := rewardAddrs[withdrawals[i].RewardAddress], rewardAddrs[withdrawals[j].RewardAddress]
+		if a.Network != b.Network {
+			return a.Network < b.Network
+		}
+		return credentialLess(a.Stake.Type == address.ScriptHashCredential, a.Stake.Hash, b.Stake.Type == address.ScriptHashCredential, b.Stake.Hash)
+	})
+	tx.Withdrawals = withdrawals
+	for i, w := range withdrawals {
+		if w.ScriptFilePath == "" {
+			continue
+		}
+		if _, err := scripts.add(w.ScriptFilePath); err != nil {
+			return nil, fmt.Errorf("invalid script of withdrawal %s: %w", w.RewardAddress, err)
+		}
+		if w.RedeemerValue != "" {
+			if err := addRedeemer(RedeemerReward, i, w.RedeemerValue, w.ExMem, w.ExCPU); err != nil {
+				return nil, fmt.Errorf("withdrawal %s: %w", w.RewardAddress, err)
+			}
+		}
+	}
+
+	// votes are indexed in the order of voters
+	voters := sortedVoters(b.Votes)
+	for _, vote := range b.Votes {
+		if vote.ScriptFilePath == "" {
+			continue
+		}
+		if _, err := scripts.add(vote.ScriptFilePath); err != nil {
+			return nil, fmt.Errorf("invalid script of voter %s: %w", vote.Voter.Hash, err)
+		}
+		if vote.RedeemerValue != "" {
+			index := 0
+			for index < len(voters) && voters[index] != vote.Voter {
+				index++
+			}
+			if err := addRedeemer(RedeemerVote, index, vote.RedeemerValue, vote.ExMem, vote.ExCPU); err != nil {
+				return nil, fmt.Errorf("voter %s: %w", vote.Voter.Hash, err)
+			}
+		}
+	}
+	for i, proposal := range b.Proposals {
+		if proposal.ScriptFilePath == "" {
+			continue
+		}
+		if _, err := scripts.add(proposal.ScriptFilePath); err != nil {
+			return nil, fmt.Errorf("invalid guardrail script of proposal %d: %w", i, err)
+		}
+		if proposal.RedeemerValue != "" {
+			if err := addRedeemer(RedeemerPropose, i, proposal.RedeemerValue, proposal.ExMem, proposal.ExCPU); err != nil {
+				return nil, fmt.Errorf("proposal %d: %w", i, err)
+			}
+		}
+	}
+
+	// required signers
+	tx.RequiredSigners = append(tx.RequiredSigners, b.RequiredSignerVkeyHashes...)
+	for _, skey := range b.SignerSkeyPaths {
+		hash, err := ledger.KeyHashFromSigningKeyFile(skey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid required signer %s: %w", skey, err)
+		}
+		tx.RequiredSigners = append(tx.RequiredSigners, hash)
+	}
+
+	if b.JSONMetadata != "" {
+		aux, err := encodeJSONMetadata(b.JSONMetadata)
+		if err != nil {
+			return nil, err
+		}
+		tx.AuxiliaryData = aux
+	}
+
+	tx.Scripts = scripts.scripts
+	tx.Datums = datums.datums
+	return tx, nil
+}
+
+// credentialLess orders credentials like the ledger: script hashes before key
+// hashes, then by hash.
+func credentialLess(aScript bool, aHash string, bScript bool, bHash string) bool {
+	if aScript != bScript {
+		return aScript
+	}
+	return strings.ToLower(aHash) < strings.ToLower(bHash)
+}
+
+// sortedVoters returns the distinct voters of votes in the ledger order:
+// committee members, then DReps, then stake pools.
+func sortedVoters(votes []Vote) []Voter {
+	seen := make(map[Voter]struct{})
+	var voters []Voter
+	for _, v := range votes {
+		if _, ok := seen[v.Voter]; !ok {
+			seen[v.Voter] = struct{}{}
+			voters = append(voters, v.Voter)
+		}
+	}
+	group := func(k VoterKind) int {
+		switch k {
+		case VoterCommitteeHotKey, VoterCommitteeHotScript:
+			return 0
+		case VoterDRepKey, VoterDRepScript:
+			return 1
+		}
+		return 2
+	}
+	isScript := func(k VoterKind) bool {
+		return k == VoterCommitteeHotScript || k == VoterDRepScript
+	}
+	sort.Slice(voters, func(i, j int) bool {
+		a, b := voters[i], voters[j]
+		if group(a.Kind) != group(b.Kind) {
+			return group(a.Kind) < group(b.Kind)
+		}
+		return credentialLess(isScript(a.Kind), a.Hash, isScript(b.Kind), b.Hash)
+	})
+	return voters
+}
+
+// encodeOrderedMap encodes m keeping the order of its entries, which the
+// ledger sorts by key value rather than by canonical key bytes.
+func encodeOrderedMap(m cbor.Map) (cbor.RawMessage, error) {
+	b := cbor.AppendMapHeader(nil, len(m))
+	for _, e := range m {
+		k, err := cbor.Marshal(e.Key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := cbor.Marshal(e.Value)
+		if err != nil {
+			return nil, err
+		}
+		b = append(append(b, k...), v...)
+	}
+	return b, nil
+}
+
+// Languages returns the Plutus languages of the scripts of the transaction.
+func (tx *Transaction) Languages() []ledger.ScriptLanguage {
+	seen := make(map[ledger.ScriptLanguage]struct{})
+	var langs []ledger.ScriptLanguage
+	for _, s := range tx.Scripts {
+		if s.Language == ledger.NativeScript {
+			continue
+		}
+		if _, ok := seen[s.Language]; !ok {
+			seen[s.Language] = struct{}{}
+			langs = append(langs, s.Language)
+		}
+	}
+	sort.Slice(langs, func(i, j int) bool { return langs[i] < langs[j] })
+	return langs
+}
+
+func encodeTxInputs(inputs []TxInput) ([]interface{}, error) {
+	ret := make([]interface{}, len(inputs))
+	for i, in := range inputs {
+		txID, err := hex.DecodeString(in.TxID)
+		if err != nil || len(txID) != 32 {
+			return nil, fmt.Errorf("invalid tx id: %s", in.TxID)
+		}
+		ret[i] = []interface{}{txID, uint64(in.TxIndex)}
+	}
+	return ret, nil
+}
+
+func encodeMint(mint ledger.MintValue) (cbor.Map, error) {
+	byPolicy := make(map[string]cbor.Map)
+	for _, asset := range mint.Assets() {
+		amount := mint[asset]
+		if amount.Sign() == 0 {
+			continue
+		}
+		name, err := hex.DecodeString(asset.TokenName)
+		if err != nil {
+			return nil, fmt.Errorf("token name of %s is not hex", asset)
+		}
+		byPolicy[asset.CurrencySymbol] = append(byPolicy[asset.CurrencySymbol], cbor.MapEntry{Key: name, Value: amount})
+	}
+	m := make(cbor.Map, 0, len(byPolicy))
+	for policy, assets := range byPolicy {
+		policyID, err := hex.DecodeString(policy)
+		if err != nil || len(policyID) != address.HashLength {
+			return nil, fmt.Errorf("invalid policy ID: %s", policy)
+		}
+		m = append(m, cbor.MapEntry{Key: policyID, Value: assets})
+	}
+	return m, nil
+}
+
+func (tx *Transaction) encodeVotes() (cbor.RawMessage, error) {
+	byVoter := make(map[Voter]cbor.Map)
+	for _, v := range tx.Votes {
+		b, err := v.MarshalCBOR()
+		if err != nil {
+			return nil, err
+		}
+		decoded, err := cbor.Unmarshal(b)
+		if err != nil {
+			return nil, err
+		}
+		procedures := decoded.(cbor.Map)[0].Value.(cbor.Map)
+		byVoter[v.Voter] = append(byVoter[v.Voter], procedures...)
+	}
+	voters := sortedVoters(tx.Votes)
+	m := make(cbor.Map, 0, len(voters))
+	for _, voter := range voters {
+		hash, _ := hex.DecodeString(voter.Hash)
+		m = append(m, cbor.MapEntry{Key: []interface{}{uint64(voter.Kind), hash}, Value: byVoter[voter]})
+	}
+	return encodeOrderedMap(m)
+}
+
+// MarshalBody encodes the transaction body.
+func (tx *Transaction) MarshalBody() ([]byte, error) {
+	inputs, err := encodeTxInputs(tx.Inputs)
+	if err != nil {
+		return nil, err
+	}
+	outputs := make([]interface{}, len(tx.Outputs))
+	for i, out := range tx.Outputs {
+		outputs[i] = out
+	}
+	body := cbor.Map{
+		{Key: uint64(0), Value: inputs},
+		{Key: uint64(1), Value: outputs},
+		{Key: uint64(2), Value: uint64(tx.Fee)},
+	}
+	if tx.ValidRangeTo != nil {
+		body = append(body, cbor.MapEntry{Key: uint64(3), Value: uint64(*tx.ValidRangeTo)})
+	}
+	if len(tx.Certificates) > 0 {
+		certs := make([]interface{}, len(tx.Certificates))
+		for i, cert := range tx.Certificates {
+			certs[i] = cert
+		}
+		body = append(body, cbor.MapEntry{Key: uint64(4), Value: certs})
+	}
+	if len(tx.Withdrawals) > 0 {
+		withdrawals := make(cbor.Map, 0, len(tx.Withdrawals))
+		for _, w := range tx.Withdrawals {
+			addr, err := address.Parse(w.RewardAddress)
+			if err != nil {
+				return nil, err
+			}
+			withdrawals = append(withdrawals, cbor.MapEntry{Key: addr.Bytes(), Value: uint64(w.Amount)})
+		}
+		encoded, err := encodeOrderedMap(withdrawals)
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, cbor.MapEntry{Key: uint64(5), Value: encoded})
+	}
+	if tx.AuxiliaryData != nil {
+		hash := blake2b.Sum256(tx.AuxiliaryData)
+		body = append(body, cbor.MapEntry{Key: uint64(7), Value: hash[:]})
+	}
+	if tx.ValidRangeFrom != nil {
+		body = append(body, cbor.MapEntry{Key: uint64(8), Value: uint64(*tx.ValidRangeFrom)})
+	}
+	if len(tx.Mint) > 0 {
+		mint, err := encodeMint(tx.Mint)
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, cbor.MapEntry{Key: uint64(9), Value: mint})
+	}
+	if tx.ScriptDataHash != "" {
+		hash, err := hex.DecodeString(tx.ScriptDataHash)
+		if err != nil || len(hash) != 32 {
+			return nil, fmt.Errorf("invalid script data hash: %s", tx.ScriptDataHash)
+		}
+		body = append(body, cbor.MapEntry{Key: uint64(11), Value: hash})
+	}
+	if len(tx.Collaterals) > 0 {
+		collaterals, err := encodeTxInputs(tx.Collaterals)
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, cbor.MapEntry{Key: uint64(13), Value: collaterals})
+	}
+	if len(tx.RequiredSigners) > 0 {
+		signers := make([]interface{}, len(tx.RequiredSigners))
+		for i, s := range tx.RequiredSigners {
+			hash, err := hex.DecodeString(s)
+			if err != nil || len(hash) != address.HashLength {
+				return nil, fmt.Errorf("invalid required signer hash: %s", s)
+			}
+			signers[i] = hash
+		}
+		body = append(body, cbor.MapEntry{Key: uint64(14), Value: signers})
+	}
+	if tx.CollateralReturn != nil {
+		body = append(body,
+			cbor.MapEntry{Key: uint64(16), Value: *tx.CollateralReturn},
+			cbor.MapEntry{Key: uint64(17), Value: uint64(tx.TotalCollateral)},
+		)
+	}
+	if len(tx.Votes) > 0 {
+		votes, err := tx.encodeVotes()
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, cbor.MapEntry{Key: uint64(19), Value: votes})
+	}
+	if len(tx.Proposals) > 0 {
+		proposals := make([]interface{}, len(tx.Proposals))
+		for i, p := range tx.Proposals {
+			proposals[i] = p
+		}
+		body = append(body, cbor.MapEntry{Key: uint64(20), Value: proposals})
+	}
+	return cbor.Marshal(body)
+}
+
+// EncodeRedeemers encodes the redeemers as an array of [tag, index, data, [mem, steps]].
+func (tx *Transaction) EncodeRedeemers() ([]byte, error) {
+	redeemers := make([]interface{}, len(tx.Redeemers))
+	for i, r := range tx.Redeemers {
+		redeemers[i] = []interface{}{
+			uint64(r.Tag),
+			uint64(r.Index),
+			cbor.RawMessage(r.Data),
+			[]interface{}{uint64(r.ExMem), uint64(r.ExCPU)},
+		}
+	}
+	return cbor.Marshal(redeemers)
+}
+
+// EncodeDatums encodes the witness datums as an array of Plutus data.
+func (tx *Transaction) EncodeDatums() ([]byte, error) {
+	datums := make([]interface{}, len(tx.Datums))
+	for i, d := range tx.Datums {
+		datums[i] = cbor.RawMessage(d)
+	}
+	return cbor.Marshal(datums)
+}
+
+// MarshalWitnessSet encodes the witness set with the given key witnesses.
+func (tx *Transaction) MarshalWitnessSet(vkeyWitnesses []VkeyWitness) ([]byte, error) {
+	ws := cbor.Map{}
+	if len(vkeyWitnesses) > 0 {
+		witnesses := make([]interface{}, len(vkeyWitnesses))
+		for i, w := range vkeyWitnesses {
+			witnesses[i] = []interface{}{w.Vkey, w.Signature}
+		}
+		ws = append(ws, cbor.MapEntry{Key: uint64(0), Value: witnesses})
+	}
+	scriptsByKey := map[ledger.ScriptLanguage]uint64{
+		ledger.NativeScript: 1,
+		ledger.PlutusV1:     3,
+		ledger.PlutusV2:     6,
+		ledger.PlutusV3:     7,
+	}
+	grouped := make(map[uint64][]interface{})
+	for _, s := range tx.Scripts {
+		key, ok := scriptsByKey[s.Language]
+		if !ok {
+			return nil, fmt.Errorf("unsupported script language %s", s.Language)
+		}
+		if s.Language == ledger.NativeScript {
+			grouped[key] = append(grouped[key], cbor.RawMessage(s.Bytes))
+		} else {
+			grouped[key] = append(grouped[key], s.Bytes)
+		}
+	}
+	for key, scripts := range grouped {
+		ws = append(ws, cbor.MapEntry{Key: key, Value: scripts})
+	}
+	if len(tx.Datums) > 0 {
+		datums, err := tx.EncodeDatums()
+		if err != nil {
+			return nil, err
+		}
+		ws = append(ws, cbor.MapEntry{Key: uint64(4), Value: cbor.RawMessage(datums)})
+	}
+	if len(tx.Redeemers) > 0 {
+		redeemers, err := tx.EncodeRedeemers()
+		if err != nil {
+			return nil, err
+		}
+		ws = append(ws, cbor.MapEntry{Key: uint64(5), Value: cbor.RawMessage(redeemers)})
+	}
+	return cbor.Marshal(ws)
+}
+
+// MarshalCBOR encodes the full transaction [body, witness set, true, auxiliary data].
+func (tx *Transaction) MarshalCBOR() ([]byte, error) {
+	return tx.MarshalWithWitnesses(nil)
+}
+
+func (tx *Transaction) MarshalWithWitnesses(vkeyWitnesses []VkeyWitness) ([]byte, error) {
+	body, err := tx.MarshalBody()
+	if err != nil {
+		return nil, err
+	}
+	ws, err := tx.MarshalWitnessSet(vkeyWitnesses)
+	if err != nil {
+		return nil, err
+	}
+	var aux interface{}
+	if tx.AuxiliaryData != nil {
+		aux = cbor.RawMessage(tx.AuxiliaryData)
+	}
+	return cbor.Marshal([]interface{}{cbor.RawMessage(body), cbor.RawMessage(ws), true, aux})
+}
+
+// ID returns the transaction hash, the blake2b-256 hash of the body.
+func (tx *Transaction) ID() (string, error) {
+	body, err := tx.MarshalBody()
+	if err != nil {
+		return "", err
+	}
+	hash := blake2b.Sum256(body)
+	return hex.EncodeToString(hash[:]), nil
+}
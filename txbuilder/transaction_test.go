@@ -0,0 +1,85 @@
+package txbuilder
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/minswap/pab-go/address"
+	"github.com/minswap/pab-go/cbor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeJSONMetadata(t *testing.T) {
+	b, err := encodeJSONMetadata(`{"674": {"msg": ["hi", 1, "0xcafe"]}}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "a11902a2a1636d7367836268690142cafe", hex.EncodeToString(b))
+
+	_, err = encodeJSONMetadata(`{"msg": 1}`)
+	assert.Error(t, err)
+}
+
+func TestTransactionSortsInputs(t *testing.T) {
+	b := New(
+		RequireSignWithVkeyHash("5d8c6e8a59a1aa1f9d5aa1b1e85c0c3e1c6a3b5e0f2d4c6a8b0c2e4f"),
+	)
+	b.PubKeyInputs = []TxInput{
+		{TxID: "bb00000000000000000000000000000000000000000000000000000000000000", TxIndex: 0},
+		{TxID: "aa00000000000000000000000000000000000000000000000000000000000000", TxIndex: 1},
+	}
+	tx, err := b.Transaction()
+	assert.NoError(t, err)
+	assert.Equal(t, "aa00000000000000000000000000000000000000000000000000000000000000", tx.Inputs[0].TxID)
+	_, err = tx.ID()
+	assert.NoError(t, err)
+}
+
+func TestTransactionLedgerOrder(t *testing.T) {
+	keyHash := "00000000000000000000000000000000000000000000000000000000"
+	scriptHash := "ffffffffffffffffffffffffffffffffffffffffffffffffffffffff"
+	keyAddr, _ := address.NewRewardAddress(address.Testnet, address.NewKeyCredential(keyHash))
+	scriptAddr, _ := address.NewRewardAddress(address.Testnet, address.NewScriptCredential(scriptHash))
+	mainnetAddr, _ := address.NewRewardAddress(address.Mainnet, address.NewScriptCredential(keyHash))
+	actionID := GovActionID{TxID: "aa00000000000000000000000000000000000000000000000000000000000000"}
+
+	b := New(
+		WithdrawRewards(mainnetAddr.String(), 3),
+		WithdrawRewards(keyAddr.String(), 1),
+		WithdrawRewards(scriptAddr.String(), 2),
+		CastVote(Voter{Kind: VoterStakePool, Hash: keyHash}, actionID, VoteYes, nil),
+		CastVote(Voter{Kind: VoterDRepKey, Hash: keyHash}, actionID, VoteYes, nil),
+		CastVote(Voter{Kind: VoterDRepScript, Hash: scriptHash}, actionID, VoteYes, nil),
+		CastVote(Voter{Kind: VoterCommitteeHotKey, Hash: keyHash}, actionID, VoteYes, nil),
+	)
+	tx, err := b.Transaction()
+	assert.NoError(t, err)
+	// reward accounts sort by network, then script credentials before keys
+	var amounts []int64
+	for _, w := range tx.Withdrawals {
+		amounts = append(amounts, w.Amount)
+	}
+	assert.Equal(t, []int64{2, 1, 3}, amounts)
+	assert.Equal(t, []Voter{
+		{Kind: VoterCommitteeHotKey, Hash: keyHash},
+		{Kind: VoterDRepScript, Hash: scriptHash},
+		{Kind: VoterDRepKey, Hash: keyHash},
+		{Kind: VoterStakePool, Hash: keyHash},
+	}, sortedVoters(tx.Votes))
+
+	// the body keeps the ledger order instead of the canonical one
+	raw, err := tx.MarshalBody()
+	assert.NoError(t, err)
+	decoded, err := cbor.Unmarshal(raw)
+	assert.NoError(t, err)
+	withdrawals, _ := decoded.(cbor.Map).Get(uint64(5))
+	assert.Equal(t, scriptAddr.Bytes(), withdrawals.(cbor.Map)[0].Key)
+	votes, _ := decoded.(cbor.Map).Get(uint64(19))
+	assert.Equal(t, []interface{}{uint64(VoterDRepScript), mustDecodeHex(scriptHash)}, votes.(cbor.Map)[1].Key)
+}
+
+func mustDecodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
@@ -1,6 +1,8 @@
 package txbuilder
 
 import (
+	"math/big"
+
 	"github.com/minswap/pab-go/ledger"
 )
 
@@ -41,6 +43,12 @@ type ScriptOutputDatumValue struct {
 
 func (ScriptOutputDatumValue) isScriptOutputDatum() {}
 
+type ScriptOutputInlineDatum struct {
+	DatumValue string
+}
+
+func (ScriptOutputInlineDatum) isScriptOutputDatum() {}
+
 type ScriptOutput struct {
 	TxOutput
 	Datum ScriptOutputDatum
@@ -89,6 +97,17 @@ type TxBuilder struct {
 	JSONMetadata             string
 	SignerSkeyPaths          []string // TODO: Rename to RequiredSignerSkeyPaths in next breaking change
 	RequiredSignerVkeyHashes []string
+	Certificates             []Certificate
+	Withdrawals              []Withdrawal
+	Votes                    []Vote
+	Proposals                []Proposal
+	// MinimumADAParams, when set, makes builders raise the ADA of every output
+	// to the Babbage minimum before building.
+	MinimumADAParams *ledger.ProtocolParams
+	// CollateralReturn and TotalCollateral are set together, for collaterals
+	// holding more than is forfeited, e.g. native tokens.
+	CollateralReturn *TxOutput
+	TotalCollateral  int64
 }
 
 type Option = func(b *TxBuilder)
@@ -267,6 +286,19 @@ func UseCollaterals(utxos ...ledger.Utxo) Option {
 	}
 }
 
+// UseCollateralsWithReturn uses utxos as collateral, forfeiting only
+// totalCollateral lovelace if scripts fail; the rest goes to returnAddress.
+// Building fails if totalCollateral exceeds the lovelace of utxos.
+func UseCollateralsWithReturn(utxos []ledger.Utxo, returnAddress string, totalCollateral int64) Option {
+	return func(b *TxBuilder) {
+		UseCollaterals(utxos...)(b)
+		ret := ledger.SumValueOfUtxos(utxos)
+		ret.Add(ledger.ADA, big.NewInt(-totalCollateral))
+		b.CollateralReturn = &TxOutput{Address: returnAddress, Value: ret}
+		b.TotalCollateral = totalCollateral
+	}
+}
+
 func PayFee(x int64) Option {
 	return func(b *TxBuilder) {
 		b.Fee = x
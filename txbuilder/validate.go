@@ -0,0 +1,92 @@
+package txbuilder
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/minswap/pab-go/address"
+	"github.com/minswap/pab-go/ledger"
+)
+
+func validateAddress(addr string, network address.Network) error {
+	a, err := address.Parse(addr)
+	if err != nil {
+		return err
+	}
+	return a.ValidateNetwork(network)
+}
+
+// ValidateNetwork checks that every output, the change address and every
+// withdrawal are valid addresses of network.
+func (b *TxBuilder) ValidateNetwork(network address.Network) error {
+	for i, out := range b.PubKeyOutputs {
+		if err := validateAddress(out.Address, network); err != nil {
+			return fmt.Errorf("invalid pub key output %d: %w", i, err)
+		}
+	}
+	for i, out := range b.ScriptOutputs {
+		if err := validateAddress(out.Address, network); err != nil {
+			return fmt.Errorf("invalid script output %d: %w", i, err)
+		}
+	}
+	for i, w := range b.Withdrawals {
+		a, err := address.Parse(w.RewardAddress)
+		if err != nil {
+			return fmt.Errorf("invalid withdrawal %d: %w", i, err)
+		}
+		if a.Kind != address.KindReward {
+			return fmt.Errorf("invalid withdrawal %d: %s is not a reward address", i, w.RewardAddress)
+		}
+		if err := a.ValidateNetwork(network); err != nil {
+			return fmt.Errorf("invalid withdrawal %d: %w", i, err)
+		}
+	}
+	if b.ChangeAddress != "" {
+		if err := validateAddress(b.ChangeAddress, network); err != nil {
+			return fmt.Errorf("invalid change address: %w", err)
+		}
+	}
+	return nil
+}
+
+// ValidateAmounts checks that the withdrawal amounts and the slots of the
+// validity interval are not negative.
+func (b *TxBuilder) ValidateAmounts() error {
+	for _, w := range b.Withdrawals {
+		if w.Amount < 0 {
+			return fmt.Errorf("invalid withdrawal of %d lovelace from %s", w.Amount, w.RewardAddress)
+		}
+	}
+	if b.ValidRangeFrom != nil && *b.ValidRangeFrom < 0 {
+		return fmt.Errorf("invalid validity interval start %d", *b.ValidRangeFrom)
+	}
+	if b.ValidRangeTo != nil && *b.ValidRangeTo < 0 {
+		return fmt.Errorf("invalid validity interval end %d", *b.ValidRangeTo)
+	}
+	return nil
+}
+
+// ValidateCollateral checks that the total collateral of a collateral return
+// is covered by the lovelace of the collaterals.
+func (b *TxBuilder) ValidateCollateral() error {
+	if b.CollateralReturn == nil {
+		return nil
+	}
+	if b.TotalCollateral < 0 {
+		return fmt.Errorf("invalid total collateral %d", b.TotalCollateral)
+	}
+	lovelace := new(big.Int)
+	for _, in := range b.Collaterals {
+		if in.TxOut.Value == nil {
+			// unresolved collaterals are checked by the node
+			return nil
+		}
+		if amount, ok := in.TxOut.Value[ledger.ADA]; ok {
+			lovelace.Add(lovelace, amount)
+		}
+	}
+	if lovelace.Cmp(big.NewInt(b.TotalCollateral)) < 0 {
+		return fmt.Errorf("total collateral of %d lovelace exceeds the %s lovelace of collaterals", b.TotalCollateral, lovelace)
+	}
+	return nil
+}
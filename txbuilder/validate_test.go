@@ -0,0 +1,54 @@
+package txbuilder
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/minswap/pab-go/address"
+	"github.com/minswap/pab-go/ledger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateNetwork(t *testing.T) {
+	val := ledger.NewValue().Add(ledger.ADA, big.NewInt(2_000_000))
+	b := New(
+		PayToPubKey("addr_test1qpmtp5t0t5y6cqkaz7rfsyrx7mld77kpvksgkwm0p7en7qum7a589n30e80tclzrrnj8qr4qvzj6al0vpgtnmrkkksnqd8upj0", val),
+		PayChangeTo("addr_test1qr2tn9mcgzu08lskmnekswwg9ghfrzhtlzx9t2vm4r96skx5hxthss9c70lpdh8ndquus23wjx9wh7yv2k5eh2xt4pvqzs2er6"),
+	)
+	assert.NoError(t, b.ValidateNetwork(address.Testnet))
+	assert.ErrorIs(t, b.ValidateNetwork(address.Mainnet), address.ErrWrongNetwork)
+
+	b.Add(PayToPubKey("addr_test1qpmtp5t0t5y6cqkaz7rfsyrx7mld77kp", val))
+	assert.Error(t, b.ValidateNetwork(address.Testnet))
+}
+
+func TestValidateAmounts(t *testing.T) {
+	rewardAddr := "stake_test17rphkx6acpnf78fuvxn0mkew3l0fd058hzquvz7w36x4gtcljw6kf"
+	b := New(WithdrawRewards(rewardAddr, 0), SetValidRangeFrom(0), SetValidRangeTo(100))
+	assert.NoError(t, b.ValidateAmounts())
+
+	b = New(WithdrawRewards(rewardAddr, -5))
+	assert.Error(t, b.ValidateAmounts())
+	_, err := b.Transaction()
+	assert.Error(t, err)
+
+	b = New(SetValidRangeFrom(-1))
+	assert.Error(t, b.ValidateAmounts())
+	b = New(SetValidRangeTo(-1))
+	assert.Error(t, b.ValidateAmounts())
+}
+
+func TestValidateCollateral(t *testing.T) {
+	collateral := ledger.Utxo{
+		TxID:    "aa00000000000000000000000000000000000000000000000000000000000000",
+		Address: "addr_test1qpmtp5t0t5y6cqkaz7rfsyrx7mld77kpvksgkwm0p7en7qum7a589n30e80tclzrrnj8qr4qvzj6al0vpgtnmrkkksnqd8upj0",
+		Value:   ledger.NewValue().Add(ledger.ADA, big.NewInt(5_000_000)),
+	}
+	b := New(UseCollateralsWithReturn([]ledger.Utxo{collateral}, collateral.Address, 3_000_000))
+	assert.NoError(t, b.ValidateCollateral())
+
+	b = New(UseCollateralsWithReturn([]ledger.Utxo{collateral}, collateral.Address, 6_000_000))
+	assert.Error(t, b.ValidateCollateral())
+	_, err := b.Transaction()
+	assert.Error(t, err)
+}
@@ -0,0 +1,207 @@
+// Package watcher polls a chain provider and reports the UTxOs created and
+// spent at a set of addresses or patterns as events.
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/minswap/pab-go/ledger"
+	"github.com/minswap/pab-go/provider"
+)
+
+type EventKind int
+
+const (
+	// Created is emitted when a watched UTxO appears.
+	Created EventKind = iota
+	// Spent is emitted when a watched UTxO disappears.
+	Spent
+	// RolledBack is emitted instead of Spent when a UTxO disappears because
+	// the chain rolled back before the tip it was first seen at.
+	//
+	// Detection is best-effort: providers only report the tip, so a rollback
+	// is seen when the new tip is at an earlier slot, or at the same slot with
+	// another hash. A rollback followed by a fork growing past the previous
+	// tip before the next poll looks like a forward move, and UTxOs it
+	// removed are reported as Spent.
+	RolledBack
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case Created:
+		return "created"
+	case Spent:
+		return "spent"
+	case RolledBack:
+		return "rolled back"
+	default:
+		return fmt.Sprintf("EventKind(%d)", int(k))
+	}
+}
+
+// Event is a change of a watched UTxO seen at the tip of Slot and BlockHash.
+type Event struct {
+	Kind      EventKind
+	Utxo      ledger.Utxo
+	Slot      int
+	BlockHash string
+}
+
+var ErrNoPatternQuerier = errors.New("watcher: patterns need a PatternQuerier")
+
+type Options struct {
+	Provider  provider.ChainProvider
+	Addresses []string
+	// Patterns are queried with PatternQuerier, e.g. a Kupo client.
+	Patterns       []provider.Pattern
+	PatternQuerier provider.PatternQuerier
+	// PollInterval is how often the tip is queried, 2 seconds by default.
+	// UTxOs are only queried when the tip changes.
+	PollInterval time.Duration
+	// BufferSize is the capacity of the event channel. When it is full the
+	// watcher stops polling until events are received, so none is dropped.
+	BufferSize int
+	// SkipExisting does not emit Created for the UTxOs of the first poll.
+	SkipExisting bool
+	// MaxFailures is how many polls in a row may fail before Run returns the
+	// error, 5 by default. Negative retries forever. Failed polls are
+	// retried every PollInterval.
+	MaxFailures int
+	// OnError, if set, is called with the error of each failed poll.
+	OnError func(err error)
+}
+
+type Watcher struct {
+	Options
+	events chan Event
+	utxos  ledger.UtxoSet
+	// seenAt is the slot of the tip at which each UTxO was first seen.
+	seenAt map[ledger.OutRef]int
+	tip    *provider.Tip
+}
+
+func New(options Options) (*Watcher, error) {
+	if len(options.Patterns) > 0 && options.PatternQuerier == nil {
+		return nil, ErrNoPatternQuerier
+	}
+	if options.PollInterval <= 0 {
+		options.PollInterval = 2 * time.Second
+	}
+	if options.BufferSize < 0 {
+		options.BufferSize = 0
+	}
+	if options.MaxFailures == 0 {
+		options.MaxFailures = 5
+	}
+	return &Watcher{
+		Options: options,
+		events:  make(chan Event, options.BufferSize),
+		seenAt:  make(map[ledger.OutRef]int),
+	}, nil
+}
+
+// Events returns the channel of events, closed when Run returns.
+func (w *Watcher) Events() <-chan Event {
+	return w.events
+}
+
+// Run polls until ctx is done or MaxFailures polls in a row fail. A failed
+// poll changes nothing, the next one starts over from the last good one.
+func (w *Watcher) Run(ctx context.Context) error {
+	defer close(w.events)
+	ticker := time.NewTicker(w.PollInterval)
+	defer ticker.Stop()
+	failures := 0
+	for {
+		if err := w.Poll(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if w.OnError != nil {
+				w.OnError(err)
+			}
+			failures++
+			if w.MaxFailures > 0 && failures >= w.MaxFailures {
+				return err
+			}
+		} else {
+			failures = 0
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll queries the tip and, if it changed, the watched UTxOs, emitting the
+// differences with the previous poll.
+func (w *Watcher) Poll(ctx context.Context) error {
+	tip, err := w.Provider.GetTip()
+	if err != nil {
+		return fmt.Errorf("fail to get tip: %w", err)
+	}
+	if w.tip != nil && tip.Slot == w.tip.Slot && tip.Hash == w.tip.Hash {
+		return nil
+	}
+	utxos, err := w.query()
+	if err != nil {
+		return err
+	}
+
+	first := w.utxos == nil
+	rollback := !first && (tip.Slot < w.tip.Slot || (tip.Slot == w.tip.Slot && tip.Hash != w.tip.Hash))
+	var events []Event
+	if first {
+		w.utxos = ledger.NewUtxoSet()
+	}
+	for _, u := range w.utxos.Diff(utxos).Utxos() {
+		kind := Spent
+		if rollback && w.seenAt[u.OutRef()] > tip.Slot {
+			kind = RolledBack
+		}
+		delete(w.seenAt, u.OutRef())
+		events = append(events, Event{Kind: kind, Utxo: u, Slot: tip.Slot, BlockHash: tip.Hash})
+	}
+	for _, u := range utxos.Diff(w.utxos).Utxos() {
+		w.seenAt[u.OutRef()] = tip.Slot
+		if first && w.SkipExisting {
+			continue
+		}
+		events = append(events, Event{Kind: Created, Utxo: u, Slot: tip.Slot, BlockHash: tip.Hash})
+	}
+	w.utxos, w.tip = utxos, tip
+
+	for _, e := range events {
+		select {
+		case w.events <- e:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (w *Watcher) query() (ledger.UtxoSet, error) {
+	set := ledger.NewUtxoSet()
+	if len(w.Addresses) > 0 {
+		utxos, err := w.Provider.GetUtxosByAddresses(w.Addresses...)
+		if err != nil {
+			return nil, fmt.Errorf("fail to query utxos of addresses: %w", err)
+		}
+		set.Add(utxos...)
+	}
+	for _, pattern := range w.Patterns {
+		utxos, err := w.PatternQuerier.GetUtxosByPattern(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("fail to query utxos of %s: %w", pattern, err)
+		}
+		set.Add(utxos...)
+	}
+	return set, nil
+}
@@ -0,0 +1,155 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/minswap/pab-go/ledger"
+	"github.com/minswap/pab-go/provider"
+	"github.com/stretchr/testify/assert"
+)
+
+const testTxID = "5ca53b0eb10f317a5f1bf1bda679a04a8dd01c156643deee1d406ec2cc15e9e3"
+
+type fakeChain struct {
+	provider.ChainProvider
+	tip        provider.Tip
+	utxos      []ledger.Utxo
+	utxoCalls  int
+	patternSet []ledger.Utxo
+	// tipErrors is the number of GetTip calls left to fail
+	tipErrors int
+	tipCalls  int
+}
+
+func (c *fakeChain) GetTip() (*provider.Tip, error) {
+	c.tipCalls++
+	if c.tipErrors > 0 {
+		c.tipErrors--
+		return nil, errors.New("connection reset")
+	}
+	tip := c.tip
+	return &tip, nil
+}
+
+func (c *fakeChain) GetUtxosByAddresses(addresses ...string) ([]ledger.Utxo, error) {
+	c.utxoCalls++
+	return c.utxos, nil
+}
+
+func (c *fakeChain) GetUtxosByPattern(pattern provider.Pattern) ([]ledger.Utxo, error) {
+	return provider.FilterUtxos(c.patternSet, pattern), nil
+}
+
+func utxo(txIndex int) ledger.Utxo {
+	return ledger.Utxo{TxID: testTxID, TxIndex: txIndex, Address: "addr", Value: ledger.NewValue().Add(ledger.ADA, big.NewInt(1))}
+}
+
+func drain(w *Watcher) []Event {
+	var events []Event
+	for {
+		select {
+		case e := <-w.Events():
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
+
+func TestPoll(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	chain := &fakeChain{tip: provider.Tip{Slot: 10, Hash: "a"}, utxos: []ledger.Utxo{utxo(0), utxo(1)}}
+	w, err := New(Options{Provider: chain, Addresses: []string{"addr"}, BufferSize: 10})
+	assert.NoError(err)
+
+	assert.NoError(w.Poll(ctx))
+	assert.Equal([]Event{
+		{Kind: Created, Utxo: utxo(0), Slot: 10, BlockHash: "a"},
+		{Kind: Created, Utxo: utxo(1), Slot: 10, BlockHash: "a"},
+	}, drain(w))
+
+	// same tip: no query
+	assert.NoError(w.Poll(ctx))
+	assert.Equal(1, chain.utxoCalls)
+
+	chain.tip = provider.Tip{Slot: 20, Hash: "b"}
+	chain.utxos = []ledger.Utxo{utxo(1), utxo(2)}
+	assert.NoError(w.Poll(ctx))
+	assert.Equal([]Event{
+		{Kind: Spent, Utxo: utxo(0), Slot: 20, BlockHash: "b"},
+		{Kind: Created, Utxo: utxo(2), Slot: 20, BlockHash: "b"},
+	}, drain(w))
+
+	// a rollback to slot 15 drops utxo 2, seen at slot 20, and restores utxo 0
+	chain.tip = provider.Tip{Slot: 15, Hash: "c"}
+	chain.utxos = []ledger.Utxo{utxo(0), utxo(1)}
+	assert.NoError(w.Poll(ctx))
+	assert.Equal([]Event{
+		{Kind: RolledBack, Utxo: utxo(2), Slot: 15, BlockHash: "c"},
+		{Kind: Created, Utxo: utxo(0), Slot: 15, BlockHash: "c"},
+	}, drain(w))
+	assert.Equal("rolled back", RolledBack.String())
+}
+
+func TestRunBackpressure(t *testing.T) {
+	assert := assert.New(t)
+	token := ledger.NewAsset("29d222ce763455e3d7a09a665ce554f00ac89d2e99a1a83d267170c6", "4d494e")
+	withToken := utxo(3)
+	withToken.Value.Add(token, big.NewInt(1))
+	chain := &fakeChain{tip: provider.Tip{Slot: 10, Hash: "a"}, patternSet: []ledger.Utxo{utxo(0), withToken}}
+
+	_, err := New(Options{Provider: chain, Patterns: []provider.Pattern{provider.AssetPattern(token)}})
+	assert.ErrorIs(err, ErrNoPatternQuerier)
+
+	w, err := New(Options{
+		Provider:       chain,
+		Patterns:       []provider.Pattern{provider.AssetPattern(token)},
+		PatternQuerier: chain,
+		PollInterval:   time.Millisecond,
+	})
+	assert.NoError(err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Run(ctx) }()
+
+	// unbuffered: the watcher waits for the receiver
+	e := <-w.Events()
+	assert.Equal(Event{Kind: Created, Utxo: withToken, Slot: 10, BlockHash: "a"}, e)
+	cancel()
+	assert.ErrorIs(<-done, context.Canceled)
+	_, ok := <-w.Events()
+	assert.False(ok)
+}
+
+func TestRunRetries(t *testing.T) {
+	assert := assert.New(t)
+	chain := &fakeChain{tip: provider.Tip{Slot: 10, Hash: "a"}, utxos: []ledger.Utxo{utxo(0)}, tipErrors: 2}
+	var failed int
+	w, err := New(Options{
+		Provider:     chain,
+		Addresses:    []string{"addr"},
+		PollInterval: time.Millisecond,
+		BufferSize:   1,
+		OnError:      func(error) { failed++ },
+	})
+	assert.NoError(err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Run(ctx) }()
+	e := <-w.Events()
+	assert.Equal(Created, e.Kind)
+	cancel()
+	assert.ErrorIs(<-done, context.Canceled)
+	assert.Equal(2, failed)
+
+	chain = &fakeChain{tipErrors: 10}
+	w, err = New(Options{Provider: chain, Addresses: []string{"addr"}, PollInterval: time.Millisecond, MaxFailures: 3})
+	assert.NoError(err)
+	assert.Error(w.Run(context.Background()))
+	assert.Equal(3, chain.tipCalls)
+}